@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+)
+
+// MaxBatchSize is the most operations a single batch may hold.
+const MaxBatchSize = 1000
+
+// BatchOp names the kind of a batch operation.
+type BatchOp string
+
+const (
+	BatchCreate BatchOp = "create"
+	BatchUpdate BatchOp = "update"
+	BatchDelete BatchOp = "delete"
+)
+
+// BatchOperation is a single write in a batch. ID names the task to update
+// or delete; Create and Update hold the input of their kind of operation.
+type BatchOperation struct {
+	Op     BatchOp
+	ID     string
+	Create CreateTaskInput
+	Update UpdateTaskInput
+}
+
+// BatchResult is the outcome of one batch operation. Task is the created or
+// updated task and is nil for deletes and failures.
+type BatchResult struct {
+	Task *Task
+	Err  error
+}
+
+// TaskBatcher is implemented by repositories that can apply several writes
+// atomically. Batch calls fn with a repository whose writes are applied
+// together if fn returns nil and not at all otherwise.
+type TaskBatcher interface {
+	Batch(ctx context.Context, fn func(tx TaskRepository) error) error
+}
+
+// ApplyBatch applies each operation in order, independently of the others.
+func (s *taskService) ApplyBatch(ctx context.Context, ops []BatchOperation) ([]BatchResult, error) {
+	if err := checkBatchSize(ops); err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(ops))
+	for i, op := range ops {
+		results[i].Task, results[i].Err = s.applyOp(ctx, op)
+	}
+	return results, nil
+}
+
+// ApplyBatchAtomic applies the operations in order as a single write. If one
+// fails, none are applied: it carries its own error and every other result
+// ErrBatchAborted. The repository must implement TaskBatcher.
+func (s *taskService) ApplyBatchAtomic(ctx context.Context, ops []BatchOperation) ([]BatchResult, error) {
+	if err := checkBatchSize(ops); err != nil {
+		return nil, err
+	}
+	batcher, ok := s.repo.(TaskBatcher)
+	if !ok {
+		return nil, pkgerrors.NewValidationError(CodeBatchUnsupported, ErrBatchUnsupported)
+	}
+
+	results := make([]BatchResult, len(ops))
+	history := &batchHistory{}
+	failed := -1
+	err := batcher.Batch(ctx, func(repo TaskRepository) error {
+		// Run the operations against the batch, holding back their
+		// history until it is applied.
+		tx := *s
+		tx.repo = repo
+		tx.history = history
+		for i, op := range ops {
+			task, err := tx.applyOp(ctx, op)
+			if err != nil {
+				failed = i
+				return err
+			}
+			results[i].Task = task
+		}
+		return nil
+	})
+
+	if failed >= 0 {
+		for i := range results {
+			results[i] = BatchResult{Err: pkgerrors.NewConflictError(CodeBatchAborted, ErrBatchAborted)}
+		}
+		results[failed].Err = err
+		return results, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.appendHistory(ctx, history.entries...)
+	return results, nil
+}
+
+// applyOp runs a single batch operation.
+func (s *taskService) applyOp(ctx context.Context, op BatchOperation) (*Task, error) {
+	switch op.Op {
+	case BatchCreate:
+		return s.CreateTask(ctx, op.Create)
+	case BatchUpdate:
+		return s.UpdateTask(ctx, op.ID, op.Update)
+	case BatchDelete:
+		return nil, s.DeleteTask(ctx, op.ID)
+	default:
+		return nil, pkgerrors.NewValidationError(CodeBatchOpInvalid, ErrBatchOpInvalid)
+	}
+}
+
+// checkBatchSize rejects empty and oversized batches.
+func checkBatchSize(ops []BatchOperation) error {
+	if len(ops) == 0 {
+		return pkgerrors.NewValidationError(CodeBatchEmpty, ErrBatchEmpty)
+	}
+	if len(ops) > MaxBatchSize {
+		return pkgerrors.NewValidationError(CodeBatchTooLarge, fmt.Sprintf("batch must hold at most %d operations", MaxBatchSize))
+	}
+	return nil
+}
+
+// batchHistory holds the history of an atomic batch until the batch has
+// been applied.
+type batchHistory struct {
+	entries []*HistoryEntry
+}
+
+func (h *batchHistory) Append(_ context.Context, entry *HistoryEntry) error {
+	h.entries = append(h.entries, entry)
+	return nil
+}
+
+func (h *batchHistory) ListByTask(context.Context, string) ([]*HistoryEntry, error) {
+	return []*HistoryEntry{}, nil
+}
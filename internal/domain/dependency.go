@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+)
+
+// DependencyEdge says that Blocker must be done before Blocked can start.
+type DependencyEdge struct {
+	Blocker string `json:"blocker_id"`
+	Blocked string `json:"blocked_id"`
+}
+
+// DependencyGraph holds the transitive blockers of a task in topological
+// order, so every task appears after all of its own blockers.
+type DependencyGraph struct {
+	TaskID string           `json:"task_id"`
+	Tasks  []*Task          `json:"tasks"`
+	Edges  []DependencyEdge `json:"edges"`
+}
+
+// AddDependency records that task id cannot start until blockerID is done.
+// Adding an existing dependency is a no-op. Additions are serialized within
+// the service, so the cycle check always sees the edges added before.
+func (s *taskService) AddDependency(ctx context.Context, id, blockerID string) (*Task, error) {
+	s.dependencies.Lock()
+	defer s.dependencies.Unlock()
+
+	task, err := s.getLive(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(task.BlockedBy, blockerID) {
+		return task, nil
+	}
+
+	if blockerID == id {
+		return nil, pkgerrors.NewValidationError(CodeDependencyCycle, ErrDependencyCycle)
+	}
+	if _, err := s.getLive(ctx, blockerID); err != nil {
+		if pkgerrors.IsNotFound(err) {
+			return nil, pkgerrors.NewValidationError(CodeBlockerNotFound, ErrBlockerNotFound)
+		}
+		return nil, err
+	}
+
+	// The new edge closes a cycle if the blocker already waits on task id.
+	reaches, err := s.blockedBy(ctx, blockerID, id)
+	if err != nil {
+		return nil, err
+	}
+	if reaches {
+		return nil, pkgerrors.NewValidationError(CodeDependencyCycle, ErrDependencyCycle)
+	}
+
+	before := *task
+	task.BlockedBy = append(slices.Clone(task.BlockedBy), blockerID)
+	slices.Sort(task.BlockedBy)
+	task.UpdatedAt = time.Now().UTC()
+	if err := s.save(ctx, HistoryUpdated, before, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// RemoveDependency removes blockerID from the blockers of task id.
+func (s *taskService) RemoveDependency(ctx context.Context, id, blockerID string) (*Task, error) {
+	task, err := s.getLive(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	i := slices.Index(task.BlockedBy, blockerID)
+	if i < 0 {
+		return nil, pkgerrors.NewNotFoundError(CodeDependencyNotFound, ErrDependencyNotFound)
+	}
+
+	before := *task
+	task.BlockedBy = slices.Delete(slices.Clone(task.BlockedBy), i, i+1)
+	task.UpdatedAt = time.Now().UTC()
+	if err := s.save(ctx, HistoryUpdated, before, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// DependencyGraph returns the transitive blockers of task id. Blockers that
+// have been purged are skipped.
+func (s *taskService) DependencyGraph(ctx context.Context, id string) (*DependencyGraph, error) {
+	root, err := s.getLive(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &DependencyGraph{TaskID: id, Tasks: []*Task{}, Edges: []DependencyEdge{}}
+	visited := map[string]bool{id: true}
+
+	// Depth-first post-order emits each task after all of its blockers.
+	var visit func(t *Task) error
+	visit = func(t *Task) error {
+		for _, blockerID := range t.BlockedBy {
+			blocker, err := s.repo.GetByID(ctx, blockerID)
+			if pkgerrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			graph.Edges = append(graph.Edges, DependencyEdge{Blocker: blockerID, Blocked: t.ID})
+			if visited[blockerID] {
+				continue
+			}
+			visited[blockerID] = true
+			if err := visit(blocker); err != nil {
+				return err
+			}
+			graph.Tasks = append(graph.Tasks, blocker)
+		}
+		return nil
+	}
+	if err := visit(root); err != nil {
+		return nil, err
+	}
+	return graph, nil
+}
+
+// blockedBy reports whether task id transitively waits on target.
+func (s *taskService) blockedBy(ctx context.Context, id, target string) (bool, error) {
+	visited := map[string]bool{}
+	stack := []string{id}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current == target {
+			return true, nil
+		}
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+
+		task, err := s.repo.GetByID(ctx, current)
+		if pkgerrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		stack = append(stack, task.BlockedBy...)
+	}
+	return false, nil
+}
+
+// checkBlockers rejects starting a task while any live blocker is not done.
+func (s *taskService) checkBlockers(ctx context.Context, task *Task) error {
+	for _, blockerID := range task.BlockedBy {
+		blocker, err := s.repo.GetByID(ctx, blockerID)
+		if pkgerrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !blocker.IsDeleted() && blocker.Status != StatusDone {
+			return pkgerrors.NewValidationError(CodeTaskBlocked, ErrTaskBlocked)
+		}
+	}
+	return nil
+}
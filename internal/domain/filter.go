@@ -0,0 +1,493 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+)
+
+// MaxFilterLength bounds the length of a filter expression.
+const MaxFilterLength = 1024
+
+// FilterField names a task attribute that filter expressions can test.
+type FilterField string
+
+const (
+	FilterStatus      FilterField = "status"
+	FilterPriority    FilterField = "priority"
+	FilterTitle       FilterField = "title"
+	FilterDescription FilterField = "description"
+	FilterTags        FilterField = "tags"
+	FilterParentID    FilterField = "parent_id"
+	FilterDueDate     FilterField = "due_date"
+	FilterCreatedAt   FilterField = "created_at"
+	FilterUpdatedAt   FilterField = "updated_at"
+)
+
+// FilterOp is a comparison operator.
+type FilterOp string
+
+const (
+	OpEq       FilterOp = "="
+	OpNe       FilterOp = "!="
+	OpLt       FilterOp = "<"
+	OpLe       FilterOp = "<="
+	OpGt       FilterOp = ">"
+	OpGe       FilterOp = ">="
+	OpContains FilterOp = "~" // case-insensitive substring match
+	OpIn       FilterOp = "in"
+)
+
+// filterFields lists the operators each field supports.
+var filterFields = map[FilterField][]FilterOp{
+	FilterStatus:      {OpEq, OpNe, OpIn},
+	FilterPriority:    {OpEq, OpNe, OpLt, OpLe, OpGt, OpGe, OpIn},
+	FilterTitle:       {OpEq, OpNe, OpContains, OpIn},
+	FilterDescription: {OpEq, OpNe, OpContains, OpIn},
+	FilterTags:        {OpEq, OpNe, OpIn},
+	FilterParentID:    {OpEq, OpNe, OpIn},
+	FilterDueDate:     {OpEq, OpNe, OpLt, OpLe, OpGt, OpGe},
+	FilterCreatedAt:   {OpEq, OpNe, OpLt, OpLe, OpGt, OpGe},
+	FilterUpdatedAt:   {OpEq, OpNe, OpLt, OpLe, OpGt, OpGe},
+}
+
+// FilterExpr is a node of a parsed filter expression.
+type FilterExpr interface {
+	Matches(t *Task) bool
+}
+
+// FilterAnd matches tasks matching every term.
+type FilterAnd struct {
+	Terms []FilterExpr
+}
+
+// FilterOr matches tasks matching at least one term.
+type FilterOr struct {
+	Terms []FilterExpr
+}
+
+// FilterNot matches tasks not matching Expr.
+type FilterNot struct {
+	Expr FilterExpr
+}
+
+// FilterComparison compares a field against one value, or several for
+// OpIn. Values are typed by field: Priority for priority, time.Time for
+// dates, and string otherwise (tags already normalized).
+type FilterComparison struct {
+	Field  FilterField
+	Op     FilterOp
+	Values []any
+}
+
+// Matches implements FilterExpr.
+func (e *FilterAnd) Matches(t *Task) bool {
+	for _, term := range e.Terms {
+		if !term.Matches(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches implements FilterExpr.
+func (e *FilterOr) Matches(t *Task) bool {
+	for _, term := range e.Terms {
+		if term.Matches(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches implements FilterExpr.
+func (e *FilterNot) Matches(t *Task) bool {
+	return !e.Expr.Matches(t)
+}
+
+// Matches implements FilterExpr.
+func (e *FilterComparison) Matches(t *Task) bool {
+	if e.Op == OpIn {
+		return slices.ContainsFunc(e.Values, func(v any) bool { return e.compare(t, OpEq, v) })
+	}
+	return e.compare(t, e.Op, e.Values[0])
+}
+
+// compare applies a single-valued operator to a task field.
+func (e *FilterComparison) compare(t *Task, op FilterOp, v any) bool {
+	switch e.Field {
+	case FilterTags:
+		has := slices.Contains(t.Tags, v.(string))
+		return has == (op == OpEq)
+	case FilterPriority:
+		return compareOp(op, t.Priority.Rank()-v.(Priority).Rank())
+	case FilterDueDate:
+		return compareOp(op, t.DueDate.Compare(v.(time.Time)))
+	case FilterCreatedAt:
+		return compareOp(op, t.CreatedAt.Compare(v.(time.Time)))
+	case FilterUpdatedAt:
+		return compareOp(op, t.UpdatedAt.Compare(v.(time.Time)))
+	}
+
+	var field string
+	switch e.Field {
+	case FilterStatus:
+		field = string(t.Status)
+	case FilterTitle:
+		field = t.Title
+	case FilterDescription:
+		field = t.Description
+	case FilterParentID:
+		field = t.ParentID
+	}
+	if op == OpContains {
+		return strings.Contains(strings.ToLower(field), strings.ToLower(v.(string)))
+	}
+	return compareOp(op, strings.Compare(field, v.(string)))
+}
+
+// compareOp reports whether a three-way comparison result satisfies op.
+func compareOp(op FilterOp, c int) bool {
+	switch op {
+	case OpEq:
+		return c == 0
+	case OpNe:
+		return c != 0
+	case OpLt:
+		return c < 0
+	case OpLe:
+		return c <= 0
+	case OpGt:
+		return c > 0
+	case OpGe:
+		return c >= 0
+	default:
+		return false
+	}
+}
+
+// FilterError is a syntax or validation error in a filter expression. Pos
+// is the 1-based character position where the problem was found.
+type FilterError struct {
+	Pos int
+	Msg string
+}
+
+// Error implements the error interface.
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("%s at position %d: %s", ErrFilterInvalid, e.Pos, e.Msg)
+}
+
+// Unwrap makes filter errors validation errors.
+func (e *FilterError) Unwrap() error {
+	return pkgerrors.NewValidationError(CodeFilterInvalid, e.Error())
+}
+
+// ParseFilter parses a filter expression such as
+//
+//	status in (PENDING, IN_PROGRESS) and due_date < 2026-11-01 and title ~ "deploy"
+//
+// Comparisons can be combined with and, or, not and parentheses; and binds
+// tighter than or. Values are bare words or double-quoted strings, and dates
+// are RFC3339 or YYYY-MM-DD (midnight UTC). An empty expression yields nil.
+func ParseFilter(input string) (FilterExpr, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, nil
+	}
+	if len(input) > MaxFilterLength {
+		return nil, &FilterError{Pos: MaxFilterLength + 1, Msg: fmt.Sprintf("expression longer than %d characters", MaxFilterLength)}
+	}
+
+	tokens, err := lexFilter(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, tok.errorf("unexpected %s", tok)
+	}
+	return expr, nil
+}
+
+type filterTokenKind int
+
+const (
+	tokEOF filterTokenKind = iota
+	tokWord
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string
+	pos  int
+}
+
+// String describes the token for error messages.
+func (t filterToken) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return fmt.Sprintf("string %q", t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+func (t filterToken) errorf(format string, args ...any) error {
+	return &FilterError{Pos: t.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// keyword reports whether the token is the given case-insensitive keyword.
+func (t filterToken) keyword(kw string) bool {
+	return t.kind == tokWord && strings.EqualFold(t.text, kw)
+}
+
+// isWordRune reports whether r can appear in a bare word.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_-:.+/@", r)
+}
+
+// lexFilter splits a filter expression into tokens.
+func lexFilter(input string) ([]filterToken, error) {
+	runes := []rune(input)
+	var tokens []filterToken
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, filterToken{tokLParen, "(", pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{tokRParen, ")", pos})
+			i++
+		case r == ',':
+			tokens = append(tokens, filterToken{tokComma, ",", pos})
+			i++
+		case r == '=' || r == '~':
+			tokens = append(tokens, filterToken{tokOp, string(r), pos})
+			i++
+		case r == '<' || r == '>' || r == '!':
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, &FilterError{Pos: pos, Msg: `expected "!="`}
+			}
+			tokens = append(tokens, filterToken{tokOp, op, pos})
+			i += len(op)
+		case r == '"':
+			var sb strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, &FilterError{Pos: pos, Msg: "unterminated string"}
+			}
+			tokens = append(tokens, filterToken{tokString, sb.String(), pos})
+			i++
+		case isWordRune(r):
+			start := i
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, filterToken{tokWord, string(runes[start:i]), pos})
+		default:
+			return nil, &FilterError{Pos: pos, Msg: fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+	return append(tokens, filterToken{kind: tokEOF, pos: len(runes) + 1}), nil
+}
+
+// filterParser is a recursive descent parser over filter tokens.
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() filterToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// parseOr parses and-terms joined by or.
+func (p *filterParser) parseOr() (FilterExpr, error) {
+	var terms []FilterExpr
+	for {
+		term, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+		if !p.peek().keyword("or") {
+			break
+		}
+		p.next()
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return &FilterOr{Terms: terms}, nil
+}
+
+// parseAnd parses unary terms joined by and.
+func (p *filterParser) parseAnd() (FilterExpr, error) {
+	var terms []FilterExpr
+	for {
+		term, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+		if !p.peek().keyword("and") {
+			break
+		}
+		p.next()
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return &FilterAnd{Terms: terms}, nil
+}
+
+// parseUnary parses a negation, a parenthesized expression or a comparison.
+func (p *filterParser) parseUnary() (FilterExpr, error) {
+	tok := p.peek()
+	switch {
+	case tok.keyword("not"):
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &FilterNot{Expr: expr}, nil
+	case tok.kind == tokLParen:
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, closing.errorf(`expected ")", got %s`, closing)
+		}
+		return expr, nil
+	default:
+		return p.parseComparison()
+	}
+}
+
+// parseComparison parses "field op value" or "field in (value, ...)".
+func (p *filterParser) parseComparison() (FilterExpr, error) {
+	fieldTok := p.next()
+	if fieldTok.kind != tokWord {
+		return nil, fieldTok.errorf("expected field name, got %s", fieldTok)
+	}
+	field := FilterField(strings.ToLower(fieldTok.text))
+	ops, ok := filterFields[field]
+	if !ok {
+		return nil, fieldTok.errorf("unknown field %q", fieldTok.text)
+	}
+
+	opTok := p.next()
+	var op FilterOp
+	switch {
+	case opTok.kind == tokOp:
+		op = FilterOp(opTok.text)
+	case opTok.keyword("in"):
+		op = OpIn
+	default:
+		return nil, opTok.errorf("expected operator, got %s", opTok)
+	}
+	if !slices.Contains(ops, op) {
+		return nil, opTok.errorf("operator %q not supported for %s", op, field)
+	}
+
+	cmp := &FilterComparison{Field: field, Op: op}
+	if op != OpIn {
+		v, err := p.parseValue(field)
+		if err != nil {
+			return nil, err
+		}
+		cmp.Values = []any{v}
+		return cmp, nil
+	}
+
+	if open := p.next(); open.kind != tokLParen {
+		return nil, open.errorf(`expected "(", got %s`, open)
+	}
+	for {
+		v, err := p.parseValue(field)
+		if err != nil {
+			return nil, err
+		}
+		cmp.Values = append(cmp.Values, v)
+		sep := p.next()
+		if sep.kind == tokRParen {
+			return cmp, nil
+		}
+		if sep.kind != tokComma {
+			return nil, sep.errorf(`expected "," or ")", got %s`, sep)
+		}
+	}
+}
+
+// parseValue parses a single value and converts it to the field's type.
+func (p *filterParser) parseValue(field FilterField) (any, error) {
+	tok := p.next()
+	if tok.kind != tokWord && tok.kind != tokString {
+		return nil, tok.errorf("expected value, got %s", tok)
+	}
+
+	switch field {
+	case FilterPriority:
+		priority := Priority(strings.ToUpper(tok.text))
+		if !isValidPriority(priority) {
+			return nil, tok.errorf("unknown priority %q", tok.text)
+		}
+		return priority, nil
+	case FilterTags:
+		tag, err := NormalizeTag(tok.text)
+		if err != nil {
+			return nil, tok.errorf("invalid tag %q", tok.text)
+		}
+		return tag, nil
+	case FilterDueDate, FilterCreatedAt, FilterUpdatedAt:
+		if t, err := time.Parse(time.RFC3339, tok.text); err == nil {
+			return t.UTC(), nil
+		}
+		if t, err := time.Parse(time.DateOnly, tok.text); err == nil {
+			return t, nil
+		}
+		return nil, tok.errorf("invalid date %q, expected RFC3339 or YYYY-MM-DD", tok.text)
+	default:
+		return tok.text, nil
+	}
+}
@@ -0,0 +1,227 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+)
+
+// CompletionRule decides how a parent's status follows its children.
+type CompletionRule string
+
+const (
+	// CompletionBlock rejects moving a parent to StatusDone while any of its
+	// children are still open.
+	CompletionBlock CompletionRule = "block"
+	// CompletionAuto marks a parent done once all of its children are done.
+	CompletionAuto CompletionRule = "auto"
+)
+
+// DeletePolicy decides what happens to children when a parent is deleted.
+type DeletePolicy string
+
+const (
+	// DeleteOrphan detaches the children, which become top-level tasks.
+	DeleteOrphan DeletePolicy = "orphan"
+	// DeleteCascade moves every descendant to the trash with the parent.
+	// Restoring the parent restores the descendants trashed with it.
+	DeleteCascade DeletePolicy = "cascade"
+)
+
+// HierarchyPolicy configures how parent tasks relate to their children.
+type HierarchyPolicy struct {
+	Completion CompletionRule
+	Delete     DeletePolicy
+}
+
+// DefaultHierarchyPolicy blocks completing parents with open children and
+// orphans children of deleted parents.
+var DefaultHierarchyPolicy = HierarchyPolicy{Completion: CompletionBlock, Delete: DeleteOrphan}
+
+// WithHierarchyPolicy sets the service's parent/child rules.
+func WithHierarchyPolicy(p HierarchyPolicy) ServiceOption {
+	return func(s *taskService) {
+		s.hierarchy = p
+	}
+}
+
+// childPageSize is the page size used when walking all children of a task.
+const childPageSize = 100
+
+// ListChildren lists the live children of a live task, paged like
+// QueryTasks.
+func (s *taskService) ListChildren(ctx context.Context, id string, filter TaskFilter) (*TaskPage, error) {
+	if _, err := s.getLive(ctx, id); err != nil {
+		return nil, err
+	}
+
+	filter.ParentID = id
+	return s.QueryTasks(ctx, filter)
+}
+
+// children returns every child of a task, live or trashed.
+func (s *taskService) children(ctx context.Context, id string, trashed bool) ([]*Task, error) {
+	var out []*Task
+	filter := TaskFilter{ParentID: id, Trashed: trashed, PageSize: childPageSize}
+	for {
+		page, err := s.repo.Query(ctx, filter.WithDefaults())
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, page.Tasks...)
+		if page.NextCursor == "" {
+			return out, nil
+		}
+		filter.Cursor = page.NextCursor
+	}
+}
+
+// validateParent checks that parentID names a live task and that making it
+// the parent of task id would not form a cycle. id is empty for new tasks.
+func (s *taskService) validateParent(ctx context.Context, id, parentID string) error {
+	seen := map[string]bool{}
+	for current := parentID; current != ""; {
+		if current == id {
+			return pkgerrors.NewValidationError(CodeParentCycle, ErrParentCycle)
+		}
+		if seen[current] {
+			// An existing cycle that does not involve id; stop walking.
+			return nil
+		}
+		seen[current] = true
+
+		parent, err := s.repo.GetByID(ctx, current)
+		if pkgerrors.IsNotFound(err) || (err == nil && parent.IsDeleted()) {
+			if current == parentID {
+				return pkgerrors.NewValidationError(CodeParentNotFound, ErrParentNotFound)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		current = parent.ParentID
+	}
+	return nil
+}
+
+// checkCompletion enforces CompletionBlock before a task moves to DONE.
+func (s *taskService) checkCompletion(ctx context.Context, task *Task) error {
+	if s.hierarchy.Completion != CompletionBlock {
+		return nil
+	}
+
+	children, err := s.children(ctx, task.ID, false)
+	if err != nil {
+		return err
+	}
+	for _, c := range children {
+		if c.Status != StatusDone {
+			return pkgerrors.NewConflictError(CodeOpenChildren, ErrOpenChildren)
+		}
+	}
+	return nil
+}
+
+// completeAncestors applies CompletionAuto after a task moves to DONE,
+// completing each ancestor whose children are now all done and whose
+// workflow allows it.
+func (s *taskService) completeAncestors(ctx context.Context, task *Task) error {
+	if s.hierarchy.Completion != CompletionAuto {
+		return nil
+	}
+
+	for parentID := task.ParentID; parentID != ""; {
+		parent, err := s.repo.GetByID(ctx, parentID)
+		if pkgerrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if parent.IsDeleted() || parent.Status == StatusDone {
+			return nil
+		}
+
+		children, err := s.children(ctx, parent.ID, false)
+		if err != nil {
+			return err
+		}
+		for _, c := range children {
+			if c.Status != StatusDone {
+				return nil
+			}
+		}
+
+		// Leave parents alone when the workflow does not let them finish
+		before := *parent
+		parent.Status = StatusDone
+		if s.workflow.Check(before.Status, parent) != nil {
+			return nil
+		}
+		parent.UpdatedAt = time.Now().UTC()
+		if err := s.save(ctx, HistoryUpdated, before, parent); err != nil {
+			return err
+		}
+		parentID = parent.ParentID
+	}
+	return nil
+}
+
+// detachChildren applies the delete policy to the children of a task that
+// has just been trashed at deletedAt.
+func (s *taskService) detachChildren(ctx context.Context, task *Task, deletedAt time.Time) error {
+	children, err := s.children(ctx, task.ID, false)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range children {
+		before := *c
+		if s.hierarchy.Delete == DeleteCascade {
+			// Stamp descendants with the parent's time so a restore can
+			// tell which ones went to the trash together.
+			c.DeletedAt = &deletedAt
+			c.UpdatedAt = deletedAt
+			if err := s.save(ctx, HistoryDeleted, before, c); err != nil {
+				return err
+			}
+			if err := s.detachChildren(ctx, c, deletedAt); err != nil {
+				return err
+			}
+			continue
+		}
+
+		c.ParentID = ""
+		c.UpdatedAt = deletedAt
+		if err := s.save(ctx, HistoryUpdated, before, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreChildren restores the descendants trashed together with task.
+func (s *taskService) restoreChildren(ctx context.Context, task *Task, deletedAt time.Time) error {
+	children, err := s.children(ctx, task.ID, true)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range children {
+		if !c.DeletedAt.Equal(deletedAt) {
+			continue
+		}
+		before := *c
+		c.DeletedAt = nil
+		c.UpdatedAt = time.Now().UTC()
+		if err := s.save(ctx, HistoryRestored, before, c); err != nil {
+			return err
+		}
+		if err := s.restoreChildren(ctx, c, deletedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// HistoryAction names the kind of change recorded in a history entry.
+type HistoryAction string
+
+const (
+	HistoryCreated  HistoryAction = "created"
+	HistoryUpdated  HistoryAction = "updated"
+	HistoryDeleted  HistoryAction = "deleted"
+	HistoryRestored HistoryAction = "restored"
+	HistoryPurged   HistoryAction = "purged"
+)
+
+// SystemActor is recorded for changes made by background jobs.
+const SystemActor = "system"
+
+// anonymousActor is recorded when the context carries no actor.
+const anonymousActor = "anonymous"
+
+// FieldChange is the old and new value of a single task field, keyed by its
+// JSON name. Old is nil for created tasks.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// HistoryEntry is an immutable record of one change to a task.
+type HistoryEntry struct {
+	ID      string        `json:"id"`
+	TaskID  string        `json:"task_id"`
+	Action  HistoryAction `json:"action"`
+	Actor   string        `json:"actor"`
+	At      time.Time     `json:"at"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// HistoryRepository stores history entries. Append assigns the entry ID.
+// ListByTask returns entries in the order they were appended.
+type HistoryRepository interface {
+	Append(ctx context.Context, entry *HistoryEntry) error
+	ListByTask(ctx context.Context, taskID string) ([]*HistoryEntry, error)
+}
+
+// WithHistory sets where the service records task history. Without it no
+// history is kept.
+func WithHistory(h HistoryRepository) ServiceOption {
+	return func(s *taskService) {
+		s.history = h
+	}
+}
+
+// noHistory is the HistoryRepository used when history is disabled.
+type noHistory struct{}
+
+func (noHistory) Append(context.Context, *HistoryEntry) error { return nil }
+
+func (noHistory) ListByTask(context.Context, string) ([]*HistoryEntry, error) {
+	return []*HistoryEntry{}, nil
+}
+
+type actorKey struct{}
+
+// WithActor returns a context that attributes changes to actor.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor stored in ctx, or "anonymous".
+func ActorFrom(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return anonymousActor
+}
+
+// GetHistory returns the history of a task, oldest first. History outlives
+// the task, so purged tasks still have one.
+func (s *taskService) GetHistory(ctx context.Context, id string) ([]*HistoryEntry, error) {
+	entries, err := s.history.ListByTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		if _, err := s.repo.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// insert creates a task and records its creation. History is advisory:
+// once the change is stored, failing to record it is logged rather than
+// returned, so callers never see an error for a change that was made.
+func (s *taskService) insert(ctx context.Context, task *Task) error {
+	if err := s.repo.Create(ctx, task); err != nil {
+		return err
+	}
+	s.record(ctx, HistoryCreated, task.ID, nil, task)
+	return nil
+}
+
+// save persists a task and records how it differs from before.
+func (s *taskService) save(ctx context.Context, action HistoryAction, before Task, task *Task) error {
+	if err := s.repo.Update(ctx, task); err != nil {
+		return err
+	}
+	s.record(ctx, action, task.ID, &before, task)
+	return nil
+}
+
+// purge permanently removes a task and records the purge. The task is only
+// removed if it has not changed since it was read, so a task restored in the
+// meantime is kept.
+func (s *taskService) purge(ctx context.Context, task *Task) error {
+	if err := s.repo.DeleteVersion(ctx, task.ID, task.Version); err != nil {
+		return err
+	}
+	s.record(ctx, HistoryPurged, task.ID, task, nil)
+	return nil
+}
+
+// record appends a history entry for a change from before to after. Either
+// may be nil for creations and purges.
+func (s *taskService) record(ctx context.Context, action HistoryAction, taskID string, before, after *Task) {
+	s.appendHistory(ctx, &HistoryEntry{
+		TaskID:  taskID,
+		Action:  action,
+		Actor:   ActorFrom(ctx),
+		At:      time.Now().UTC(),
+		Changes: diffTasks(before, after),
+	})
+}
+
+// appendHistory appends entries, logging any that cannot be stored.
+func (s *taskService) appendHistory(ctx context.Context, entries ...*HistoryEntry) {
+	for _, entry := range entries {
+		if err := s.history.Append(ctx, entry); err != nil {
+			log.Printf("recording %s of task %s failed: %v", entry.Action, entry.TaskID, err)
+		}
+	}
+}
+
+// untrackedFields are bookkeeping fields left out of diffs.
+var untrackedFields = map[string]bool{"id": true, "version": true, "created_at": true, "updated_at": true}
+
+// diffTasks lists the fields that differ between two versions of a task.
+// Purges (after == nil) record no field changes.
+func diffTasks(before, after *Task) []FieldChange {
+	changes := []FieldChange{}
+	if after == nil {
+		return changes
+	}
+
+	var old reflect.Value
+	if before != nil {
+		old = reflect.ValueOf(*before)
+	}
+	cur := reflect.ValueOf(*after)
+	typ := cur.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" || untrackedFields[name] {
+			continue
+		}
+
+		newValue := cur.Field(i)
+		if before == nil {
+			if !newValue.IsZero() {
+				changes = append(changes, FieldChange{Field: name, New: fieldValue(newValue)})
+			}
+			continue
+		}
+		oldValue := old.Field(i)
+		if !sameValue(oldValue, newValue) {
+			changes = append(changes, FieldChange{Field: name, Old: fieldValue(oldValue), New: fieldValue(newValue)})
+		}
+	}
+	return changes
+}
+
+// sameValue compares field values, treating equal instants and empty
+// slices as unchanged.
+func sameValue(a, b reflect.Value) bool {
+	switch x := a.Interface().(type) {
+	case time.Time:
+		return x.Equal(b.Interface().(time.Time))
+	case *time.Time:
+		y := b.Interface().(*time.Time)
+		if x == nil || y == nil {
+			return x == y
+		}
+		return x.Equal(*y)
+	}
+	if a.Kind() == reflect.Slice && a.Len() == 0 && b.Len() == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// fieldValue returns a field as its plain JSON value, so entries look the
+// same whichever store they were read back from. Empty values are nil.
+func fieldValue(v reflect.Value) any {
+	if v.IsZero() || (v.Kind() == reflect.Slice && v.Len() == 0) {
+		return nil
+	}
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return nil
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil
+	}
+	return out
+}
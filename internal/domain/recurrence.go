@@ -0,0 +1,303 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+)
+
+// Frequency is the base period of a recurrence rule.
+type Frequency string
+
+const (
+	FreqDaily   Frequency = "DAILY"
+	FreqWeekly  Frequency = "WEEKLY"
+	FreqMonthly Frequency = "MONTHLY"
+	FreqYearly  Frequency = "YEARLY"
+)
+
+// MaxPreviewOccurrences caps how many occurrences a preview may return.
+const MaxPreviewOccurrences = 100
+
+// maxRecurrenceSteps bounds the search for the next valid date, e.g. the
+// next February 29th for a yearly rule.
+const maxRecurrenceSteps = 100
+
+// untilLayout is the RFC 5545 UTC date-time form used for UNTIL.
+const untilLayout = "20060102T150405Z"
+
+// rruleDays maps RFC 5545 day codes to weekdays.
+var rruleDays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// Recurrence is a subset of an RFC 5545 RRULE: FREQ, INTERVAL, BYDAY, COUNT
+// and UNTIL. Occurrences keep the time of day of the task's due date.
+type Recurrence struct {
+	Freq     Frequency
+	Interval int            // periods between occurrences, at least 1
+	ByDay    []time.Weekday // DAILY and WEEKLY only, ordered Monday first
+	Count    int            // occurrences left including the current one, 0 for no limit
+	Until    time.Time      // last allowed occurrence, zero for no limit
+}
+
+// ParseRecurrence parses a rule such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH".
+// An optional "RRULE:" prefix is accepted.
+func ParseRecurrence(rule string) (*Recurrence, error) {
+	invalid := func(format string, args ...any) error {
+		return pkgerrors.NewValidationError(CodeRecurrenceInvalid, ErrRecurrenceInvalid+": "+fmt.Sprintf(format, args...))
+	}
+
+	rule = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(rule)), "RRULE:")
+	r := &Recurrence{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, invalid("expected KEY=VALUE, got %q", part)
+		}
+		if seen[key] {
+			return nil, invalid("duplicate %s", key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			r.Freq = Frequency(value)
+			switch r.Freq {
+			case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+			default:
+				return nil, invalid("unsupported FREQ %q", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, invalid("INTERVAL must be a positive integer")
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, invalid("COUNT must be a positive integer")
+			}
+			r.Count = n
+		case "UNTIL":
+			until, err := time.Parse(untilLayout, value)
+			if err != nil {
+				// A date-only UNTIL includes the whole day.
+				day, dayErr := time.Parse("20060102", value)
+				if dayErr != nil {
+					return nil, invalid("UNTIL must look like 20060102 or 20060102T150405Z")
+				}
+				until = day.Add(24*time.Hour - time.Second)
+			}
+			r.Until = until
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				day, ok := rruleDays[code]
+				if !ok {
+					return nil, invalid("unknown BYDAY %q", code)
+				}
+				if !slices.Contains(r.ByDay, day) {
+					r.ByDay = append(r.ByDay, day)
+				}
+			}
+			slices.SortFunc(r.ByDay, func(a, b time.Weekday) int { return weekdayOffset(a) - weekdayOffset(b) })
+		default:
+			return nil, invalid("unsupported part %s", key)
+		}
+	}
+
+	if r.Freq == "" {
+		return nil, invalid("FREQ is required")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return nil, invalid("COUNT and UNTIL cannot be combined")
+	}
+	if len(r.ByDay) > 0 && r.Freq != FreqDaily && r.Freq != FreqWeekly {
+		return nil, invalid("BYDAY is only supported with DAILY or WEEKLY")
+	}
+	// Every occurrence of such a rule falls on the weekday it started on,
+	// so BYDAY either repeats that day or never matches at all.
+	if len(r.ByDay) > 0 && r.Freq == FreqDaily && r.Interval%7 == 0 {
+		return nil, invalid("BYDAY cannot be combined with a DAILY INTERVAL that is a multiple of 7, use FREQ=WEEKLY")
+	}
+	return r, nil
+}
+
+// String formats the rule in canonical RRULE form, omitting defaults.
+func (r *Recurrence) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			codes = append(codes, strings.ToUpper(day.String()[:2]))
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayout))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns the occurrence following t, ignoring COUNT and UNTIL. It
+// returns the zero time if no occurrence is found within
+// maxRecurrenceSteps periods.
+func (r *Recurrence) Next(t time.Time) time.Time {
+	switch r.Freq {
+	case FreqDaily:
+		for step := 1; step <= 7; step++ {
+			next := t.AddDate(0, 0, step*r.Interval)
+			if len(r.ByDay) == 0 || slices.Contains(r.ByDay, next.Weekday()) {
+				return next
+			}
+		}
+		return time.Time{}
+
+	case FreqWeekly:
+		if len(r.ByDay) == 0 {
+			return t.AddDate(0, 0, 7*r.Interval)
+		}
+		// Later days in the same Monday-based week come first, then the
+		// first day of the next week in the series.
+		weekStart := t.AddDate(0, 0, -weekdayOffset(t.Weekday()))
+		for _, day := range r.ByDay {
+			if weekdayOffset(day) > weekdayOffset(t.Weekday()) {
+				return weekStart.AddDate(0, 0, weekdayOffset(day))
+			}
+		}
+		return weekStart.AddDate(0, 0, 7*r.Interval+weekdayOffset(r.ByDay[0]))
+
+	case FreqMonthly, FreqYearly:
+		// Dates that do not exist in a period, such as the 31st of a
+		// 30-day month, are skipped rather than rolled over.
+		for step := 1; step <= maxRecurrenceSteps; step++ {
+			months := step * r.Interval
+			if r.Freq == FreqYearly {
+				months *= 12
+			}
+			next := time.Date(t.Year(), t.Month()+time.Month(months), t.Day(),
+				t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+			if next.Day() == t.Day() {
+				return next
+			}
+		}
+		return time.Time{}
+	}
+	return time.Time{}
+}
+
+// Occurrences returns up to n occurrences after t, honouring COUNT (which
+// counts t itself) and UNTIL.
+func (r *Recurrence) Occurrences(t time.Time, n int) []time.Time {
+	out := []time.Time{}
+	for len(out) < n {
+		if r.Count > 0 && len(out)+1 >= r.Count {
+			break
+		}
+		t = r.Next(t)
+		if t.IsZero() || (!r.Until.IsZero() && t.After(r.Until)) {
+			break
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+// weekdayOffset returns the position of a weekday in a Monday-based week.
+func weekdayOffset(d time.Weekday) int {
+	return (int(d) + 6) % 7
+}
+
+// normalizeRecurrence validates a rule and returns its canonical form.
+func normalizeRecurrence(rule string) (string, error) {
+	if strings.TrimSpace(rule) == "" {
+		return "", nil
+	}
+	r, err := ParseRecurrence(rule)
+	if err != nil {
+		return "", err
+	}
+	return r.String(), nil
+}
+
+// nextOccurrence builds the task that follows a recurring task which has
+// just been completed. Occurrences that are already past are skipped and
+// count against COUNT. It returns nil when the series has ended.
+func (s *taskService) nextOccurrence(ctx context.Context, done *Task) (*Task, error) {
+	r, err := ParseRecurrence(done.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	due := done.DueDate
+	for {
+		if r.Count == 1 {
+			return nil, nil
+		}
+		due = r.Next(due)
+		if due.IsZero() || (!r.Until.IsZero() && due.After(r.Until)) {
+			return nil, nil
+		}
+		if r.Count > 0 {
+			r.Count--
+		}
+		if due.After(now) {
+			break
+		}
+	}
+
+	next := &Task{
+		Title:       done.Title,
+		Description: done.Description,
+		Status:      s.workflow.Initial(),
+		Priority:    done.Priority,
+		Tags:        slices.Clone(done.Tags),
+		Recurrence:  r.String(),
+		DueDate:     due,
+		CreatedAt:   now.UTC(),
+		UpdatedAt:   now.UTC(),
+	}
+	if done.ParentID != "" {
+		if _, err := s.getLive(ctx, done.ParentID); err == nil {
+			next.ParentID = done.ParentID
+		} else if !pkgerrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	return next, nil
+}
+
+// PreviewOccurrences returns the next n due dates of a recurring task.
+func (s *taskService) PreviewOccurrences(ctx context.Context, id string, n int) ([]time.Time, error) {
+	task, err := s.getLive(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Recurrence == "" {
+		return nil, pkgerrors.NewValidationError(CodeNotRecurring, ErrNotRecurring)
+	}
+	if n < 1 || n > MaxPreviewOccurrences {
+		return nil, pkgerrors.NewValidationError(CodeCountInvalid, fmt.Sprintf("count must be between 1 and %d", MaxPreviewOccurrences))
+	}
+
+	r, err := ParseRecurrence(task.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+	return r.Occurrences(task.DueDate, n), nil
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+)
+
+// Search limits.
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// SearchMatch is a task matching a full-text query. Highlights maps field
+// names to snippets with the matched words wrapped in <mark> tags.
+type SearchMatch struct {
+	TaskID     string
+	Score      float64
+	Highlights map[string]string
+}
+
+// TaskSearcher ranks live tasks against a full-text query. Search returns
+// up to limit matches, best first, and the total number of matches.
+type TaskSearcher interface {
+	Search(ctx context.Context, query string, limit int) ([]SearchMatch, int, error)
+}
+
+// SearchResult is a task returned by SearchTasks.
+type SearchResult struct {
+	Task       *Task             `json:"task"`
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+// SearchPage is the result of a full-text search. Total counts every
+// matching task, not only the Items returned.
+type SearchPage struct {
+	Items []*SearchResult `json:"items"`
+	Total int             `json:"total"`
+}
+
+// WithSearch sets the full-text index used by SearchTasks. Without it
+// searches find nothing.
+func WithSearch(searcher TaskSearcher) ServiceOption {
+	return func(s *taskService) {
+		s.search = searcher
+	}
+}
+
+// noSearch is the TaskSearcher used when search is disabled.
+type noSearch struct{}
+
+func (noSearch) Search(context.Context, string, int) ([]SearchMatch, int, error) {
+	return nil, 0, nil
+}
+
+// SearchTasks finds live tasks whose title or description match query.
+// limit defaults to DefaultSearchLimit.
+func (s *taskService) SearchTasks(ctx context.Context, query string, limit int) (*SearchPage, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, pkgerrors.NewValidationError(CodeQueryRequired, ErrQueryRequired)
+	}
+	if limit == 0 {
+		limit = DefaultSearchLimit
+	}
+	if limit < 1 || limit > MaxSearchLimit {
+		return nil, pkgerrors.NewValidationError(CodeLimitInvalid, fmt.Sprintf("limit must be between 1 and %d", MaxSearchLimit))
+	}
+
+	matches, total, err := s.search.Search(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &SearchPage{Items: make([]*SearchResult, 0, len(matches)), Total: total}
+	for _, m := range matches {
+		// The index can briefly trail a concurrent delete.
+		task, err := s.getLive(ctx, m.TaskID)
+		if pkgerrors.IsNotFound(err) {
+			page.Total--
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		page.Items = append(page.Items, &SearchResult{Task: task, Score: m.Score, Highlights: m.Highlights})
+	}
+	return page, nil
+}
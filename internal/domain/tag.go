@@ -0,0 +1,240 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+)
+
+// Tag limits enforced by NormalizeTags.
+const (
+	MaxTagLength   = 32
+	MaxTagsPerTask = 20
+)
+
+// TagMatch selects how a filter with several tags is applied.
+type TagMatch string
+
+const (
+	TagMatchAny TagMatch = "any" // task has at least one of the tags
+	TagMatchAll TagMatch = "all" // task has every tag
+)
+
+// TagCount is a tag together with the number of live tasks using it.
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// NormalizeTag trims and lower-cases a tag, rejecting empty tags, tags with
+// commas (the list separator in query strings) and overly long tags.
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" || strings.Contains(tag, ",") {
+		return "", pkgerrors.NewValidationError(CodeTagInvalid, fmt.Sprintf("%s: %q", ErrTagInvalid, tag))
+	}
+	if utf8.RuneCountInString(tag) > MaxTagLength {
+		return "", pkgerrors.NewValidationError(CodeTagInvalid, fmt.Sprintf("%s: %q exceeds %d characters", ErrTagInvalid, tag, MaxTagLength))
+	}
+	return tag, nil
+}
+
+// NormalizeTags normalizes every tag and returns them sorted without
+// duplicates.
+func NormalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		norm, err := NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, norm)
+	}
+	slices.Sort(out)
+	return slices.Compact(out), nil
+}
+
+// normalizeTaskTags normalizes the tags of a task and enforces the per-task
+// limit.
+func normalizeTaskTags(tags []string) ([]string, error) {
+	out, err := NormalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) > MaxTagsPerTask {
+		return nil, pkgerrors.NewValidationError(CodeTooManyTags, fmt.Sprintf("%s: at most %d allowed", ErrTooManyTags, MaxTagsPerTask))
+	}
+	return out, nil
+}
+
+// matchesTags reports whether tags satisfies wanted under the match mode.
+func matchesTags(tags, wanted []string, match TagMatch) bool {
+	if len(wanted) == 0 {
+		return true
+	}
+	for _, w := range wanted {
+		has := slices.Contains(tags, w)
+		if has && match != TagMatchAll {
+			return true
+		}
+		if !has && match == TagMatchAll {
+			return false
+		}
+	}
+	return match == TagMatchAll
+}
+
+// ListTags returns every tag used by a live task with its usage count, most
+// used first.
+func (s *taskService) ListTags(ctx context.Context) ([]TagCount, error) {
+	tasks, err := s.repo.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, t := range tasks {
+		if t.IsDeleted() {
+			continue
+		}
+		for _, tag := range t.Tags {
+			counts[tag]++
+		}
+	}
+
+	out := make([]TagCount, 0, len(counts))
+	for tag, n := range counts {
+		out = append(out, TagCount{Tag: tag, Count: n})
+	}
+	slices.SortFunc(out, func(a, b TagCount) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Tag, b.Tag)
+	})
+	return out, nil
+}
+
+// RenameTag replaces tag from with to on every task, including trashed ones,
+// and returns how many tasks changed. Renaming onto a tag that is already in
+// use merges the two. When the repository implements TaskBatcher the rename
+// is applied as a single batch; otherwise it is not atomic: tasks are
+// renamed one at a time, a task changed concurrently is re-read and retried,
+// and a failure leaves the tasks already renamed in place.
+func (s *taskService) RenameTag(ctx context.Context, from, to string) (int, error) {
+	from, err := NormalizeTag(from)
+	if err != nil {
+		return 0, err
+	}
+	to, err = NormalizeTag(to)
+	if err != nil {
+		return 0, err
+	}
+
+	batcher, ok := s.repo.(TaskBatcher)
+	if !ok {
+		return s.renameTag(ctx, from, to)
+	}
+
+	var updated int
+	history := &batchHistory{}
+	err = batcher.Batch(ctx, func(repo TaskRepository) error {
+		tx := *s
+		tx.repo = repo
+		tx.history = history
+		updated, err = tx.renameTag(ctx, from, to)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	s.appendHistory(ctx, history.entries...)
+	return updated, nil
+}
+
+// renameTagRetries is how many times renameTag re-reads a task that changed
+// under it before giving up.
+const renameTagRetries = 3
+
+// renameTag renames from to to on every task holding it.
+func (s *taskService) renameTag(ctx context.Context, from, to string) (int, error) {
+	tasks, err := s.repo.ListAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	found := false
+	updated := 0
+	now := time.Now().UTC()
+	for _, t := range tasks {
+		if !slices.Contains(t.Tags, from) {
+			continue
+		}
+		found = true
+		if from == to {
+			continue
+		}
+
+		saved, err := s.retagFresh(ctx, t, from, to, now)
+		if pkgerrors.IsNotFound(err) {
+			continue // purged since it was listed
+		}
+		if err != nil {
+			return updated, err
+		}
+		if saved {
+			updated++
+		}
+	}
+
+	if !found {
+		return 0, pkgerrors.NewNotFoundError(CodeTagNotFound, ErrTagNotFound)
+	}
+	return updated, nil
+}
+
+// retagFresh retags t, re-reading and retrying it when it was changed
+// concurrently. It reports whether t was saved; a task that lost the tag
+// meanwhile is left alone.
+func (s *taskService) retagFresh(ctx context.Context, t *Task, from, to string, now time.Time) (bool, error) {
+	for attempt := 1; ; attempt++ {
+		err := s.retag(ctx, t, from, to, now)
+		if err == nil {
+			return true, nil
+		}
+		if !pkgerrors.IsConflict(err) || attempt == renameTagRetries {
+			return false, err
+		}
+		if t, err = s.repo.GetByID(ctx, t.ID); err != nil {
+			return false, err
+		}
+		if !slices.Contains(t.Tags, from) {
+			return false, nil
+		}
+	}
+}
+
+// retag replaces from with to on a single task and saves it.
+func (s *taskService) retag(ctx context.Context, t *Task, from, to string, now time.Time) error {
+	before := *t
+	tags := make([]string, 0, len(t.Tags))
+	for _, tag := range t.Tags {
+		if tag == from {
+			tag = to
+		}
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+	t.Tags = slices.Compact(tags)
+	t.UpdatedAt = now
+	return s.save(ctx, HistoryUpdated, before, t)
+}
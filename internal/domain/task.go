@@ -11,19 +11,129 @@ const (
 	StatusDone       TaskStatus = "DONE"
 )
 
+// Priority represents how urgent a task is.
+type Priority string
+
+const (
+	PriorityLow    Priority = "LOW"
+	PriorityMedium Priority = "MEDIUM"
+	PriorityHigh   Priority = "HIGH"
+	PriorityUrgent Priority = "URGENT"
+)
+
+// Priorities lists every priority from most to least urgent.
+var Priorities = []Priority{PriorityUrgent, PriorityHigh, PriorityMedium, PriorityLow}
+
+// Rank orders priorities from LOW (1) to URGENT (4); unknown values rank 0.
+func (p Priority) Rank() int {
+	switch p {
+	case PriorityLow:
+		return 1
+	case PriorityMedium:
+		return 2
+	case PriorityHigh:
+		return 3
+	case PriorityUrgent:
+		return 4
+	default:
+		return 0
+	}
+}
+
 // Task represents a task entity in the domain.
 type Task struct {
 	ID          string     `json:"id"`
+	ParentID    string     `json:"parent_id,omitempty"`
 	Title       string     `json:"title"`
 	Description string     `json:"description,omitempty"`
 	Status      TaskStatus `json:"status"`
+	Priority    Priority   `json:"priority"`
+	Tags        []string   `json:"tags,omitempty"`       // normalized, sorted and unique
+	BlockedBy   []string   `json:"blocked_by,omitempty"` // IDs of tasks that must be done first
 	DueDate     time.Time  `json:"due_date"`
+	Recurrence  string     `json:"recurrence,omitempty"` // RRULE subset, see ParseRecurrence
+	Version     int64      `json:"version"`              // incremented on every update
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"` // set while the task is in the trash
+}
+
+// IsDeleted reports whether the task has been moved to the trash.
+func (t *Task) IsDeleted() bool {
+	return t.DeletedAt != nil
 }
 
 // Validation error messages
 const (
-	ErrTitleRequired   = "title is required"
-	ErrDueDateRequired = "due_date is required"
-	ErrDueDatePast     = "due_date must be in the future"
-	ErrStatusInvalid   = "invalid status"
+	ErrTitleRequired      = "title is required"
+	ErrDueDateRequired    = "due_date is required"
+	ErrDueDatePast        = "due_date must be in the future"
+	ErrDueDateInvalid     = "invalid due_date format, expected RFC3339"
+	ErrStatusInvalid      = "invalid status"
+	ErrTransitionInvalid  = "invalid status transition"
+	ErrPriorityInvalid    = "invalid priority"
+	ErrTagInvalid         = "invalid tag"
+	ErrTooManyTags        = "too many tags"
+	ErrTagMatchInvalid    = "invalid tag match"
+	ErrTagNotFound        = "tag not found"
+	ErrParentNotFound     = "parent task not found"
+	ErrParentCycle        = "parent would create a cycle"
+	ErrOpenChildren       = "task has open children"
+	ErrBlockerNotFound    = "blocking task not found"
+	ErrDependencyCycle    = "dependency would create a cycle"
+	ErrDependencyNotFound = "dependency not found"
+	ErrTaskBlocked        = "task is blocked by unfinished tasks"
+	ErrRecurrenceInvalid  = "invalid recurrence"
+	ErrNotRecurring       = "task does not recur"
+	ErrCursorInvalid      = "invalid cursor"
+	ErrSortInvalid        = "invalid sort"
+	ErrFilterInvalid      = "invalid filter"
+	ErrQueryRequired      = "search query is required"
+	ErrBatchEmpty         = "batch has no operations"
+	ErrBatchOpInvalid     = "invalid batch operation"
+	ErrBatchAborted       = "not applied because another operation in the batch failed"
+	ErrBatchUnsupported   = "atomic batches are not supported by this storage backend"
+	ErrVersionConflict    = "task has been modified by another request"
+	ErrTaskNotFound       = "task not found"
+	ErrNotInTrash         = "task not found in trash"
+)
+
+// Error codes, one for each message above plus the range checks whose
+// messages are formatted where they fail. Clients match on codes, so a code
+// stays the same when its message is reworded.
+const (
+	CodeTitleRequired      = "title_required"
+	CodeDueDateRequired    = "due_date_required"
+	CodeDueDatePast        = "due_date_past"
+	CodeDueDateInvalid     = "due_date_invalid"
+	CodeStatusInvalid      = "status_invalid"
+	CodeTransitionInvalid  = "transition_invalid"
+	CodePriorityInvalid    = "priority_invalid"
+	CodeTagInvalid         = "tag_invalid"
+	CodeTooManyTags        = "too_many_tags"
+	CodeTagMatchInvalid    = "tag_match_invalid"
+	CodeTagNotFound        = "tag_not_found"
+	CodeParentNotFound     = "parent_not_found"
+	CodeParentCycle        = "parent_cycle"
+	CodeOpenChildren       = "open_children"
+	CodeBlockerNotFound    = "blocker_not_found"
+	CodeDependencyCycle    = "dependency_cycle"
+	CodeDependencyNotFound = "dependency_not_found"
+	CodeTaskBlocked        = "task_blocked"
+	CodeRecurrenceInvalid  = "recurrence_invalid"
+	CodeNotRecurring       = "not_recurring"
+	CodeCursorInvalid      = "cursor_invalid"
+	CodeSortInvalid        = "sort_invalid"
+	CodeFilterInvalid      = "filter_invalid"
+	CodeQueryRequired      = "query_required"
+	CodeBatchEmpty         = "batch_empty"
+	CodeBatchOpInvalid     = "batch_op_invalid"
+	CodeBatchAborted       = "batch_aborted"
+	CodeBatchUnsupported   = "batch_unsupported"
+	CodeVersionConflict    = "version_conflict"
+	CodeTaskNotFound       = "task_not_found"
+	CodeNotInTrash         = "not_in_trash"
+	CodeBatchTooLarge      = "batch_too_large"
+	CodeLimitInvalid       = "limit_invalid"
+	CodeCountInvalid       = "count_invalid"
 )
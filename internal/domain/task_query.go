@@ -0,0 +1,286 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+)
+
+// SortField names a task attribute that lists can be ordered by.
+type SortField string
+
+const (
+	SortByDueDate   SortField = "due_date"
+	SortByTitle     SortField = "title"
+	SortByCreatedAt SortField = "created_at"
+	SortByUpdatedAt SortField = "updated_at"
+	SortByPriority  SortField = "priority"
+)
+
+// isValidSortField checks if a field can be sorted on.
+func isValidSortField(f SortField) bool {
+	switch f {
+	case SortByDueDate, SortByTitle, SortByCreatedAt, SortByUpdatedAt, SortByPriority:
+		return true
+	default:
+		return false
+	}
+}
+
+// SortKey orders results by a single field.
+type SortKey struct {
+	Field SortField
+	Desc  bool
+}
+
+// ParseSort parses a comma-separated sort expression such as
+// "-updated_at,title". A leading "-" sorts that key in descending order.
+func ParseSort(expr string) ([]SortKey, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(expr, ",")
+	keys := make([]SortKey, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		key := SortKey{Field: SortField(part)}
+		if rest, ok := strings.CutPrefix(part, "-"); ok {
+			key = SortKey{Field: SortField(rest), Desc: true}
+		} else if rest, ok := strings.CutPrefix(part, "+"); ok {
+			key = SortKey{Field: SortField(rest)}
+		}
+		keys = append(keys, key)
+	}
+
+	if err := validateSort(keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// validateSort rejects unknown and repeated sort fields.
+func validateSort(keys []SortKey) error {
+	seen := make(map[SortField]bool, len(keys))
+	for _, k := range keys {
+		if !isValidSortField(k.Field) {
+			return pkgerrors.NewValidationError(CodeSortInvalid, fmt.Sprintf("%s: unknown field %q", ErrSortInvalid, k.Field))
+		}
+		if seen[k.Field] {
+			return pkgerrors.NewValidationError(CodeSortInvalid, fmt.Sprintf("%s: duplicate field %q", ErrSortInvalid, k.Field))
+		}
+		seen[k.Field] = true
+	}
+	return nil
+}
+
+// DefaultSort is the ordering used when a filter does not specify one: most
+// urgent first, then earliest due.
+var DefaultSort = []SortKey{{Field: SortByPriority, Desc: true}, {Field: SortByDueDate}}
+
+// FormatSort is the inverse of ParseSort.
+func FormatSort(keys []SortKey) string {
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k.Desc {
+			parts = append(parts, "-"+string(k.Field))
+		} else {
+			parts = append(parts, string(k.Field))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// TaskPage is a single page of query results.
+type TaskPage struct {
+	Tasks      []*Task `json:"items"`
+	Total      int     `json:"total"`                 // number of tasks matching the filter, across all pages
+	NextCursor string  `json:"next_cursor,omitempty"` // cursor for the following page, empty on the last page
+}
+
+// cursorPayload is the decoded form of a cursor: the sort order it was
+// issued for and the sort values and ID of the last task on the page.
+type cursorPayload struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+	ID     string   `json:"id"`
+}
+
+// EncodeCursor returns an opaque token for the position just after task in
+// the given order. Because it carries the sort values rather than an offset,
+// paging with it is unaffected by tasks inserted or removed in between.
+func EncodeCursor(task *Task, keys []SortKey) string {
+	p := cursorPayload{Sort: FormatSort(keys), Values: make([]string, 0, len(keys)), ID: task.ID}
+	for _, k := range keys {
+		p.Values = append(p.Values, sortValue(task, k.Field))
+	}
+	data, _ := json.Marshal(p)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor returns the position encoded by a token as a task holding only
+// its ID and sort fields. Tokens issued for another order are rejected.
+func DecodeCursor(token string, keys []SortKey) (*Task, error) {
+	invalid := pkgerrors.NewValidationError(CodeCursorInvalid, ErrCursorInvalid)
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, invalid
+	}
+	var p cursorPayload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, invalid
+	}
+	if p.ID == "" || p.Sort != FormatSort(keys) || len(p.Values) != len(keys) {
+		return nil, invalid
+	}
+
+	task := &Task{ID: p.ID}
+	for i, k := range keys {
+		if err := setSortValue(task, k.Field, p.Values[i]); err != nil {
+			return nil, invalid
+		}
+	}
+	return task, nil
+}
+
+// sortValue returns a sort field of a task as a string.
+func sortValue(t *Task, f SortField) string {
+	switch f {
+	case SortByTitle:
+		return t.Title
+	case SortByCreatedAt:
+		return t.CreatedAt.Format(time.RFC3339Nano)
+	case SortByUpdatedAt:
+		return t.UpdatedAt.Format(time.RFC3339Nano)
+	case SortByPriority:
+		return string(t.Priority)
+	default:
+		return t.DueDate.Format(time.RFC3339Nano)
+	}
+}
+
+// setSortValue is the inverse of sortValue.
+func setSortValue(t *Task, f SortField, v string) error {
+	var err error
+	switch f {
+	case SortByTitle:
+		t.Title = v
+	case SortByCreatedAt:
+		t.CreatedAt, err = time.Parse(time.RFC3339Nano, v)
+	case SortByUpdatedAt:
+		t.UpdatedAt, err = time.Parse(time.RFC3339Nano, v)
+	case SortByPriority:
+		t.Priority = Priority(v)
+	default:
+		t.DueDate, err = time.Parse(time.RFC3339Nano, v)
+	}
+	return err
+}
+
+// DefaultPageSize is the page size used when a filter does not specify one.
+const DefaultPageSize = 10
+
+// CompareTasks orders two tasks by the given sort keys. Ties are broken by ID
+// in the direction of the last key so the order is total, stable across
+// backends, and exactly reversed when every key is flipped.
+func CompareTasks(a, b *Task, keys []SortKey) int {
+	for _, k := range keys {
+		c := compareField(a, b, k.Field)
+		if k.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	c := strings.Compare(a.ID, b.ID)
+	if len(keys) > 0 && keys[len(keys)-1].Desc {
+		c = -c
+	}
+	return c
+}
+
+// compareField compares a single field of two tasks.
+func compareField(a, b *Task, f SortField) int {
+	switch f {
+	case SortByDueDate:
+		return a.DueDate.Compare(b.DueDate)
+	case SortByTitle:
+		return strings.Compare(a.Title, b.Title)
+	case SortByCreatedAt:
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case SortByUpdatedAt:
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	case SortByPriority:
+		return a.Priority.Rank() - b.Priority.Rank()
+	default:
+		return 0
+	}
+}
+
+// Matches reports whether a task satisfies the filter's criteria.
+func (f TaskFilter) Matches(t *Task) bool {
+	if t.IsDeleted() != f.Trashed {
+		return false
+	}
+	if f.ParentID != "" && t.ParentID != f.ParentID {
+		return false
+	}
+	if f.Status != nil && t.Status != *f.Status {
+		return false
+	}
+	if len(f.Priorities) > 0 && !slices.Contains(f.Priorities, t.Priority) {
+		return false
+	}
+	if !matchesTags(t.Tags, f.Tags, f.TagMatch) {
+		return false
+	}
+	if f.Expr != nil && !f.Expr.Matches(t) {
+		return false
+	}
+	return true
+}
+
+// normalizeFilter normalizes the filter's tags and rejects unknown
+// priorities, tag match modes or sort fields.
+func normalizeFilter(f TaskFilter) (TaskFilter, error) {
+	for _, p := range f.Priorities {
+		if !isValidPriority(p) {
+			return f, pkgerrors.NewValidationError(CodePriorityInvalid, ErrPriorityInvalid)
+		}
+	}
+
+	switch f.TagMatch {
+	case "", TagMatchAny, TagMatchAll:
+	default:
+		return f, pkgerrors.NewValidationError(CodeTagMatchInvalid, ErrTagMatchInvalid)
+	}
+	tags, err := NormalizeTags(f.Tags)
+	if err != nil {
+		return f, err
+	}
+	f.Tags = tags
+
+	return f, validateSort(f.Sort)
+}
+
+// WithDefaults returns a copy of the filter with default paging and sorting
+// applied.
+func (f TaskFilter) WithDefaults() TaskFilter {
+	if f.Page <= 0 {
+		f.Page = 1
+	}
+	if f.PageSize <= 0 {
+		f.PageSize = DefaultPageSize
+	}
+	if len(f.Sort) == 0 {
+		f.Sort = DefaultSort
+	}
+	return f
+}
@@ -1,124 +1,353 @@
 package domain
 
 import (
-	"sort"
+	"context"
+	"errors"
+	"sync"
 	"time"
 
 	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
 )
 
-// TaskRepository defines the persistence interface.
+// TaskRepository defines the persistence interface. Implementations must
+// return ctx.Err() once ctx is cancelled instead of touching storage.
+// Create sets Version to 1. Update rejects a task whose Version differs from
+// the stored one with a conflict error, and increments Version on success.
+// DeleteVersion deletes a task only while its stored Version is version,
+// returning a conflict error otherwise.
 type TaskRepository interface {
-	Create(task *Task) error
-	GetByID(id string) (*Task, error)
-	Update(task *Task) error
-	Delete(id string) error
-	ListAll() ([]*Task, error)
+	Create(ctx context.Context, task *Task) error
+	GetByID(ctx context.Context, id string) (*Task, error)
+	Update(ctx context.Context, task *Task) error
+	Delete(ctx context.Context, id string) error
+	DeleteVersion(ctx context.Context, id string, version int64) error
+	ListAll(ctx context.Context) ([]*Task, error)
+	Query(ctx context.Context, filter TaskFilter) (*TaskPage, error)
 }
 
 // TaskService defines the business logic interface.
 type TaskService interface {
-	CreateTask(input CreateTaskInput) (*Task, error)
-	GetTask(id string) (*Task, error)
-	UpdateTask(id string, input UpdateTaskInput) (*Task, error)
-	DeleteTask(id string) error
-	ListTasks(filter TaskFilter) ([]*Task, error)
+	CreateTask(ctx context.Context, input CreateTaskInput) (*Task, error)
+	GetTask(ctx context.Context, id string) (*Task, error)
+	UpdateTask(ctx context.Context, id string, input UpdateTaskInput) (*Task, error)
+	ReplaceTask(ctx context.Context, id string, input ReplaceTaskInput) (*Task, error)
+	DeleteTask(ctx context.Context, id string) error
+	ListTasks(ctx context.Context, filter TaskFilter) ([]*Task, error)
+	QueryTasks(ctx context.Context, filter TaskFilter) (*TaskPage, error)
+	SearchTasks(ctx context.Context, query string, limit int) (*SearchPage, error)
+
+	// Batches of creates, updates and deletes, with one result per
+	// operation. ApplyBatch applies each operation on its own;
+	// ApplyBatchAtomic applies all of them or none.
+	ApplyBatch(ctx context.Context, ops []BatchOperation) ([]BatchResult, error)
+	ApplyBatchAtomic(ctx context.Context, ops []BatchOperation) ([]BatchResult, error)
+
+	// Trash management. DeleteTask only moves a task to the trash.
+	ListTrash(ctx context.Context, filter TaskFilter) (*TaskPage, error)
+	RestoreTask(ctx context.Context, id string) (*Task, error)
+	PurgeTask(ctx context.Context, id string) error
+	PurgeTrash(ctx context.Context, deletedBefore time.Time) (int, error)
+
+	// Subtasks.
+	ListChildren(ctx context.Context, id string, filter TaskFilter) (*TaskPage, error)
+
+	// Dependencies between tasks.
+	AddDependency(ctx context.Context, id, blockerID string) (*Task, error)
+	RemoveDependency(ctx context.Context, id, blockerID string) (*Task, error)
+	DependencyGraph(ctx context.Context, id string) (*DependencyGraph, error)
+
+	// Recurring tasks. Completing a recurring task creates its next
+	// occurrence.
+	PreviewOccurrences(ctx context.Context, id string, n int) ([]time.Time, error)
+
+	// Audit history, oldest entry first.
+	GetHistory(ctx context.Context, id string) ([]*HistoryEntry, error)
+
+	// Tag management across all tasks.
+	ListTags(ctx context.Context) ([]TagCount, error)
+	RenameTag(ctx context.Context, from, to string) (int, error)
+
+	// Checks of CreateTask and ReplaceTask input that store nothing, so
+	// callers can report them along with their own.
+	ValidateCreate(ctx context.Context, input CreateTaskInput) error
+	ValidateReplace(ctx context.Context, id string, input ReplaceTaskInput) error
 }
 
 // CreateTaskInput is the input for creating a task.
 type CreateTaskInput struct {
+	ParentID    string // optional, must name a live task
 	Title       string
 	Description string
 	Status      *TaskStatus
+	Priority    *Priority // defaults to PriorityMedium
+	Tags        []string
 	DueDate     time.Time
+	Recurrence  string // optional RRULE, e.g. "FREQ=WEEKLY;BYDAY=MO"
 }
 
 // UpdateTaskInput is the input for updating a task (all fields optional).
+// When ExpectedVersion is set the update is rejected with a conflict error
+// unless it matches the stored version.
 type UpdateTaskInput struct {
-	Title       *string
-	Description *string
-	Status      *TaskStatus
-	DueDate     *time.Time
+	ParentID        *string // an empty string detaches the task from its parent
+	Title           *string
+	Description     *string
+	Status          *TaskStatus
+	Priority        *Priority
+	Tags            *[]string // replaces all tags; an empty list clears them
+	DueDate         *time.Time
+	Recurrence      *string // an empty string stops the task recurring
+	ExpectedVersion *int64
+}
+
+// ReplaceTaskInput is the input for replacing every writable field of a
+// task. Optional fields that are left out take their CreateTaskInput
+// defaults. ExpectedVersion works as in UpdateTaskInput.
+type ReplaceTaskInput struct {
+	CreateTaskInput
+	ExpectedVersion *int64
 }
 
-// TaskFilter is used for listing tasks with filters and pagination.
+// TaskFilter is used for listing tasks with filters, sorting and pagination.
+// When Cursor is set it takes precedence over Page. Trashed selects tasks in
+// the trash instead of live ones.
 type TaskFilter struct {
-	Status   *TaskStatus
-	Page     int
-	PageSize int
+	ParentID   string // only children of this task
+	Status     *TaskStatus
+	Priorities []Priority // matches any of the given priorities
+	Tags       []string
+	TagMatch   TagMatch   // how Tags is applied, defaults to TagMatchAny
+	Expr       FilterExpr // parsed filter expression, nil matches everything
+	Trashed    bool
+	Sort       []SortKey
+	Page       int
+	PageSize   int
+	Cursor     string // NextCursor of the previous page
 }
 
 // taskService implements TaskService interface.
 type taskService struct {
-	repo TaskRepository
+	repo      TaskRepository
+	hierarchy HierarchyPolicy
+	workflow  *Workflow
+	history   HistoryRepository
+	search    TaskSearcher
+
+	// dependencies serializes adding dependencies, so two concurrent
+	// additions cannot each pass the cycle check and together close a cycle.
+	// It is shared by copies of the service made for batches.
+	dependencies *sync.Mutex
 }
 
+// ServiceOption configures optional behaviour of a TaskService.
+type ServiceOption func(*taskService)
+
 // NewTaskService creates and returns a new TaskService.
-func NewTaskService(repo TaskRepository) TaskService {
-	return &taskService{repo: repo}
+func NewTaskService(repo TaskRepository, opts ...ServiceOption) TaskService {
+	s := &taskService{repo: repo, hierarchy: DefaultHierarchyPolicy, workflow: DefaultWorkflow(), history: noHistory{}, search: noSearch{}, dependencies: &sync.Mutex{}}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // CreateTask creates a new task with validation.
-func (s *taskService) CreateTask(input CreateTaskInput) (*Task, error) {
+func (s *taskService) CreateTask(ctx context.Context, input CreateTaskInput) (*Task, error) {
+	task, err := s.newTask(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Persist
+	if err := s.insert(ctx, task); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// ValidateCreate checks input as CreateTask does, without creating a task.
+func (s *taskService) ValidateCreate(ctx context.Context, input CreateTaskInput) error {
+	_, err := s.newTask(ctx, input)
+	return err
+}
+
+// newTask validates input and builds the task CreateTask stores.
+func (s *taskService) newTask(ctx context.Context, input CreateTaskInput) (*Task, error) {
+	// Every invalid field is reported at once
+	var invalid fieldErrors
+
 	// Validate title
 	if input.Title == "" {
-		return nil, pkgerrors.NewValidationError(ErrTitleRequired)
+		invalid.add("title", CodeTitleRequired, ErrTitleRequired)
 	}
 
 	// Validate due date
 	if input.DueDate.IsZero() {
-		return nil, pkgerrors.NewValidationError(ErrDueDateRequired)
-	}
-	if !input.DueDate.After(time.Now()) {
-		return nil, pkgerrors.NewValidationError(ErrDueDatePast)
+		invalid.add("due_date", CodeDueDateRequired, ErrDueDateRequired)
+	} else if !input.DueDate.After(time.Now()) {
+		invalid.add("due_date", CodeDueDatePast, ErrDueDatePast)
 	}
 
 	// Set default status or validate provided status
-	status := StatusPending
+	status := s.workflow.Initial()
 	if input.Status != nil {
-		if !isValidStatus(*input.Status) {
-			return nil, pkgerrors.NewValidationError(ErrStatusInvalid)
+		if !s.workflow.HasState(*input.Status) {
+			invalid.add("status", CodeStatusInvalid, ErrStatusInvalid)
 		}
 		status = *input.Status
 	}
 
+	// Set default priority or validate provided priority
+	priority := PriorityMedium
+	if input.Priority != nil {
+		if !isValidPriority(*input.Priority) {
+			invalid.add("priority", CodePriorityInvalid, ErrPriorityInvalid)
+		}
+		priority = *input.Priority
+	}
+
+	// Normalize tags
+	tags, err := normalizeTaskTags(input.Tags)
+	if err := invalid.check("tags", err); err != nil {
+		return nil, err
+	}
+
+	// Validate recurrence rule
+	recurrence, err := normalizeRecurrence(input.Recurrence)
+	if err := invalid.check("recurrence", err); err != nil {
+		return nil, err
+	}
+
+	// Validate parent if provided
+	if input.ParentID != "" {
+		if err := invalid.check("parent_id", s.validateParent(ctx, "", input.ParentID)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := invalid.err(); err != nil {
+		return nil, err
+	}
+
 	// Create task entity
+	now := time.Now().UTC()
 	task := &Task{
+		ParentID:    input.ParentID,
 		Title:       input.Title,
 		Description: input.Description,
 		Status:      status,
+		Priority:    priority,
+		Tags:        tags,
 		DueDate:     input.DueDate,
+		Recurrence:  recurrence,
+		CreatedAt:   now,
+		UpdatedAt:   now,
 	}
 
-	// Persist
-	if err := s.repo.Create(task); err != nil {
+	// A task created in another state must be able to reach it
+	if err := s.workflow.CheckReachable(task); err != nil {
 		return nil, err
 	}
-
 	return task, nil
 }
 
 // GetTask retrieves a task by ID.
-func (s *taskService) GetTask(id string) (*Task, error) {
-	task, err := s.repo.GetByID(id)
-	if err != nil {
-		return nil, err
-	}
-	return task, nil
+func (s *taskService) GetTask(ctx context.Context, id string) (*Task, error) {
+	return s.getLive(ctx, id)
 }
 
 // UpdateTask updates an existing task with partial or full updates.
-func (s *taskService) UpdateTask(id string, input UpdateTaskInput) (*Task, error) {
+func (s *taskService) UpdateTask(ctx context.Context, id string, input UpdateTaskInput) (*Task, error) {
 	// Get existing task
-	task, err := s.repo.GetByID(id)
+	task, err := s.getLive(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	// Check the caller's view of the task is current
+	if input.ExpectedVersion != nil && *input.ExpectedVersion != task.Version {
+		return nil, pkgerrors.NewConflictError(CodeVersionConflict, ErrVersionConflict)
+	}
+	before := *task
+
+	if err := s.applyUpdate(ctx, task, input); err != nil {
+		return nil, err
+	}
+
+	// Starting a task needs its blockers done, and completing it needs its
+	// children done
+	completed := false
+	from := before.Status
+	if task.Status == StatusInProgress && from != StatusInProgress {
+		if err := s.checkBlockers(ctx, task); err != nil {
+			return nil, err
+		}
+	}
+	if task.Status == StatusDone && from != StatusDone {
+		if err := s.checkCompletion(ctx, task); err != nil {
+			return nil, err
+		}
+		completed = true
+	}
+
+	// Check the workflow allows the status change, guards included
+	if err := s.workflow.Check(from, task); err != nil {
+		return nil, err
+	}
+
+	// A completed recurring task hands its rule on to the next occurrence,
+	// so reopening and completing it again does not repeat the series.
+	var next *Task
+	if completed && task.Recurrence != "" {
+		if next, err = s.nextOccurrence(ctx, task); err != nil {
+			return nil, err
+		}
+		task.Recurrence = ""
+	}
+
+	// Persist
+	task.UpdatedAt = time.Now().UTC()
+	if err := s.save(ctx, HistoryUpdated, before, task); err != nil {
+		return nil, err
+	}
+
+	// Schedule the next occurrence
+	if next != nil {
+		if err := s.insert(ctx, next); err != nil {
+			return nil, err
+		}
+	}
+
+	// Roll completion up to the parent
+	if completed {
+		if err := s.completeAncestors(ctx, task); err != nil {
+			return nil, err
+		}
+	}
+
+	return task, nil
+}
+
+// applyUpdate validates input and applies it to task.
+func (s *taskService) applyUpdate(ctx context.Context, task *Task, input UpdateTaskInput) error {
+	// Every invalid field is reported at once
+	var invalid fieldErrors
+
+	// Update parent
+	if input.ParentID != nil && *input.ParentID != task.ParentID {
+		if *input.ParentID != "" {
+			if err := invalid.check("parent_id", s.validateParent(ctx, task.ID, *input.ParentID)); err != nil {
+				return err
+			}
+		}
+		task.ParentID = *input.ParentID
+	}
+
 	// Update title
 	if input.Title != nil {
 		if *input.Title == "" {
-			return nil, pkgerrors.NewValidationError(ErrTitleRequired)
+			invalid.add("title", CodeTitleRequired, ErrTitleRequired)
 		}
 		task.Title = *input.Title
 	}
@@ -130,89 +359,175 @@ func (s *taskService) UpdateTask(id string, input UpdateTaskInput) (*Task, error
 
 	// Update status
 	if input.Status != nil {
-		if !isValidStatus(*input.Status) {
-			return nil, pkgerrors.NewValidationError(ErrStatusInvalid)
+		if !s.workflow.HasState(*input.Status) {
+			invalid.add("status", CodeStatusInvalid, ErrStatusInvalid)
 		}
 		task.Status = *input.Status
 	}
 
+	// Update priority
+	if input.Priority != nil {
+		if !isValidPriority(*input.Priority) {
+			invalid.add("priority", CodePriorityInvalid, ErrPriorityInvalid)
+		}
+		task.Priority = *input.Priority
+	}
+
+	// Update tags
+	if input.Tags != nil {
+		tags, err := normalizeTaskTags(*input.Tags)
+		if err := invalid.check("tags", err); err != nil {
+			return err
+		}
+		task.Tags = tags
+	}
+
 	// Update due date
 	if input.DueDate != nil {
-		if input.DueDate.IsZero() {
-			return nil, pkgerrors.NewValidationError(ErrDueDateRequired)
-		}
-		if !input.DueDate.After(time.Now()) {
-			return nil, pkgerrors.NewValidationError(ErrDueDatePast)
+		// An unchanged due date may already have passed
+		switch {
+		case input.DueDate.IsZero():
+			invalid.add("due_date", CodeDueDateRequired, ErrDueDateRequired)
+		case !input.DueDate.Equal(task.DueDate) && !input.DueDate.After(time.Now()):
+			invalid.add("due_date", CodeDueDatePast, ErrDueDatePast)
 		}
 		task.DueDate = *input.DueDate
 	}
 
-	// Persist
-	if err := s.repo.Update(task); err != nil {
-		return nil, err
+	// Update recurrence rule
+	if input.Recurrence != nil {
+		recurrence, err := normalizeRecurrence(*input.Recurrence)
+		if err := invalid.check("recurrence", err); err != nil {
+			return err
+		}
+		task.Recurrence = recurrence
 	}
 
-	return task, nil
+	return invalid.err()
 }
 
-// DeleteTask deletes a task by ID.
-func (s *taskService) DeleteTask(id string) error {
-	return s.repo.Delete(id)
+// ReplaceTask replaces every writable field of a task, so fields left out
+// of the input are reset to their defaults rather than kept.
+func (s *taskService) ReplaceTask(ctx context.Context, id string, input ReplaceTaskInput) (*Task, error) {
+	return s.UpdateTask(ctx, id, s.replacement(input))
 }
 
-// ListTasks lists all tasks with optional filtering and pagination.
-func (s *taskService) ListTasks(filter TaskFilter) ([]*Task, error) {
-	// Get all tasks
-	tasks, err := s.repo.ListAll()
+// ValidateReplace checks input as ReplaceTask does, without replacing the
+// task. The version it expects is not checked.
+func (s *taskService) ValidateReplace(ctx context.Context, id string, input ReplaceTaskInput) error {
+	task, err := s.getLive(ctx, id)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	return s.applyUpdate(ctx, task, s.replacement(input))
+}
 
-	// Filter by status if provided
-	if filter.Status != nil {
-		filtered := make([]*Task, 0, len(tasks))
-		for _, t := range tasks {
-			if t.Status == *filter.Status {
-				filtered = append(filtered, t)
-			}
-		}
-		tasks = filtered
+// replacement is the update that replaces every writable field with input.
+func (s *taskService) replacement(input ReplaceTaskInput) UpdateTaskInput {
+	status := s.workflow.Initial()
+	if input.Status != nil {
+		status = *input.Status
+	}
+	priority := PriorityMedium
+	if input.Priority != nil {
+		priority = *input.Priority
+	}
+	tags := input.Tags
+	if tags == nil {
+		tags = []string{}
 	}
 
-	// Sort by due date
-	sort.Slice(tasks, func(i, j int) bool {
-		return tasks[i].DueDate.Before(tasks[j].DueDate)
-	})
+	return UpdateTaskInput{
+		ParentID:        &input.ParentID,
+		Title:           &input.Title,
+		Description:     &input.Description,
+		Status:          &status,
+		Priority:        &priority,
+		Tags:            &tags,
+		DueDate:         &input.DueDate,
+		Recurrence:      &input.Recurrence,
+		ExpectedVersion: input.ExpectedVersion,
+	}
+}
 
-	// Apply pagination
-	page := filter.Page
-	if page <= 0 {
-		page = 1
+// DeleteTask moves a task to the trash.
+func (s *taskService) DeleteTask(ctx context.Context, id string) error {
+	task, err := s.getLive(ctx, id)
+	if err != nil {
+		return err
 	}
-	size := filter.PageSize
-	if size <= 0 {
-		size = 10
+
+	before := *task
+	now := time.Now().UTC()
+	task.DeletedAt = &now
+	task.UpdatedAt = now
+	if err := s.save(ctx, HistoryDeleted, before, task); err != nil {
+		return err
 	}
+	return s.detachChildren(ctx, task, now)
+}
+
+// ListTasks lists all tasks with optional filtering and pagination.
+func (s *taskService) ListTasks(ctx context.Context, filter TaskFilter) ([]*Task, error) {
+	page, err := s.QueryTasks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return page.Tasks, nil
+}
 
-	start := (page - 1) * size
-	if start >= len(tasks) {
-		return []*Task{}, nil // Empty result if page out of range
+// QueryTasks is ListTasks that also returns the total and the cursor for
+// the next page.
+func (s *taskService) QueryTasks(ctx context.Context, filter TaskFilter) (*TaskPage, error) {
+	filter, err := normalizeFilter(filter)
+	if err != nil {
+		return nil, err
 	}
 
-	end := start + size
-	if end > len(tasks) {
-		end = len(tasks)
+	filter.Trashed = false
+	return s.repo.Query(ctx, filter.WithDefaults())
+}
+
+// getLive retrieves a task that is not in the trash.
+func (s *taskService) getLive(ctx context.Context, id string) (*Task, error) {
+	task, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
 	}
+	if task.IsDeleted() {
+		return nil, pkgerrors.NewNotFoundError(CodeTaskNotFound, ErrTaskNotFound)
+	}
+	return task, nil
+}
+
+// fieldErrors collects invalid fields so they can be reported together.
+type fieldErrors []pkgerrors.FieldError
 
-	return tasks[start:end], nil
+// add records an invalid field.
+func (f *fieldErrors) add(field, code, msg string) {
+	*f = append(*f, pkgerrors.FieldError{Field: field, Code: code, Message: msg})
 }
 
-// isValidStatus checks if a status string is valid.
-func isValidStatus(s TaskStatus) bool {
-	switch s {
-	case StatusPending, StatusInProgress, StatusDone:
-		return true
-	default:
-		return false
+// check records err against field if it is a validation error and returns
+// any other error.
+func (f *fieldErrors) check(field string, err error) error {
+	var appErr *pkgerrors.AppError
+	if errors.As(err, &appErr) && appErr.Type == pkgerrors.ErrTypeValidation {
+		f.add(field, appErr.Code, appErr.Message)
+		return nil
 	}
+	return err
+}
+
+// err returns a validation error listing the invalid fields, or nil.
+func (f fieldErrors) err() error {
+	if len(f) == 0 {
+		return nil
+	}
+	return pkgerrors.NewFieldErrors(f...)
+}
+
+// isValidPriority checks if a priority is valid.
+func isValidPriority(p Priority) bool {
+	return p.Rank() > 0
 }
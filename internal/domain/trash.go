@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"context"
+	"log"
+	"time"
+
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+)
+
+// ListTrash lists tasks in the trash with optional filtering and pagination,
+// paged like QueryTasks.
+func (s *taskService) ListTrash(ctx context.Context, filter TaskFilter) (*TaskPage, error) {
+	filter, err := normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.Trashed = true
+	return s.repo.Query(ctx, filter.WithDefaults())
+}
+
+// RestoreTask moves a task out of the trash.
+func (s *taskService) RestoreTask(ctx context.Context, id string) (*Task, error) {
+	task, err := s.getTrashed(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *task
+
+	// A task whose parent is gone comes back as a top-level task
+	if task.ParentID != "" {
+		if _, err := s.getLive(ctx, task.ParentID); pkgerrors.IsNotFound(err) {
+			task.ParentID = ""
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	deletedAt := *task.DeletedAt
+	task.DeletedAt = nil
+	task.UpdatedAt = time.Now().UTC()
+	if err := s.save(ctx, HistoryRestored, before, task); err != nil {
+		return nil, err
+	}
+	if err := s.restoreChildren(ctx, task, deletedAt); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// PurgeTask permanently removes a task from the trash.
+func (s *taskService) PurgeTask(ctx context.Context, id string) error {
+	task, err := s.getTrashed(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.purge(ctx, task)
+}
+
+// PurgeTrash permanently removes every task trashed before deletedBefore and
+// returns how many were removed. Tasks restored, changed or purged since
+// they were listed are skipped.
+func (s *taskService) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int, error) {
+	tasks, err := s.repo.ListAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, t := range tasks {
+		if !t.IsDeleted() || !t.DeletedAt.Before(deletedBefore) {
+			continue
+		}
+		err := s.purge(ctx, t)
+		if pkgerrors.IsConflict(err) || pkgerrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// getTrashed retrieves a task that is in the trash.
+func (s *taskService) getTrashed(ctx context.Context, id string) (*Task, error) {
+	task, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !task.IsDeleted() {
+		return nil, pkgerrors.NewNotFoundError(CodeNotInTrash, ErrNotInTrash)
+	}
+	return task, nil
+}
+
+// RunTrashPurger purges tasks that have been in the trash longer than
+// retention, checking every interval until ctx is cancelled.
+func RunTrashPurger(ctx context.Context, svc TaskService, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := svc.PurgeTrash(WithActor(ctx, SystemActor), time.Now().Add(-retention))
+			if err != nil {
+				log.Printf("trash purge failed: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("purged %d tasks from trash", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+)
+
+// Guard checks that a task may complete a transition. It returns a short
+// reason when it may not.
+type Guard func(t *Task) error
+
+// guards are the guards that workflow configs may refer to by name.
+var guards = map[string]Guard{
+	"description_required": func(t *Task) error {
+		if strings.TrimSpace(t.Description) == "" {
+			return fmt.Errorf("description is required")
+		}
+		return nil
+	},
+	"tags_required": func(t *Task) error {
+		if len(t.Tags) == 0 {
+			return fmt.Errorf("at least one tag is required")
+		}
+		return nil
+	},
+}
+
+// WorkflowConfig is the serializable form of a Workflow.
+type WorkflowConfig struct {
+	Initial     TaskStatus         `json:"initial"`
+	States      []TaskStatus       `json:"states"`
+	Transitions []TransitionConfig `json:"transitions"`
+}
+
+// TransitionConfig allows moving from one state to another once every named
+// guard passes.
+type TransitionConfig struct {
+	From   TaskStatus `json:"from"`
+	To     TaskStatus `json:"to"`
+	Guards []string   `json:"guards,omitempty"`
+}
+
+// DefaultWorkflowConfig allows starting, finishing and pausing work, and
+// reopening finished tasks, but not moving DONE tasks back to PENDING.
+var DefaultWorkflowConfig = WorkflowConfig{
+	Initial: StatusPending,
+	States:  []TaskStatus{StatusPending, StatusInProgress, StatusDone},
+	Transitions: []TransitionConfig{
+		{From: StatusPending, To: StatusInProgress},
+		{From: StatusPending, To: StatusDone},
+		{From: StatusInProgress, To: StatusPending},
+		{From: StatusInProgress, To: StatusDone},
+		{From: StatusDone, To: StatusInProgress},
+	},
+}
+
+// Workflow is the set of states a task may be in and the allowed moves
+// between them.
+type Workflow struct {
+	initial     TaskStatus
+	states      []TaskStatus
+	transitions map[TaskStatus]map[TaskStatus][]string // from -> to -> guard names
+}
+
+// NewWorkflow builds a workflow from its config, rejecting unknown states,
+// unknown guards and duplicate transitions.
+func NewWorkflow(cfg WorkflowConfig) (*Workflow, error) {
+	w := &Workflow{
+		initial:     cfg.Initial,
+		transitions: make(map[TaskStatus]map[TaskStatus][]string),
+	}
+
+	for _, s := range cfg.States {
+		if s == "" || slices.Contains(w.states, s) {
+			return nil, fmt.Errorf("workflow: invalid or duplicate state %q", s)
+		}
+		w.states = append(w.states, s)
+	}
+	if !w.HasState(cfg.Initial) {
+		return nil, fmt.Errorf("workflow: initial state %q is not a state", cfg.Initial)
+	}
+
+	for _, t := range cfg.Transitions {
+		if !w.HasState(t.From) || !w.HasState(t.To) || t.From == t.To {
+			return nil, fmt.Errorf("workflow: invalid transition %s -> %s", t.From, t.To)
+		}
+		for _, g := range t.Guards {
+			if _, ok := guards[g]; !ok {
+				return nil, fmt.Errorf("workflow: unknown guard %q on %s -> %s", g, t.From, t.To)
+			}
+		}
+		to, ok := w.transitions[t.From]
+		if !ok {
+			to = make(map[TaskStatus][]string)
+			w.transitions[t.From] = to
+		}
+		if _, dup := to[t.To]; dup {
+			return nil, fmt.Errorf("workflow: duplicate transition %s -> %s", t.From, t.To)
+		}
+		to[t.To] = slices.Clone(t.Guards)
+	}
+
+	return w, nil
+}
+
+// LoadWorkflow reads a JSON workflow config from path.
+func LoadWorkflow(path string) (*Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg WorkflowConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("workflow: %w", err)
+	}
+	return NewWorkflow(cfg)
+}
+
+// DefaultWorkflow returns the workflow built from DefaultWorkflowConfig.
+func DefaultWorkflow() *Workflow {
+	w, err := NewWorkflow(DefaultWorkflowConfig)
+	if err != nil {
+		panic(err)
+	}
+	return w
+}
+
+// WithWorkflow sets the status workflow enforced by the service.
+func WithWorkflow(w *Workflow) ServiceOption {
+	return func(s *taskService) {
+		s.workflow = w
+	}
+}
+
+// Initial returns the state new tasks start in.
+func (w *Workflow) Initial() TaskStatus {
+	return w.initial
+}
+
+// States returns the states of the workflow in the order they were
+// configured.
+func (w *Workflow) States() []TaskStatus {
+	return slices.Clone(w.states)
+}
+
+// HasState reports whether s is a state of the workflow.
+func (w *Workflow) HasState(s TaskStatus) bool {
+	return slices.Contains(w.states, s)
+}
+
+// Check validates moving task from state from to its current status.
+// Staying in the same state is always allowed.
+func (w *Workflow) Check(from TaskStatus, task *Task) error {
+	to := task.Status
+	if from == to {
+		return nil
+	}
+
+	names, ok := w.transitions[from][to]
+	if !ok {
+		return transitionError(from, to, nil)
+	}
+	if err := checkGuards(names, task); err != nil {
+		return transitionError(from, to, err)
+	}
+	return nil
+}
+
+// CheckReachable validates creating task in its current status, which must
+// be reachable from the initial state through transitions whose guards the
+// task passes.
+func (w *Workflow) CheckReachable(task *Task) error {
+	to := task.Status
+	if to == w.initial {
+		return nil
+	}
+
+	// Breadth-first, visiting states in their configured order so a
+	// blocking guard is reported deterministically
+	var blocked error
+	seen := map[TaskStatus]bool{w.initial: true}
+	queue := []TaskStatus{w.initial}
+	for len(queue) > 0 {
+		from := queue[0]
+		queue = queue[1:]
+		for _, next := range w.states {
+			names, ok := w.transitions[from][next]
+			if !ok || seen[next] {
+				continue
+			}
+			if err := checkGuards(names, task); err != nil {
+				if next == to && blocked == nil {
+					blocked = err
+				}
+				continue
+			}
+			if next == to {
+				return nil
+			}
+			seen[next] = true
+			queue = append(queue, next)
+		}
+	}
+	return transitionError(w.initial, to, blocked)
+}
+
+// checkGuards runs the named guards against task, returning the first
+// reason it fails.
+func checkGuards(names []string, task *Task) error {
+	for _, name := range names {
+		if err := guards[name](task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transitionError reports a disallowed move, with the reason a guard gave
+// if any.
+func transitionError(from, to TaskStatus, reason error) error {
+	if reason != nil {
+		return pkgerrors.NewValidationError(CodeTransitionInvalid, fmt.Sprintf("%s from %s to %s: %v", ErrTransitionInvalid, from, to, reason))
+	}
+	return pkgerrors.NewValidationError(CodeTransitionInvalid, fmt.Sprintf("%s from %s to %s", ErrTransitionInvalid, from, to))
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	"github.com/google/uuid"
+)
+
+const fileHistoryName = "history.log"
+
+// FileHistoryRepository is a HistoryRepository that appends every entry to
+// a JSON-lines log and serves reads from an in-memory copy rebuilt at
+// startup. History is append-only, so the log is never compacted.
+type FileHistoryRepository struct {
+	mu      sync.Mutex // serializes writes to the log
+	mem     *InMemoryHistoryRepository
+	logFile *os.File
+}
+
+// NewFileHistoryRepository opens (or creates) the history log in dir and
+// replays it.
+func NewFileHistoryRepository(dir string) (*FileHistoryRepository, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	r := &FileHistoryRepository{mem: NewInMemoryHistoryRepository()}
+	path := filepath.Join(dir, fileHistoryName)
+	if err := r.replay(path); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	r.logFile = f
+
+	return r, nil
+}
+
+// Close closes the log file.
+func (r *FileHistoryRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.logFile.Close()
+}
+
+// Append writes entry to the log under a new ID.
+func (r *FileHistoryRepository) Append(ctx context.Context, entry *domain.HistoryEntry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *entry
+	stored.ID = uuid.NewString()
+	line, err := json.Marshal(&stored)
+	if err != nil {
+		return err
+	}
+	if _, err := r.logFile.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := r.logFile.Sync(); err != nil {
+		return err
+	}
+	r.mem.put(&stored)
+
+	entry.ID = stored.ID
+	return nil
+}
+
+// ListByTask returns a task's entries, oldest first.
+func (r *FileHistoryRepository) ListByTask(ctx context.Context, taskID string) ([]*domain.HistoryEntry, error) {
+	return r.mem.ListByTask(ctx, taskID)
+}
+
+// replay loads every entry from the log. As with the task log, a final line
+// without a newline is torn, even if it parses, and is truncated away so the
+// next append starts on a line of its own. Corruption anywhere else is an
+// error.
+func (r *FileHistoryRepository) replay(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	var offset int64
+	for lineNo := 1; ; lineNo++ {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr == io.EOF {
+			if len(line) > 0 {
+				return f.Truncate(offset)
+			}
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+
+		var entry domain.HistoryEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			if _, peekErr := reader.Peek(1); peekErr == io.EOF {
+				return f.Truncate(offset)
+			}
+			return fmt.Errorf("corrupt history log at line %d: %w", lineNo, err)
+		}
+		r.mem.put(&entry)
+
+		offset += int64(len(line))
+	}
+}
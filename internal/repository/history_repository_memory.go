@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	"github.com/google/uuid"
+)
+
+// InMemoryHistoryRepository is an in-memory implementation of
+// HistoryRepository.
+type InMemoryHistoryRepository struct {
+	mu      sync.RWMutex
+	entries map[string][]*domain.HistoryEntry // by task ID, oldest first
+}
+
+// NewInMemoryHistoryRepository creates a new in-memory history repository.
+func NewInMemoryHistoryRepository() *InMemoryHistoryRepository {
+	return &InMemoryHistoryRepository{entries: make(map[string][]*domain.HistoryEntry)}
+}
+
+// Append stores a copy of entry under a new ID.
+func (r *InMemoryHistoryRepository) Append(ctx context.Context, entry *domain.HistoryEntry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	entry.ID = uuid.NewString()
+	r.put(entry)
+	return nil
+}
+
+// ListByTask returns copies of a task's entries, oldest first.
+func (r *InMemoryHistoryRepository) ListByTask(ctx context.Context, taskID string) ([]*domain.HistoryEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]*domain.HistoryEntry, 0, len(r.entries[taskID]))
+	for _, e := range r.entries[taskID] {
+		copy := *e
+		copy.Changes = slices.Clone(e.Changes)
+		out = append(out, &copy)
+	}
+	return out, nil
+}
+
+// put stores a copy of an entry that already has an ID. It is used by
+// backends that replay persisted history.
+func (r *InMemoryHistoryRepository) put(entry *domain.HistoryEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copy := *entry
+	copy.Changes = slices.Clone(entry.Changes)
+	r.entries[entry.TaskID] = append(r.entries[entry.TaskID], &copy)
+}
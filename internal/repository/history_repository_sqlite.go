@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	"github.com/google/uuid"
+)
+
+// sqliteHistorySchema creates the history table. Entries are never updated;
+// rowid preserves append order.
+const sqliteHistorySchema = `
+CREATE TABLE IF NOT EXISTS task_history (
+	id      TEXT PRIMARY KEY,
+	task_id TEXT NOT NULL,
+	action  TEXT NOT NULL,
+	actor   TEXT NOT NULL,
+	at      TEXT NOT NULL,
+	changes TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_task_history_task_id ON task_history(task_id);
+`
+
+// SQLiteHistoryRepository is a SQLite-backed implementation of
+// HistoryRepository that shares the task database.
+type SQLiteHistoryRepository struct {
+	db *sql.DB
+}
+
+// History returns a history repository stored alongside the tasks, creating
+// its table if needed.
+func (r *SQLiteTaskRepository) History() (*SQLiteHistoryRepository, error) {
+	if _, err := r.db.Exec(sqliteHistorySchema); err != nil {
+		return nil, err
+	}
+	return &SQLiteHistoryRepository{db: r.db}, nil
+}
+
+// Append inserts entry under a new ID.
+func (r *SQLiteHistoryRepository) Append(ctx context.Context, entry *domain.HistoryEntry) error {
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return err
+	}
+
+	id := uuid.NewString()
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO task_history (id, task_id, action, actor, at, changes) VALUES (?, ?, ?, ?, ?, ?)`,
+		id, entry.TaskID, string(entry.Action), entry.Actor, formatTime(entry.At), string(changes),
+	)
+	if err != nil {
+		return err
+	}
+
+	entry.ID = id
+	return nil
+}
+
+// ListByTask returns a task's entries, oldest first.
+func (r *SQLiteHistoryRepository) ListByTask(ctx context.Context, taskID string) ([]*domain.HistoryEntry, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, task_id, action, actor, at, changes FROM task_history WHERE task_id = ? ORDER BY rowid`, taskID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]*domain.HistoryEntry, 0)
+	for rows.Next() {
+		var (
+			entry   domain.HistoryEntry
+			action  string
+			at      string
+			changes string
+		)
+		if err := rows.Scan(&entry.ID, &entry.TaskID, &action, &entry.Actor, &at, &changes); err != nil {
+			return nil, err
+		}
+		entry.Action = domain.HistoryAction(action)
+		if entry.At, err = parseTime(at); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(changes), &entry.Changes); err != nil {
+			return nil, err
+		}
+		out = append(out, &entry)
+	}
+
+	return out, rows.Err()
+}
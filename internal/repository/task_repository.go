@@ -6,4 +6,4 @@ import "github.com/gauravpandey771/task-api/internal/domain"
 // Real implementation is in task_repository_memory.go
 type TaskRepository interface {
 	domain.TaskRepository
-}
\ No newline at end of file
+}
@@ -0,0 +1,425 @@
+package repository
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/google/uuid"
+)
+
+const (
+	fileLogName      = "tasks.log"
+	fileSnapshotName = "tasks.snapshot.json"
+)
+
+// Log operations recorded by FileTaskRepository.
+const (
+	opCreate = "create"
+	opUpdate = "update"
+	opDelete = "delete"
+	opBatch  = "batch"
+)
+
+// logEntry is a single line of the append-only log. A batch holds the
+// entries of every write it made.
+type logEntry struct {
+	Op    string       `json:"op"`
+	ID    string       `json:"id,omitempty"`
+	Task  *domain.Task `json:"task,omitempty"`
+	Batch []logEntry   `json:"batch,omitempty"`
+}
+
+// FileTaskRepository is a TaskRepository that appends every write to a
+// JSON-lines log and serves reads from an in-memory copy rebuilt at startup.
+type FileTaskRepository struct {
+	mu      sync.Mutex // serializes writes to the log
+	mem     *InMemoryTaskRepository
+	dir     string
+	logFile *os.File
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewFileTaskRepository opens (or creates) a file repository in dir, replays
+// the snapshot and log, and compacts every compactInterval. A zero interval
+// disables background compaction.
+func NewFileTaskRepository(dir string, compactInterval time.Duration) (*FileTaskRepository, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	r := &FileTaskRepository{
+		mem: NewInMemoryTaskRepository(),
+		dir: dir,
+	}
+
+	if err := r.loadSnapshot(); err != nil {
+		return nil, err
+	}
+	if err := r.replayLog(); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(r.logPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	r.logFile = f
+
+	if compactInterval > 0 {
+		r.stop = make(chan struct{})
+		r.done = make(chan struct{})
+		go r.compactLoop(compactInterval)
+	}
+
+	return r, nil
+}
+
+// Close stops background compaction and closes the log file.
+func (r *FileTaskRepository) Close() error {
+	if r.stop != nil {
+		close(r.stop)
+		<-r.done
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.logFile.Close()
+}
+
+// Create adds a new task to the repository.
+func (r *FileTaskRepository) Create(ctx context.Context, task *domain.Task) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *task
+	stored.ID = uuid.NewString()
+	stored.Version = 1
+
+	if err := r.append(logEntry{Op: opCreate, Task: &stored}); err != nil {
+		return err
+	}
+	r.mem.put(&stored)
+
+	task.ID = stored.ID
+	task.Version = stored.Version
+	return nil
+}
+
+// GetByID retrieves a task by its ID.
+func (r *FileTaskRepository) GetByID(ctx context.Context, id string) (*domain.Task, error) {
+	return r.mem.GetByID(ctx, id)
+}
+
+// Update updates an existing task.
+func (r *FileTaskRepository) Update(ctx context.Context, task *domain.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, err := r.mem.GetByID(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+	if current.Version != task.Version {
+		return pkgerrors.NewConflictError(domain.CodeVersionConflict, domain.ErrVersionConflict)
+	}
+
+	stored := *task
+	stored.Version++
+	if err := r.append(logEntry{Op: opUpdate, Task: &stored}); err != nil {
+		return err
+	}
+	r.mem.put(&stored)
+
+	task.Version = stored.Version
+	return nil
+}
+
+// Delete removes a task from the repository.
+func (r *FileTaskRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.mem.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	if err := r.append(logEntry{Op: opDelete, ID: id}); err != nil {
+		return err
+	}
+	r.mem.remove(id)
+
+	return nil
+}
+
+// DeleteVersion removes a task if its version is current.
+func (r *FileTaskRepository) DeleteVersion(ctx context.Context, id string, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, err := r.mem.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if current.Version != version {
+		return pkgerrors.NewConflictError(domain.CodeVersionConflict, domain.ErrVersionConflict)
+	}
+
+	if err := r.append(logEntry{Op: opDelete, ID: id}); err != nil {
+		return err
+	}
+	r.mem.remove(id)
+
+	return nil
+}
+
+// Batch implements domain.TaskBatcher. The writes of a batch are applied to
+// the in-memory state, which is rolled back if fn fails, and logged as a
+// single line, so a crash mid-write loses the whole batch and never part of
+// it.
+func (r *FileTaskRepository) Batch(ctx context.Context, fn func(tx domain.TaskRepository) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.mem.Batch(ctx, func(tx domain.TaskRepository) error {
+		b := &fileBatch{TaskRepository: tx}
+		if err := fn(b); err != nil {
+			return err
+		}
+		if len(b.entries) == 0 {
+			return nil
+		}
+		return r.append(logEntry{Op: opBatch, Batch: b.entries})
+	})
+}
+
+// ListAll retrieves all tasks from the repository.
+func (r *FileTaskRepository) ListAll(ctx context.Context) ([]*domain.Task, error) {
+	return r.mem.ListAll(ctx)
+}
+
+// Query returns a single page of tasks matching the filter.
+func (r *FileTaskRepository) Query(ctx context.Context, filter domain.TaskFilter) (*domain.TaskPage, error) {
+	return r.mem.Query(ctx, filter)
+}
+
+// Compact writes the current state to a snapshot and truncates the log.
+func (r *FileTaskRepository) Compact() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tasks, err := r.mem.ListAll(context.Background())
+	if err != nil {
+		return err
+	}
+
+	tmp := r.snapshotPath() + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(tasks); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, r.snapshotPath()); err != nil {
+		return err
+	}
+
+	// A crash before the truncate below only means the log is replayed on
+	// top of a snapshot that already contains it, which is harmless.
+	if err := r.logFile.Truncate(0); err != nil {
+		return err
+	}
+	return r.logFile.Sync()
+}
+
+// append writes a single entry to the log and syncs it to disk.
+func (r *FileTaskRepository) append(entry logEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if _, err := r.logFile.Write(line); err != nil {
+		return err
+	}
+	return r.logFile.Sync()
+}
+
+// loadSnapshot seeds the in-memory state from the last snapshot, if any.
+func (r *FileTaskRepository) loadSnapshot() error {
+	data, err := os.ReadFile(r.snapshotPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var tasks []*domain.Task
+	if err := json.Unmarshal(data, &tasks); err != nil {
+		return fmt.Errorf("read snapshot: %w", err)
+	}
+	for _, t := range tasks {
+		r.mem.put(t)
+	}
+
+	return nil
+}
+
+// replayLog applies every log entry on top of the snapshot. A torn final line
+// left by a crash mid-write is truncated away; corruption anywhere else is an
+// error. Entries are written together with their newline, so a final line
+// without one is torn even if it parses, and is never acknowledged.
+func (r *FileTaskRepository) replayLog() error {
+	f, err := os.OpenFile(r.logPath(), os.O_RDWR, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	var offset int64
+	for lineNo := 1; ; lineNo++ {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr == io.EOF {
+			if len(line) > 0 {
+				return f.Truncate(offset)
+			}
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+
+		var entry logEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			if _, peekErr := reader.Peek(1); peekErr == io.EOF {
+				return f.Truncate(offset)
+			}
+			return fmt.Errorf("corrupt log at line %d: %w", lineNo, err)
+		}
+		if err := r.apply(entry); err != nil {
+			return fmt.Errorf("invalid log entry at line %d: %w", lineNo, err)
+		}
+
+		offset += int64(len(line))
+	}
+}
+
+// apply replays a single entry against the in-memory state.
+func (r *FileTaskRepository) apply(entry logEntry) error {
+	switch entry.Op {
+	case opCreate, opUpdate:
+		if entry.Task == nil || entry.Task.ID == "" {
+			return errors.New("missing task")
+		}
+		r.mem.put(entry.Task)
+	case opDelete:
+		r.mem.remove(entry.ID)
+	case opBatch:
+		for _, e := range entry.Batch {
+			if err := r.apply(e); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unknown op %q", entry.Op)
+	}
+	return nil
+}
+
+// fileBatch is the TaskRepository handed to a Batch function. It writes to
+// the in-memory batch and records the log entries of its writes.
+type fileBatch struct {
+	domain.TaskRepository
+	entries []logEntry
+}
+
+func (b *fileBatch) Create(ctx context.Context, task *domain.Task) error {
+	if err := b.TaskRepository.Create(ctx, task); err != nil {
+		return err
+	}
+	stored := *task
+	b.entries = append(b.entries, logEntry{Op: opCreate, Task: &stored})
+	return nil
+}
+
+func (b *fileBatch) Update(ctx context.Context, task *domain.Task) error {
+	if err := b.TaskRepository.Update(ctx, task); err != nil {
+		return err
+	}
+	stored := *task
+	b.entries = append(b.entries, logEntry{Op: opUpdate, Task: &stored})
+	return nil
+}
+
+func (b *fileBatch) Delete(ctx context.Context, id string) error {
+	if err := b.TaskRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	b.entries = append(b.entries, logEntry{Op: opDelete, ID: id})
+	return nil
+}
+
+func (b *fileBatch) DeleteVersion(ctx context.Context, id string, version int64) error {
+	if err := b.TaskRepository.DeleteVersion(ctx, id, version); err != nil {
+		return err
+	}
+	b.entries = append(b.entries, logEntry{Op: opDelete, ID: id})
+	return nil
+}
+
+// compactLoop compacts on a fixed interval until Close is called.
+func (r *FileTaskRepository) compactLoop(interval time.Duration) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			if err := r.Compact(); err != nil {
+				log.Printf("task log compaction failed: %v", err)
+			}
+		}
+	}
+}
+
+func (r *FileTaskRepository) logPath() string {
+	return filepath.Join(r.dir, fileLogName)
+}
+
+func (r *FileTaskRepository) snapshotPath() string {
+	return filepath.Join(r.dir, fileSnapshotName)
+}
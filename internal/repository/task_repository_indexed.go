@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	"github.com/gauravpandey771/task-api/internal/search"
+)
+
+// searchBoosts weights title matches above description matches.
+var searchBoosts = map[string]float64{"title": 2, "description": 1}
+
+// SearchableTaskRepository is a TaskRepository that also answers
+// full-text searches.
+type SearchableTaskRepository interface {
+	domain.TaskRepository
+	domain.TaskSearcher
+}
+
+// IndexedTaskRepository wraps a TaskRepository and keeps a full-text index
+// of its live tasks in step with every write. Trashed tasks are not
+// indexed.
+type IndexedTaskRepository struct {
+	domain.TaskRepository
+
+	mu    sync.Mutex // orders index updates like the writes they follow
+	index *search.Index
+}
+
+// NewIndexedTaskRepository wraps repo, indexing the tasks it already holds.
+// The result implements domain.TaskBatcher only when repo does, so callers
+// that fall back on plain writes without batches still do.
+func NewIndexedTaskRepository(ctx context.Context, repo domain.TaskRepository) (SearchableTaskRepository, error) {
+	r := &IndexedTaskRepository{TaskRepository: repo, index: search.NewIndex(searchBoosts)}
+
+	tasks, err := repo.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range tasks {
+		r.reindex(task)
+	}
+	if batcher, ok := repo.(domain.TaskBatcher); ok {
+		return &indexedBatcher{IndexedTaskRepository: r, batcher: batcher}, nil
+	}
+	return r, nil
+}
+
+// Create stores a task and indexes it.
+func (r *IndexedTaskRepository) Create(ctx context.Context, task *domain.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.TaskRepository.Create(ctx, task); err != nil {
+		return err
+	}
+	r.reindex(task)
+	return nil
+}
+
+// Update stores a task and reindexes it.
+func (r *IndexedTaskRepository) Update(ctx context.Context, task *domain.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.TaskRepository.Update(ctx, task); err != nil {
+		return err
+	}
+	r.reindex(task)
+	return nil
+}
+
+// Delete removes a task and drops it from the index.
+func (r *IndexedTaskRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.TaskRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.index.Remove(id)
+	return nil
+}
+
+// DeleteVersion removes a task if its version is current and drops it from
+// the index.
+func (r *IndexedTaskRepository) DeleteVersion(ctx context.Context, id string, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.TaskRepository.DeleteVersion(ctx, id, version); err != nil {
+		return err
+	}
+	r.index.Remove(id)
+	return nil
+}
+
+// indexedBatcher is the IndexedTaskRepository of a repository that
+// implements domain.TaskBatcher.
+type indexedBatcher struct {
+	*IndexedTaskRepository
+	batcher domain.TaskBatcher
+}
+
+// Batch implements domain.TaskBatcher, reindexing the tasks a batch wrote
+// once it has been applied.
+func (r *indexedBatcher) Batch(ctx context.Context, fn func(tx domain.TaskRepository) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	written := make(map[string]*domain.Task)
+	err := r.batcher.Batch(ctx, func(tx domain.TaskRepository) error {
+		return fn(&indexedBatch{TaskRepository: tx, written: written})
+	})
+	if err != nil {
+		return err
+	}
+	for id, task := range written {
+		if task == nil {
+			r.index.Remove(id)
+		} else {
+			r.reindex(task)
+		}
+	}
+	return nil
+}
+
+// Search implements domain.TaskSearcher.
+func (r *IndexedTaskRepository) Search(ctx context.Context, query string, limit int) ([]domain.SearchMatch, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	hits, total := r.index.Search(query, limit)
+	matches := make([]domain.SearchMatch, 0, len(hits))
+	for _, h := range hits {
+		matches = append(matches, domain.SearchMatch{TaskID: h.ID, Score: h.Score, Highlights: h.Highlights})
+	}
+	return matches, total, nil
+}
+
+// reindex indexes a live task or drops a trashed one.
+func (r *IndexedTaskRepository) reindex(task *domain.Task) {
+	if task.IsDeleted() {
+		r.index.Remove(task.ID)
+		return
+	}
+	r.index.Put(task.ID, map[string]string{"title": task.Title, "description": task.Description})
+}
+
+// indexedBatch records the last state of every task written in a batch,
+// nil for deleted tasks.
+type indexedBatch struct {
+	domain.TaskRepository
+	written map[string]*domain.Task
+}
+
+func (b *indexedBatch) Create(ctx context.Context, task *domain.Task) error {
+	if err := b.TaskRepository.Create(ctx, task); err != nil {
+		return err
+	}
+	copy := *task
+	b.written[task.ID] = &copy
+	return nil
+}
+
+func (b *indexedBatch) Update(ctx context.Context, task *domain.Task) error {
+	if err := b.TaskRepository.Update(ctx, task); err != nil {
+		return err
+	}
+	copy := *task
+	b.written[task.ID] = &copy
+	return nil
+}
+
+func (b *indexedBatch) Delete(ctx context.Context, id string) error {
+	if err := b.TaskRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	b.written[id] = nil
+	return nil
+}
+
+func (b *indexedBatch) DeleteVersion(ctx context.Context, id string, version int64) error {
+	if err := b.TaskRepository.DeleteVersion(ctx, id, version); err != nil {
+		return err
+	}
+	b.written[id] = nil
+	return nil
+}
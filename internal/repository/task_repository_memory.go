@@ -1,47 +1,173 @@
 package repository
 
 import (
+	"context"
+	"slices"
 	"sync"
 
-	"github.com/google/uuid"
 	"github.com/gauravpandey771/task-api/internal/domain"
 	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/google/uuid"
 )
 
 // InMemoryTaskRepository is an in-memory implementation of TaskRepository.
 type InMemoryTaskRepository struct {
 	mu    sync.RWMutex
 	tasks map[string]*domain.Task
+
+	// Secondary indexes for Query, all ordered by due date. byDue, byStatus
+	// and byRank hold live tasks only; trashed tasks are kept in trash.
+	// indexed records the state each task was indexed under, since callers
+	// may still hold the stored pointer and mutate it before calling Update.
+	indexed  map[string]indexedTask
+	byDue    *dueIndex
+	byStatus map[domain.TaskStatus]*dueIndex
+	byRank   map[int]*dueIndex
+	trash    *dueIndex
+}
+
+// indexedTask is the indexed state of a single task.
+type indexedTask struct {
+	status  domain.TaskStatus
+	rank    int
+	deleted bool
+	entry   dueEntry
 }
 
 // NewInMemoryTaskRepository creates a new in-memory repository.
 func NewInMemoryTaskRepository() *InMemoryTaskRepository {
 	return &InMemoryTaskRepository{
-		tasks: make(map[string]*domain.Task),
+		tasks:    make(map[string]*domain.Task),
+		indexed:  make(map[string]indexedTask),
+		byDue:    &dueIndex{},
+		byStatus: make(map[domain.TaskStatus]*dueIndex),
+		byRank:   make(map[int]*dueIndex),
+		trash:    &dueIndex{},
 	}
 }
 
 // Create adds a new task to the repository.
-func (r *InMemoryTaskRepository) Create(task *domain.Task) error {
+func (r *InMemoryTaskRepository) Create(ctx context.Context, task *domain.Task) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	// Generate UUID for the task
-	id := uuid.NewString()
-	task.ID = id
-	r.tasks[id] = task
-
+	r.create(task)
 	return nil
 }
 
 // GetByID retrieves a task by its ID.
-func (r *InMemoryTaskRepository) GetByID(id string) (*domain.Task, error) {
+func (r *InMemoryTaskRepository) GetByID(ctx context.Context, id string) (*domain.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	return r.get(id)
+}
+
+// Update updates an existing task.
+func (r *InMemoryTaskRepository) Update(ctx context.Context, task *domain.Task) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.update(task)
+}
+
+// Delete removes a task from the repository.
+func (r *InMemoryTaskRepository) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.delete(id)
+}
+
+// DeleteVersion removes a task if its version is current.
+func (r *InMemoryTaskRepository) DeleteVersion(ctx context.Context, id string, version int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.deleteVersion(id, version)
+}
+
+// ListAll retrieves all tasks from the repository.
+func (r *InMemoryTaskRepository) ListAll(ctx context.Context) ([]*domain.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.listAll(), nil
+}
+
+// Query returns a single page of tasks matching the filter. Orderings that
+// the indexes already hold are answered by walking them; anything else sorts
+// only the candidate tasks.
+func (r *InMemoryTaskRepository) Query(ctx context.Context, filter domain.TaskFilter) (*domain.TaskPage, error) {
+	filter = filter.WithDefaults()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.query(filter)
+}
+
+// Batch implements domain.TaskBatcher. It holds the write lock while fn
+// runs, so no other caller sees part of a batch, and undoes every write made
+// through tx when fn fails.
+func (r *InMemoryTaskRepository) Batch(ctx context.Context, fn func(tx domain.TaskRepository) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tx := &memoryBatch{repo: r, undo: make(map[string]*domain.Task)}
+	if err := fn(tx); err != nil {
+		tx.rollback()
+		return err
+	}
+	return nil
+}
+
+// create stores a new task under a fresh ID. Callers must hold mu.
+func (r *InMemoryTaskRepository) create(task *domain.Task) {
+	// Generate UUID for the task
+	id := uuid.NewString()
+	task.ID = id
+	task.Version = 1
+	r.tasks[id] = task
+	r.index(task)
+}
+
+// get returns a copy of a task. Callers must hold mu.
+func (r *InMemoryTaskRepository) get(id string) (*domain.Task, error) {
 	task, ok := r.tasks[id]
 	if !ok {
-		return nil, pkgerrors.NewNotFoundError("task not found")
+		return nil, pkgerrors.NewNotFoundError(domain.CodeTaskNotFound, domain.ErrTaskNotFound)
 	}
 
 	// Return a copy to prevent external mutation
@@ -49,45 +175,318 @@ func (r *InMemoryTaskRepository) GetByID(id string) (*domain.Task, error) {
 	return &copy, nil
 }
 
-// Update updates an existing task.
-func (r *InMemoryTaskRepository) Update(task *domain.Task) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	if _, ok := r.tasks[task.ID]; !ok {
-		return pkgerrors.NewNotFoundError("task not found")
+// update stores a copy of task if its version is current. Callers must hold
+// mu.
+func (r *InMemoryTaskRepository) update(task *domain.Task) error {
+	stored, ok := r.tasks[task.ID]
+	if !ok {
+		return pkgerrors.NewNotFoundError(domain.CodeTaskNotFound, domain.ErrTaskNotFound)
+	}
+	if stored.Version != task.Version {
+		return pkgerrors.NewConflictError(domain.CodeVersionConflict, domain.ErrVersionConflict)
 	}
+	task.Version++
 
 	// Store a copy
 	copy := *task
 	r.tasks[task.ID] = &copy
+	r.unindex(task.ID)
+	r.index(&copy)
 
 	return nil
 }
 
-// Delete removes a task from the repository.
-func (r *InMemoryTaskRepository) Delete(id string) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
+// delete removes a task. Callers must hold mu.
+func (r *InMemoryTaskRepository) delete(id string) error {
 	if _, ok := r.tasks[id]; !ok {
-		return pkgerrors.NewNotFoundError("task not found")
+		return pkgerrors.NewNotFoundError(domain.CodeTaskNotFound, domain.ErrTaskNotFound)
 	}
 
 	delete(r.tasks, id)
+	r.unindex(id)
 	return nil
 }
 
-// ListAll retrieves all tasks from the repository.
-func (r *InMemoryTaskRepository) ListAll() ([]*domain.Task, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+// deleteVersion removes a task if its version is current. Callers must hold
+// mu.
+func (r *InMemoryTaskRepository) deleteVersion(id string, version int64) error {
+	stored, ok := r.tasks[id]
+	if !ok {
+		return pkgerrors.NewNotFoundError(domain.CodeTaskNotFound, domain.ErrTaskNotFound)
+	}
+	if stored.Version != version {
+		return pkgerrors.NewConflictError(domain.CodeVersionConflict, domain.ErrVersionConflict)
+	}
+	return r.delete(id)
+}
 
+// listAll returns copies of every task. Callers must hold mu.
+func (r *InMemoryTaskRepository) listAll() []*domain.Task {
 	out := make([]*domain.Task, 0, len(r.tasks))
 	for _, t := range r.tasks {
 		copy := *t
 		out = append(out, &copy)
 	}
+	return out
+}
+
+// query answers Query. Callers must hold mu.
+func (r *InMemoryTaskRepository) query(filter domain.TaskFilter) (*domain.TaskPage, error) {
+	if segments, desc, ok := r.plan(filter); ok {
+		return r.walk(segments, filter, desc)
+	}
+
+	candidates := r.byDue
+	switch {
+	case filter.Trashed:
+		candidates = r.trash
+	case filter.Status != nil:
+		candidates = indexOrEmpty(r.byStatus, *filter.Status)
+	}
+
+	matched := make([]*domain.Task, 0, len(candidates.entries))
+	for _, e := range candidates.entries {
+		t := r.tasks[e.id]
+		if !filter.Matches(t) {
+			continue
+		}
+		copy := *t
+		matched = append(matched, &copy)
+	}
+	slices.SortFunc(matched, func(a, b *domain.Task) int {
+		return domain.CompareTasks(a, b, filter.Sort)
+	})
+
+	return paginate(matched, filter)
+}
+
+// plan picks indexes that, laid end to end, hold exactly the tasks matching
+// the filter in the requested order. desc reports whether each index must be
+// read back to front. Tags, parents and filter expressions are not indexed,
+// so filters on them always fall back.
+func (r *InMemoryTaskRepository) plan(filter domain.TaskFilter) (segments []*dueIndex, desc bool, ok bool) {
+	if len(filter.Tags) > 0 || filter.ParentID != "" || filter.Expr != nil {
+		return nil, false, false
+	}
+	ranks := priorityRanks(filter.Priorities)
+
+	switch {
+	case isDueDateSort(filter.Sort):
+		desc = filter.Sort[0].Desc
+		switch {
+		case filter.Trashed:
+			if filter.Status == nil && len(ranks) == 0 {
+				return []*dueIndex{r.trash}, desc, true
+			}
+		case filter.Status != nil:
+			if len(ranks) == 0 {
+				return []*dueIndex{indexOrEmpty(r.byStatus, *filter.Status)}, desc, true
+			}
+		case len(ranks) == 0:
+			return []*dueIndex{r.byDue}, desc, true
+		case len(ranks) == 1:
+			return []*dueIndex{indexOrEmpty(r.byRank, ranks[0])}, desc, true
+		}
+
+	case isPriorityDueSort(filter.Sort):
+		if filter.Trashed || filter.Status != nil {
+			return nil, false, false
+		}
+		if len(ranks) == 0 {
+			for rank := range r.byRank {
+				ranks = append(ranks, rank)
+			}
+		}
+		slices.Sort(ranks)
+		if filter.Sort[0].Desc {
+			slices.Reverse(ranks)
+		}
+		for _, rank := range ranks {
+			segments = append(segments, indexOrEmpty(r.byRank, rank))
+		}
+		return segments, filter.Sort[1].Desc, true
+	}
+
+	return nil, false, false
+}
+
+// walk reads a page from one or more due date indexes laid end to end,
+// without sorting.
+func (r *InMemoryTaskRepository) walk(segments []*dueIndex, filter domain.TaskFilter, desc bool) (*domain.TaskPage, error) {
+	total := 0
+	for _, seg := range segments {
+		total += len(seg.entries)
+	}
+
+	at := func(i int) dueEntry {
+		for _, seg := range segments {
+			n := len(seg.entries)
+			if i < n {
+				if desc {
+					return seg.entries[n-1-i]
+				}
+				return seg.entries[i]
+			}
+			i -= n
+		}
+		panic("index out of range")
+	}
 
-	return out, nil
-}
\ No newline at end of file
+	start, err := pageStart(filter, total, func(i int) *domain.Task { return r.tasks[at(i).id] })
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.TaskPage{Tasks: []*domain.Task{}, Total: total}
+	end := min(start+filter.PageSize, total)
+	for i := start; i < end; i++ {
+		copy := *r.tasks[at(i).id]
+		page.Tasks = append(page.Tasks, &copy)
+	}
+	if start < end && end < total {
+		page.NextCursor = domain.EncodeCursor(r.tasks[at(end-1).id], filter.Sort)
+	}
+
+	return page, nil
+}
+
+// index adds a task to the secondary indexes. Callers must hold mu.
+func (r *InMemoryTaskRepository) index(task *domain.Task) {
+	it := indexedTask{
+		status:  task.Status,
+		rank:    task.Priority.Rank(),
+		deleted: task.IsDeleted(),
+		entry:   dueEntry{due: task.DueDate, id: task.ID},
+	}
+	r.indexed[task.ID] = it
+	if it.deleted {
+		r.trash.insert(it.entry)
+		return
+	}
+	r.byDue.insert(it.entry)
+	indexOrCreate(r.byStatus, it.status).insert(it.entry)
+	indexOrCreate(r.byRank, it.rank).insert(it.entry)
+}
+
+// unindex removes a task from the secondary indexes. Callers must hold mu.
+func (r *InMemoryTaskRepository) unindex(id string) {
+	it, ok := r.indexed[id]
+	if !ok {
+		return
+	}
+	delete(r.indexed, id)
+	if it.deleted {
+		r.trash.remove(it.entry)
+		return
+	}
+	r.byDue.remove(it.entry)
+	indexOrEmpty(r.byStatus, it.status).remove(it.entry)
+	indexOrEmpty(r.byRank, it.rank).remove(it.entry)
+}
+
+// put stores a copy of task under its existing ID, replacing any previous
+// value. It is used by backends that replay persisted state.
+func (r *InMemoryTaskRepository) put(task *domain.Task) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copy := *task
+	r.tasks[task.ID] = &copy
+	r.unindex(task.ID)
+	r.index(&copy)
+}
+
+// remove deletes a task by ID if present.
+func (r *InMemoryTaskRepository) remove(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.tasks, id)
+	r.unindex(id)
+}
+
+// memoryBatch is the TaskRepository handed to a Batch function. It writes
+// straight to the repository, whose lock Batch already holds, and keeps the
+// state each task had before the batch so the writes can be undone.
+type memoryBatch struct {
+	repo *InMemoryTaskRepository
+	undo map[string]*domain.Task // nil for tasks created by the batch
+}
+
+func (b *memoryBatch) Create(ctx context.Context, task *domain.Task) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.repo.create(task)
+	b.undo[task.ID] = nil
+	return nil
+}
+
+func (b *memoryBatch) GetByID(ctx context.Context, id string) (*domain.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return b.repo.get(id)
+}
+
+func (b *memoryBatch) Update(ctx context.Context, task *domain.Task) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.remember(task.ID)
+	return b.repo.update(task)
+}
+
+func (b *memoryBatch) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.remember(id)
+	return b.repo.delete(id)
+}
+
+func (b *memoryBatch) DeleteVersion(ctx context.Context, id string, version int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.remember(id)
+	return b.repo.deleteVersion(id, version)
+}
+
+func (b *memoryBatch) ListAll(ctx context.Context) ([]*domain.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return b.repo.listAll(), nil
+}
+
+func (b *memoryBatch) Query(ctx context.Context, filter domain.TaskFilter) (*domain.TaskPage, error) {
+	filter = filter.WithDefaults()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return b.repo.query(filter)
+}
+
+// remember records the state of a task before the batch first changes it.
+func (b *memoryBatch) remember(id string) {
+	if _, ok := b.undo[id]; ok {
+		return
+	}
+	if stored, ok := b.repo.tasks[id]; ok {
+		copy := *stored
+		b.undo[id] = &copy
+	}
+}
+
+// rollback restores every task the batch changed.
+func (b *memoryBatch) rollback() {
+	for id, before := range b.undo {
+		delete(b.repo.tasks, id)
+		b.repo.unindex(id)
+		if before != nil {
+			b.repo.tasks[id] = before
+			b.repo.index(before)
+		}
+	}
+}
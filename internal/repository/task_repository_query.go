@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+)
+
+// dueEntry is a single position in a dueIndex.
+type dueEntry struct {
+	due time.Time
+	id  string
+}
+
+// less orders entries by due date, then ID, matching domain.CompareTasks.
+func (e dueEntry) less(o dueEntry) bool {
+	if c := e.due.Compare(o.due); c != 0 {
+		return c < 0
+	}
+	return strings.Compare(e.id, o.id) < 0
+}
+
+// dueIndex keeps task IDs ordered by due date.
+type dueIndex struct {
+	entries []dueEntry
+}
+
+// search returns the position where e is or would be inserted.
+func (x *dueIndex) search(e dueEntry) int {
+	return sort.Search(len(x.entries), func(i int) bool {
+		return !x.entries[i].less(e)
+	})
+}
+
+// insert adds e keeping the index ordered.
+func (x *dueIndex) insert(e dueEntry) {
+	i := x.search(e)
+	x.entries = slices.Insert(x.entries, i, e)
+}
+
+// remove deletes e if present.
+func (x *dueIndex) remove(e dueEntry) {
+	i := x.search(e)
+	if i < len(x.entries) && x.entries[i] == e {
+		x.entries = slices.Delete(x.entries, i, i+1)
+	}
+}
+
+// position returns the index of e, or -1 if it is not present.
+func (x *dueIndex) position(e dueEntry) int {
+	i := x.search(e)
+	if i < len(x.entries) && x.entries[i] == e {
+		return i
+	}
+	return -1
+}
+
+// paginate returns one page of an already sorted, already filtered slice.
+func paginate(sorted []*domain.Task, filter domain.TaskFilter) (*domain.TaskPage, error) {
+	start, err := pageStart(filter, len(sorted), func(i int) *domain.Task { return sorted[i] })
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.TaskPage{Tasks: []*domain.Task{}, Total: len(sorted)}
+	if start >= len(sorted) {
+		return page, nil
+	}
+
+	end := min(start+filter.PageSize, len(sorted))
+	page.Tasks = sorted[start:end]
+	if end < len(sorted) {
+		page.NextCursor = domain.EncodeCursor(sorted[end-1], filter.Sort)
+	}
+	return page, nil
+}
+
+// pageStart resolves the offset of the first result of a page among n
+// ordered results, where at returns the i-th result. A cursor resolves to
+// the first result sorting after the position it encodes.
+func pageStart(filter domain.TaskFilter, n int, at func(i int) *domain.Task) (int, error) {
+	if filter.Cursor == "" {
+		return (filter.Page - 1) * filter.PageSize, nil
+	}
+
+	after, err := domain.DecodeCursor(filter.Cursor, filter.Sort)
+	if err != nil {
+		return 0, err
+	}
+	return sort.Search(n, func(i int) bool {
+		return domain.CompareTasks(at(i), after, filter.Sort) > 0
+	}), nil
+}
+
+// indexOrEmpty returns the index stored under key, or an empty one.
+func indexOrEmpty[K comparable](m map[K]*dueIndex, key K) *dueIndex {
+	if idx, ok := m[key]; ok {
+		return idx
+	}
+	return &dueIndex{}
+}
+
+// indexOrCreate returns the index stored under key, creating it if needed.
+func indexOrCreate[K comparable](m map[K]*dueIndex, key K) *dueIndex {
+	idx, ok := m[key]
+	if !ok {
+		idx = &dueIndex{}
+		m[key] = idx
+	}
+	return idx
+}
+
+// priorityRanks returns the distinct ranks of the given priorities.
+func priorityRanks(priorities []domain.Priority) []int {
+	ranks := make([]int, 0, len(priorities))
+	for _, p := range priorities {
+		if rank := p.Rank(); !slices.Contains(ranks, rank) {
+			ranks = append(ranks, rank)
+		}
+	}
+	return ranks
+}
+
+// isPriorityDueSort reports whether keys orders by priority and then due
+// date, which can be answered by walking the per-priority indexes in turn.
+func isPriorityDueSort(keys []domain.SortKey) bool {
+	return len(keys) == 2 && keys[0].Field == domain.SortByPriority && keys[1].Field == domain.SortByDueDate
+}
+
+// isDueDateSort reports whether keys is a single due date ordering, which
+// can be answered by walking a dueIndex.
+func isDueDateSort(keys []domain.SortKey) bool {
+	return len(keys) == 1 && keys[0].Field == domain.SortByDueDate
+}
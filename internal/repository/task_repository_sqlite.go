@@ -0,0 +1,675 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteSchema creates the tasks table if it does not exist yet.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS tasks (
+	id          TEXT PRIMARY KEY,
+	title       TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	status      TEXT NOT NULL,
+	due_date    TEXT NOT NULL,
+	version     INTEGER NOT NULL DEFAULT 1,
+	deleted_at  TEXT,
+	created_at  TEXT NOT NULL DEFAULT '',
+	updated_at  TEXT NOT NULL DEFAULT '',
+	priority    TEXT NOT NULL DEFAULT 'MEDIUM',
+	tags        TEXT NOT NULL DEFAULT '',
+	parent_id   TEXT NOT NULL DEFAULT '',
+	blocked_by  TEXT NOT NULL DEFAULT '',
+	recurrence  TEXT NOT NULL DEFAULT ''
+);
+`
+
+// sqliteIndexes is applied after migrateSQLite so it may reference added
+// columns.
+const sqliteIndexes = `
+CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
+CREATE INDEX IF NOT EXISTS idx_tasks_due_date ON tasks(due_date);
+CREATE INDEX IF NOT EXISTS idx_tasks_deleted_at ON tasks(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_updated_at ON tasks(updated_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_priority_due ON tasks(priority, due_date);
+CREATE INDEX IF NOT EXISTS idx_tasks_parent_id ON tasks(parent_id);
+`
+
+// sqliteAddedColumns lists columns added after the initial schema, so that
+// databases created by older versions are upgraded in place on startup.
+var sqliteAddedColumns = []struct{ name, ddl string }{
+	{"version", "INTEGER NOT NULL DEFAULT 1"},
+	{"deleted_at", "TEXT"},
+	{"created_at", "TEXT NOT NULL DEFAULT ''"},
+	{"updated_at", "TEXT NOT NULL DEFAULT ''"},
+	{"priority", "TEXT NOT NULL DEFAULT 'MEDIUM'"},
+	{"tags", "TEXT NOT NULL DEFAULT ''"},
+	{"parent_id", "TEXT NOT NULL DEFAULT ''"},
+	{"blocked_by", "TEXT NOT NULL DEFAULT ''"},
+	{"recurrence", "TEXT NOT NULL DEFAULT ''"},
+}
+
+// sqliteTaskColumns is the column list read by scanTask.
+const sqliteTaskColumns = "id, version, title, description, status, due_date, deleted_at, created_at, updated_at, priority, tags, parent_id, blocked_by, recurrence"
+
+// sqliteWritable lists the columns written on insert and update, in the
+// order returned by sqliteValues.
+var sqliteWritable = []string{
+	"title", "description", "status", "due_date", "deleted_at", "created_at", "updated_at",
+	"priority", "tags", "parent_id", "blocked_by", "recurrence",
+}
+
+// sqliteValues returns the values of the sqliteWritable columns for a task.
+func sqliteValues(t *domain.Task) []any {
+	return []any{
+		t.Title,
+		t.Description,
+		string(t.Status),
+		formatTime(t.DueDate),
+		formatNullTime(t.DeletedAt),
+		formatTime(t.CreatedAt),
+		formatTime(t.UpdatedAt),
+		string(t.Priority),
+		formatList(t.Tags),
+		t.ParentID,
+		formatList(t.BlockedBy),
+		t.Recurrence,
+	}
+}
+
+// SQLiteTaskRepository is a SQLite-backed implementation of TaskRepository.
+type SQLiteTaskRepository struct {
+	db   *sql.DB
+	conn sqlConn // db, or the transaction of a batch
+}
+
+// sqlConn is the part of *sql.DB and *sql.Tx the repository queries
+// through.
+type sqlConn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// NewSQLiteTaskRepository opens the SQLite database at path and creates the
+// schema if needed.
+func NewSQLiteTaskRepository(path string) (*SQLiteTaskRepository, error) {
+	db, err := sql.Open("sqlite3", path+"?_foreign_keys=on&_busy_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite serializes writers; a single connection avoids SQLITE_BUSY
+	// and keeps ":memory:" databases shared across calls.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := migrateSQLite(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if _, err := db.Exec(sqliteIndexes); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteTaskRepository{db: db, conn: db}, nil
+}
+
+// migrateSQLite adds any columns missing from an existing tasks table.
+func migrateSQLite(db *sql.DB) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info('tasks')`)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, col := range sqliteAddedColumns {
+		if existing[col.name] {
+			continue
+		}
+		if _, err := db.Exec(fmt.Sprintf("ALTER TABLE tasks ADD COLUMN %s %s", col.name, col.ddl)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the underlying database.
+func (r *SQLiteTaskRepository) Close() error {
+	return r.db.Close()
+}
+
+// Create adds a new task to the repository.
+func (r *SQLiteTaskRepository) Create(ctx context.Context, task *domain.Task) error {
+	id := uuid.NewString()
+
+	query := fmt.Sprintf(
+		"INSERT INTO tasks (id, version, %s) VALUES (?, 1%s)",
+		strings.Join(sqliteWritable, ", "), strings.Repeat(", ?", len(sqliteWritable)),
+	)
+	_, err := r.conn.ExecContext(ctx, query, append([]any{id}, sqliteValues(task)...)...)
+	if err != nil {
+		return err
+	}
+
+	task.ID = id
+	task.Version = 1
+	return nil
+}
+
+// GetByID retrieves a task by its ID.
+func (r *SQLiteTaskRepository) GetByID(ctx context.Context, id string) (*domain.Task, error) {
+	row := r.conn.QueryRowContext(ctx,
+		`SELECT `+sqliteTaskColumns+` FROM tasks WHERE id = ?`, id,
+	)
+
+	task, err := scanTask(row)
+	if err == sql.ErrNoRows {
+		return nil, pkgerrors.NewNotFoundError(domain.CodeTaskNotFound, domain.ErrTaskNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// Update updates an existing task.
+func (r *SQLiteTaskRepository) Update(ctx context.Context, task *domain.Task) error {
+	query := fmt.Sprintf(
+		"UPDATE tasks SET %s = ?, version = version + 1 WHERE id = ? AND version = ?",
+		strings.Join(sqliteWritable, " = ?, "),
+	)
+	res, err := r.conn.ExecContext(ctx, query, append(sqliteValues(task), task.ID, task.Version)...)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		// Distinguish a missing task from a stale version
+		if _, err := r.GetByID(ctx, task.ID); err != nil {
+			return err
+		}
+		return pkgerrors.NewConflictError(domain.CodeVersionConflict, domain.ErrVersionConflict)
+	}
+
+	task.Version++
+	return nil
+}
+
+// Delete removes a task from the repository.
+func (r *SQLiteTaskRepository) Delete(ctx context.Context, id string) error {
+	res, err := r.conn.ExecContext(ctx, `DELETE FROM tasks WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(res)
+}
+
+// DeleteVersion removes a task if its version is current.
+func (r *SQLiteTaskRepository) DeleteVersion(ctx context.Context, id string, version int64) error {
+	res, err := r.conn.ExecContext(ctx, `DELETE FROM tasks WHERE id = ? AND version = ?`, id, version)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		// Distinguish a missing task from a stale version
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return pkgerrors.NewConflictError(domain.CodeVersionConflict, domain.ErrVersionConflict)
+	}
+	return nil
+}
+
+// Batch implements domain.TaskBatcher with a transaction. The database has
+// a single connection, so other callers wait until the batch commits or
+// rolls back.
+func (r *SQLiteTaskRepository) Batch(ctx context.Context, fn func(tx domain.TaskRepository) error) error {
+	if _, ok := r.conn.(*sql.Tx); ok {
+		return fn(r) // already in a batch
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(&SQLiteTaskRepository{db: r.db, conn: tx}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListAll retrieves all tasks from the repository.
+func (r *SQLiteTaskRepository) ListAll(ctx context.Context) ([]*domain.Task, error) {
+	rows, err := r.conn.QueryContext(ctx, `SELECT `+sqliteTaskColumns+` FROM tasks`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]*domain.Task, 0)
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, task)
+	}
+
+	return out, rows.Err()
+}
+
+// Query returns a single page of tasks matching the filter, using keyset
+// pagination when a cursor is given.
+func (r *SQLiteTaskRepository) Query(ctx context.Context, filter domain.TaskFilter) (*domain.TaskPage, error) {
+	filter = filter.WithDefaults()
+
+	var (
+		where []string
+		args  []any
+	)
+	if filter.Trashed {
+		where = append(where, "deleted_at IS NOT NULL")
+	} else {
+		where = append(where, "deleted_at IS NULL")
+	}
+	if filter.ParentID != "" {
+		where = append(where, "parent_id = ?")
+		args = append(args, filter.ParentID)
+	}
+	if filter.Status != nil {
+		where = append(where, "status = ?")
+		args = append(args, string(*filter.Status))
+	}
+	if len(filter.Priorities) > 0 {
+		where = append(where, "priority IN (?"+strings.Repeat(", ?", len(filter.Priorities)-1)+")")
+		for _, p := range filter.Priorities {
+			args = append(args, string(p))
+		}
+	}
+	if len(filter.Tags) > 0 {
+		conds := make([]string, 0, len(filter.Tags))
+		for _, tag := range filter.Tags {
+			conds = append(conds, "instr(tags, ?) > 0")
+			args = append(args, formatList([]string{tag}))
+		}
+		join := " OR "
+		if filter.TagMatch == domain.TagMatchAll {
+			join = " AND "
+		}
+		where = append(where, "("+strings.Join(conds, join)+")")
+	}
+	if filter.Expr != nil {
+		cond, condArgs := sqliteFilter(filter.Expr)
+		where = append(where, cond)
+		args = append(args, condArgs...)
+	}
+
+	page := &domain.TaskPage{Tasks: []*domain.Task{}}
+	countSQL := "SELECT COUNT(*) FROM tasks" + whereClause(where)
+	if err := r.conn.QueryRowContext(ctx, countSQL, args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	offset := (filter.Page - 1) * filter.PageSize
+	if filter.Cursor != "" {
+		after, err := domain.DecodeCursor(filter.Cursor, filter.Sort)
+		if err != nil {
+			return nil, err
+		}
+		cond, condArgs := keysetCondition(filter.Sort, after)
+		where = append(where, cond)
+		args = append(args, condArgs...)
+		offset = 0
+	}
+
+	querySQL := "SELECT " + sqliteTaskColumns + " FROM tasks" +
+		whereClause(where) + orderClause(filter.Sort) + " LIMIT ? OFFSET ?"
+	rows, err := r.conn.QueryContext(ctx, querySQL, append(args, filter.PageSize+1, offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		page.Tasks = append(page.Tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// One extra row was fetched to detect whether another page follows.
+	if len(page.Tasks) > filter.PageSize {
+		page.Tasks = page.Tasks[:filter.PageSize]
+		page.NextCursor = domain.EncodeCursor(page.Tasks[len(page.Tasks)-1], filter.Sort)
+	}
+
+	return page, nil
+}
+
+// sqlitePriorityRank orders the priority column like domain.Priority.Rank.
+const sqlitePriorityRank = `(CASE priority WHEN 'LOW' THEN 1 WHEN 'MEDIUM' THEN 2 WHEN 'HIGH' THEN 3 WHEN 'URGENT' THEN 4 ELSE 0 END)`
+
+// sqliteColumn maps a sort field to its column or expression.
+func sqliteColumn(f domain.SortField) string {
+	switch f {
+	case domain.SortByTitle:
+		return "title"
+	case domain.SortByCreatedAt:
+		return "created_at"
+	case domain.SortByUpdatedAt:
+		return "updated_at"
+	case domain.SortByPriority:
+		return sqlitePriorityRank
+	default:
+		return "due_date"
+	}
+}
+
+// sqliteSortValue returns the stored value of a sort field for a task.
+func sqliteSortValue(t *domain.Task, f domain.SortField) any {
+	switch f {
+	case domain.SortByTitle:
+		return t.Title
+	case domain.SortByCreatedAt:
+		return formatTime(t.CreatedAt)
+	case domain.SortByUpdatedAt:
+		return formatTime(t.UpdatedAt)
+	case domain.SortByPriority:
+		return t.Priority.Rank()
+	default:
+		return formatTime(t.DueDate)
+	}
+}
+
+// orderClause mirrors domain.CompareTasks, including the ID tie-break.
+func orderClause(keys []domain.SortKey) string {
+	parts := make([]string, 0, len(keys)+1)
+	for _, k := range keys {
+		parts = append(parts, sqliteColumn(k.Field)+direction(k.Desc))
+	}
+	parts = append(parts, "id"+direction(keys[len(keys)-1].Desc))
+	return " ORDER BY " + strings.Join(parts, ", ")
+}
+
+// sqliteFilterColumns maps filter fields to columns.
+var sqliteFilterColumns = map[domain.FilterField]string{
+	domain.FilterStatus:      "status",
+	domain.FilterPriority:    "priority",
+	domain.FilterTitle:       "title",
+	domain.FilterDescription: "description",
+	domain.FilterTags:        "tags",
+	domain.FilterParentID:    "parent_id",
+	domain.FilterDueDate:     "due_date",
+	domain.FilterCreatedAt:   "created_at",
+	domain.FilterUpdatedAt:   "updated_at",
+}
+
+// sqliteFilter translates a filter expression into a predicate with the
+// same meaning as its in-memory evaluation. Substring matches use SQLite's
+// lower(), which only folds ASCII letters.
+func sqliteFilter(expr domain.FilterExpr) (string, []any) {
+	switch e := expr.(type) {
+	case *domain.FilterAnd:
+		return sqliteFilterTerms(e.Terms, " AND ")
+	case *domain.FilterOr:
+		return sqliteFilterTerms(e.Terms, " OR ")
+	case *domain.FilterNot:
+		cond, args := sqliteFilter(e.Expr)
+		return "NOT " + cond, args
+	case *domain.FilterComparison:
+		if e.Op == domain.OpIn {
+			terms := make([]domain.FilterExpr, 0, len(e.Values))
+			for _, v := range e.Values {
+				terms = append(terms, &domain.FilterComparison{Field: e.Field, Op: domain.OpEq, Values: []any{v}})
+			}
+			return sqliteFilterTerms(terms, " OR ")
+		}
+		return sqliteComparison(e.Field, e.Op, e.Values[0])
+	default:
+		panic(fmt.Sprintf("unknown filter expression %T", expr))
+	}
+}
+
+// sqliteFilterTerms joins the predicates of several terms.
+func sqliteFilterTerms(terms []domain.FilterExpr, join string) (string, []any) {
+	conds := make([]string, 0, len(terms))
+	var args []any
+	for _, term := range terms {
+		cond, termArgs := sqliteFilter(term)
+		conds = append(conds, cond)
+		args = append(args, termArgs...)
+	}
+	return "(" + strings.Join(conds, join) + ")", args
+}
+
+// sqliteComparison translates a single-valued comparison.
+func sqliteComparison(field domain.FilterField, op domain.FilterOp, value any) (string, []any) {
+	col := sqliteFilterColumns[field]
+	switch v := value.(type) {
+	case time.Time:
+		return fmt.Sprintf("(%s %s ?)", col, op), []any{formatTime(v)}
+	case domain.Priority:
+		if op == domain.OpEq || op == domain.OpNe {
+			return fmt.Sprintf("(%s %s ?)", col, op), []any{string(v)}
+		}
+		return fmt.Sprintf("(%s %s ?)", sqlitePriorityRank, op), []any{v.Rank()}
+	}
+
+	v := value.(string)
+	switch {
+	case field == domain.FilterTags && op == domain.OpEq:
+		return "(instr(tags, ?) > 0)", []any{formatList([]string{v})}
+	case field == domain.FilterTags:
+		return "(instr(tags, ?) = 0)", []any{formatList([]string{v})}
+	case op == domain.OpContains:
+		return fmt.Sprintf("(instr(lower(%s), lower(?)) > 0)", col), []any{v}
+	default:
+		return fmt.Sprintf("(%s %s ?)", col, op), []any{v}
+	}
+}
+
+// keysetCondition builds a predicate selecting rows strictly after the
+// cursor task in the given order.
+func keysetCondition(keys []domain.SortKey, after *domain.Task) (string, []any) {
+	type col struct {
+		name  string
+		desc  bool
+		value any
+	}
+	cols := make([]col, 0, len(keys)+1)
+	for _, k := range keys {
+		cols = append(cols, col{sqliteColumn(k.Field), k.Desc, sqliteSortValue(after, k.Field)})
+	}
+	cols = append(cols, col{"id", keys[len(keys)-1].Desc, after.ID})
+
+	var (
+		ors  []string
+		args []any
+	)
+	for i, c := range cols {
+		ands := make([]string, 0, i+1)
+		for _, prev := range cols[:i] {
+			ands = append(ands, prev.name+" = ?")
+			args = append(args, prev.value)
+		}
+		op := ">"
+		if c.desc {
+			op = "<"
+		}
+		ands = append(ands, fmt.Sprintf("%s %s ?", c.name, op))
+		args = append(args, c.value)
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+
+	return "(" + strings.Join(ors, " OR ") + ")", args
+}
+
+func direction(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+	return " ASC"
+}
+
+func whereClause(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conds, " AND ")
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanTask reads a single task row.
+func scanTask(s rowScanner) (*domain.Task, error) {
+	var (
+		task     domain.Task
+		status   string
+		due      string
+		deleted  sql.NullString
+		created  string
+		updated  string
+		priority string
+		tags     string
+		blocked  string
+	)
+	err := s.Scan(
+		&task.ID, &task.Version, &task.Title, &task.Description, &status, &due,
+		&deleted, &created, &updated, &priority, &tags, &task.ParentID, &blocked,
+		&task.Recurrence,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	task.Status = domain.TaskStatus(status)
+	task.Priority = domain.Priority(priority)
+	task.Tags = parseList(tags)
+	task.BlockedBy = parseList(blocked)
+	if task.DueDate, err = parseTime(due); err != nil {
+		return nil, err
+	}
+	if task.DeletedAt, err = parseNullTime(deleted); err != nil {
+		return nil, err
+	}
+	if task.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	if task.UpdatedAt, err = parseTime(updated); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// requireAffected maps a write that touched no rows to a not found error.
+func requireAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return pkgerrors.NewNotFoundError(domain.CodeTaskNotFound, domain.ErrTaskNotFound)
+	}
+	return nil
+}
+
+// formatList stores a list of tags or IDs as ",a,b," so a single value can
+// be matched with instr(col, ",a,"). Stored values never contain commas.
+func formatList(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return "," + strings.Join(values, ",") + ","
+}
+
+// parseList parses a list stored by formatList.
+func parseList(s string) []string {
+	s = strings.Trim(s, ",")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// sqliteTimeLayout is a fixed-width UTC RFC3339 layout so stored times sort
+// lexically in SQL.
+const sqliteTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// formatTime formats t for storage.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeLayout)
+}
+
+// parseTime parses a stored time. Rows written before a column existed hold
+// an empty string, which maps to the zero time.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(sqliteTimeLayout, s)
+}
+
+// formatNullTime formats an optional time for storage.
+func formatNullTime(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: formatTime(*t), Valid: true}
+}
+
+// parseNullTime parses an optional stored time.
+func parseNullTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	t, err := parseTime(s.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,286 @@
+// Package search implements an in-process full-text index with English
+// stemming, prefix matching, BM25 ranking and highlighted snippets.
+package search
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// BM25 parameters.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// prefixWeight discounts index terms matched only by prefix.
+const prefixWeight = 0.5
+
+// minPrefixLength is the shortest query term expanded by prefix.
+const minPrefixLength = 2
+
+// Hit is a document matching a query. Highlights holds a snippet of each
+// matching field with matched words wrapped in <mark> tags.
+type Hit struct {
+	ID         string
+	Score      float64
+	Highlights map[string]string
+}
+
+// document is an indexed document.
+type document struct {
+	fields  map[string]string
+	lengths map[string]int // tokens per field
+	terms   []string       // distinct terms, for removal
+	words   []string       // distinct unstemmed words, for removal
+}
+
+// Index is an inverted index of documents with named text fields. It is safe
+// for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	boosts   map[string]float64
+	docs     map[string]*document
+	postings map[string]map[string]map[string]int // term -> doc ID -> field -> frequency
+	vocab    []string                             // sorted terms, for prefix lookups
+	words    []string                             // sorted unstemmed words, for prefix lookups
+	stems    map[string]string                    // unstemmed word -> term
+	wordDocs map[string]int                       // unstemmed word -> documents containing it
+	totals   map[string]int                       // tokens per field across all documents
+}
+
+// NewIndex creates an empty index. boosts weights matches per field; fields
+// not listed have weight 1.
+func NewIndex(boosts map[string]float64) *Index {
+	return &Index{
+		boosts:   boosts,
+		docs:     make(map[string]*document),
+		postings: make(map[string]map[string]map[string]int),
+		stems:    make(map[string]string),
+		wordDocs: make(map[string]int),
+		totals:   make(map[string]int),
+	}
+}
+
+// Len returns the number of indexed documents.
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.docs)
+}
+
+// Put indexes a document, replacing any earlier version with the same ID.
+func (x *Index) Put(id string, fields map[string]string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.remove(id)
+
+	doc := &document{fields: make(map[string]string, len(fields)), lengths: make(map[string]int, len(fields))}
+	seen := make(map[string]bool)
+	for name, text := range fields {
+		doc.fields[name] = text
+		for _, tok := range tokenize(text) {
+			if isStopWord(tok.word) {
+				continue
+			}
+			term := Stem(tok.word)
+			if !seen[tok.word] {
+				seen[tok.word] = true
+				doc.words = append(doc.words, tok.word)
+				x.addWord(tok.word, term)
+			}
+			byDoc, ok := x.postings[term]
+			if !ok {
+				byDoc = make(map[string]map[string]int)
+				x.postings[term] = byDoc
+				i, _ := slices.BinarySearch(x.vocab, term)
+				x.vocab = slices.Insert(x.vocab, i, term)
+			}
+			if byDoc[id] == nil {
+				byDoc[id] = make(map[string]int)
+				doc.terms = append(doc.terms, term)
+			}
+			byDoc[id][name]++
+			doc.lengths[name]++
+		}
+		x.totals[name] += doc.lengths[name]
+	}
+	x.docs[id] = doc
+}
+
+// Remove drops a document from the index.
+func (x *Index) Remove(id string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.remove(id)
+}
+
+// remove drops a document. Callers must hold mu.
+func (x *Index) remove(id string) {
+	doc, ok := x.docs[id]
+	if !ok {
+		return
+	}
+	delete(x.docs, id)
+	for name, n := range doc.lengths {
+		x.totals[name] -= n
+	}
+	for _, word := range doc.words {
+		x.wordDocs[word]--
+		if x.wordDocs[word] == 0 {
+			delete(x.wordDocs, word)
+			delete(x.stems, word)
+			if i, found := slices.BinarySearch(x.words, word); found {
+				x.words = slices.Delete(x.words, i, i+1)
+			}
+		}
+	}
+	for _, term := range doc.terms {
+		byDoc := x.postings[term]
+		delete(byDoc, id)
+		if len(byDoc) == 0 {
+			delete(x.postings, term)
+			if i, found := slices.BinarySearch(x.vocab, term); found {
+				x.vocab = slices.Delete(x.vocab, i, i+1)
+			}
+		}
+	}
+}
+
+// addWord records an unstemmed word of a new document. Callers must hold mu.
+func (x *Index) addWord(word, term string) {
+	if x.wordDocs[word] == 0 {
+		i, _ := slices.BinarySearch(x.words, word)
+		x.words = slices.Insert(x.words, i, word)
+		x.stems[word] = term
+	}
+	x.wordDocs[word]++
+}
+
+// Search returns up to limit documents containing every query word, best
+// first, along with the total number of matches. A query word matches its
+// stem exactly or, with a lower weight, any indexed term or indexed word it
+// is a prefix of, so "depl" and "deploym" both find "deployment".
+func (x *Index) Search(query string, limit int) ([]Hit, int) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	var (
+		scores  map[string]float64
+		matched = make(map[string]bool) // index terms matched by any query word
+	)
+	for _, tok := range tokenize(query) {
+		if isStopWord(tok.word) {
+			continue
+		}
+		wordScores := x.scoreWord(tok.word, matched)
+
+		// Every query word must match, so keep only documents seen for all.
+		if scores == nil {
+			scores = wordScores
+			continue
+		}
+		for id, score := range scores {
+			if s, ok := wordScores[id]; ok {
+				scores[id] = score + s
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	slices.SortFunc(hits, func(a, b Hit) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	total := len(hits)
+	if limit >= 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	for i := range hits {
+		hits[i].Highlights = x.highlights(x.docs[hits[i].ID], matched)
+	}
+	return hits, total
+}
+
+// scoreWord scores every document containing a query word, taking the best
+// of its exact and prefix matches. Matched terms are added to matched.
+// Callers must hold mu.
+func (x *Index) scoreWord(word string, matched map[string]bool) map[string]float64 {
+	scores := make(map[string]float64)
+	add := func(term string, weight float64) {
+		byDoc, ok := x.postings[term]
+		if !ok {
+			return
+		}
+		matched[term] = true
+		for id, freqs := range byDoc {
+			if s := weight * x.bm25(term, x.docs[id], freqs); s > scores[id] {
+				scores[id] = s
+			}
+		}
+	}
+
+	stem := Stem(word)
+	add(stem, 1)
+	if len(word) >= minPrefixLength {
+		// Stems can be longer than the words they came from ("happy" is
+		// indexed as "happi") and shorter ("deployment" as "deploy"), so
+		// a prefix is looked up among both.
+		prefixed := map[string]bool{stem: true}
+		for i, _ := slices.BinarySearch(x.vocab, word); i < len(x.vocab) && strings.HasPrefix(x.vocab[i], word); i++ {
+			prefixed[x.vocab[i]] = true
+		}
+		for i, _ := slices.BinarySearch(x.words, word); i < len(x.words) && strings.HasPrefix(x.words[i], word); i++ {
+			prefixed[x.stems[x.words[i]]] = true
+		}
+		delete(prefixed, stem)
+		for term := range prefixed {
+			add(term, prefixWeight)
+		}
+	}
+	return scores
+}
+
+// bm25 scores one term in one document, summing the boosted score of each
+// field it appears in. Callers must hold mu.
+func (x *Index) bm25(term string, doc *document, freqs map[string]int) float64 {
+	n := float64(len(x.docs))
+	df := float64(len(x.postings[term]))
+	idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+	score := 0.0
+	for name, tf := range freqs {
+		avg := float64(x.totals[name]) / n
+		norm := 1 - bm25B + bm25B*float64(doc.lengths[name])/avg
+		boost, ok := x.boosts[name]
+		if !ok {
+			boost = 1
+		}
+		score += boost * idf * float64(tf) * (bm25K1 + 1) / (float64(tf) + bm25K1*norm)
+	}
+	return score
+}
+
+// highlights builds a snippet for each field of doc containing a matched
+// term. Callers must hold mu.
+func (x *Index) highlights(doc *document, matched map[string]bool) map[string]string {
+	out := make(map[string]string)
+	for name, text := range doc.fields {
+		if snippet, ok := highlight(text, matched); ok {
+			out[name] = snippet
+		}
+	}
+	return out
+}
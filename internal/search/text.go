@@ -0,0 +1,111 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// snippetLength is the approximate length, in bytes, of a highlighted
+// snippet taken from a longer text.
+const snippetLength = 160
+
+// token is a word and its byte range in the original text.
+type token struct {
+	word       string // lowercased
+	start, end int
+}
+
+// tokenize splits text into lowercased words of letters and digits.
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			tokens = append(tokens, token{strings.ToLower(text[start:i]), start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{strings.ToLower(text[start:]), start, len(text)})
+	}
+	return tokens
+}
+
+// stopWords are common English words left out of the index.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "for": true, "if": true, "in": true,
+	"into": true, "is": true, "it": true, "no": true, "not": true, "of": true,
+	"on": true, "or": true, "such": true, "that": true, "the": true, "their": true,
+	"then": true, "there": true, "these": true, "they": true, "this": true,
+	"to": true, "was": true, "will": true, "with": true,
+}
+
+func isStopWord(word string) bool {
+	return stopWords[word]
+}
+
+// highlight returns text, HTML-escaped, with every word whose stem is in
+// terms wrapped in <mark> tags. Texts longer than snippetLength are cut to a
+// window around the first match, marked with ellipses. ok is false when no
+// word matches.
+func highlight(text string, terms map[string]bool) (snippet string, ok bool) {
+	tokens := tokenize(text)
+	var marks []token
+	for _, tok := range tokens {
+		if !isStopWord(tok.word) && terms[Stem(tok.word)] {
+			marks = append(marks, tok)
+		}
+	}
+	if len(marks) == 0 {
+		return "", false
+	}
+
+	from, to := 0, len(text)
+	if len(text) > snippetLength {
+		from = max(0, marks[0].start-snippetLength/4)
+		to = min(len(text), from+snippetLength)
+		// Widen to word boundaries so no word is cut in half.
+		for _, tok := range tokens {
+			if tok.start < from && tok.end > from {
+				from = tok.start
+			}
+			if tok.start < to && tok.end > to {
+				to = tok.end
+			}
+		}
+		for from > 0 && !utf8.RuneStart(text[from]) {
+			from--
+		}
+		for to < len(text) && !utf8.RuneStart(text[to]) {
+			to++
+		}
+	}
+
+	var sb strings.Builder
+	if from > 0 {
+		sb.WriteString("…")
+	}
+	pos := from
+	for _, m := range marks {
+		if m.start < from || m.end > to {
+			continue
+		}
+		sb.WriteString(html.EscapeString(text[pos:m.start]))
+		sb.WriteString("<mark>")
+		sb.WriteString(html.EscapeString(text[m.start:m.end]))
+		sb.WriteString("</mark>")
+		pos = m.end
+	}
+	sb.WriteString(html.EscapeString(text[pos:to]))
+	if to < len(text) {
+		sb.WriteString("…")
+	}
+	return strings.TrimSpace(sb.String()), true
+}
@@ -0,0 +1,201 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Batch modes accepted by POST /tasks:batch.
+const (
+	BatchAtomic     = "atomic"      // apply every operation or none
+	BatchBestEffort = "best_effort" // apply each operation on its own
+)
+
+type batchRequest struct {
+	Mode       string                  `json:"mode" openapi:"enum=atomic|best_effort"` // defaults to BatchAtomic
+	Operations []batchOperationRequest `json:"operations" openapi:"required"`
+}
+
+type batchOperationRequest struct {
+	Op      string          `json:"op" openapi:"required,enum=create|update|delete"`
+	ID      string          `json:"id"`      // update and delete
+	Version *int64          `json:"version"` // optional precondition for updates
+	Task    json.RawMessage `json:"task"`    // create and update
+}
+
+// refineSchema describes task by op: a CreateTaskRequest for creates and an
+// UpdateTaskRequest for updates.
+func (batchOperationRequest) refineSchema(b *schemaBuilder, s schema) {
+	taskOf := func(op domain.BatchOp, body any) schema {
+		return schema{
+			"if":   schema{"properties": schema{"op": schema{"const": op}}, "required": []string{"op"}},
+			"then": schema{"properties": schema{"task": b.of(reflect.TypeOf(body))}, "required": []string{"task"}},
+		}
+	}
+	s["allOf"] = []any{
+		taskOf(domain.BatchCreate, createTaskRequest{}),
+		taskOf(domain.BatchUpdate, updateTaskRequest{}),
+	}
+}
+
+// updateTaskRequest is a partial update: fields left out are kept.
+type updateTaskRequest struct {
+	ParentID    *string            `json:"parent_id"`
+	Title       *string            `json:"title"`
+	Description *string            `json:"description"`
+	Status      *domain.TaskStatus `json:"status"`
+	Priority    *domain.Priority   `json:"priority"`
+	Tags        *[]string          `json:"tags"`
+	DueDate     *string            `json:"due_date" openapi:"format=date-time"` // ISO8601 format
+	Recurrence  *string            `json:"recurrence"`
+}
+
+// input converts the request to service input. An empty due_date is passed
+// on, and rejected, rather than ignored.
+func (r updateTaskRequest) input() (domain.UpdateTaskInput, error) {
+	input := domain.UpdateTaskInput{
+		ParentID:    r.ParentID,
+		Title:       r.Title,
+		Description: r.Description,
+		Status:      r.Status,
+		Priority:    r.Priority,
+		Tags:        r.Tags,
+		Recurrence:  r.Recurrence,
+	}
+	if r.DueDate != nil {
+		var due time.Time
+		if *r.DueDate != "" {
+			var err error
+			if due, err = time.Parse(time.RFC3339, *r.DueDate); err != nil {
+				return input, invalidDueDate()
+			}
+		}
+		input.DueDate = &due
+	}
+	return input, nil
+}
+
+// operation converts the request to a batch operation.
+func (r batchOperationRequest) operation() (domain.BatchOperation, error) {
+	op := domain.BatchOperation{Op: domain.BatchOp(r.Op), ID: r.ID}
+	switch op.Op {
+	case domain.BatchCreate:
+		var req createTaskRequest
+		if err := json.Unmarshal(r.Task, &req); err != nil {
+			return op, fiber.NewError(fiber.StatusBadRequest, "invalid task")
+		}
+		input, err := req.input()
+		if err != nil {
+			return op, err
+		}
+		op.Create = input
+
+	case domain.BatchUpdate:
+		var req updateTaskRequest
+		if err := json.Unmarshal(r.Task, &req); err != nil {
+			return op, fiber.NewError(fiber.StatusBadRequest, "invalid task")
+		}
+		input, err := req.input()
+		if err != nil {
+			return op, err
+		}
+		input.ExpectedVersion = r.Version
+		op.Update = input
+
+	case domain.BatchDelete:
+
+	default:
+		return op, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("invalid op %q, expected create, update or delete", r.Op))
+	}
+	return op, nil
+}
+
+type batchItemResponse struct {
+	Status int          `json:"status"`
+	Task   *domain.Task `json:"task,omitempty"`
+	Error  *problem     `json:"error,omitempty"`
+}
+
+type batchResponse struct {
+	Mode      string              `json:"mode"`
+	Succeeded int                 `json:"succeeded"`
+	Failed    int                 `json:"failed"`
+	Results   []batchItemResponse `json:"results"`
+}
+
+// Batch handles POST /tasks:batch. Malformed operations reject the whole
+// request; otherwise the response holds one result per operation, in order,
+// and is 200 if every operation succeeded and 207 if any failed.
+func (h *TaskHandler) Batch(c *fiber.Ctx) error {
+	var req batchRequest
+	if err := json.Unmarshal(c.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON body")
+	}
+	if req.Mode == "" {
+		req.Mode = BatchAtomic
+	}
+
+	ops := make([]domain.BatchOperation, len(req.Operations))
+	for i, r := range req.Operations {
+		op, err := r.operation()
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("operations[%d]: %s", i, err.Error()))
+		}
+		ops[i] = op
+	}
+
+	var results []domain.BatchResult
+	var err error
+	switch req.Mode {
+	case BatchAtomic:
+		results, err = h.service.ApplyBatchAtomic(c.UserContext(), ops)
+	case BatchBestEffort:
+		results, err = h.service.ApplyBatch(c.UserContext(), ops)
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("invalid mode %q, expected %s or %s", req.Mode, BatchAtomic, BatchBestEffort))
+	}
+	if err != nil {
+		return err
+	}
+
+	resp := batchResponse{Mode: req.Mode, Results: make([]batchItemResponse, len(results))}
+	for i, result := range results {
+		item := batchItemResponse{Status: batchStatus(ops[i].Op, result.Err), Task: result.Task}
+		if result.Err != nil {
+			resp.Failed++
+			item.Error = newProblem(c, result.Err).withStatus(item.Status)
+			logServerError(c, item.Error, result.Err)
+		} else {
+			resp.Succeeded++
+		}
+		resp.Results[i] = item
+	}
+
+	if resp.Failed > 0 {
+		return c.Status(fiber.StatusMultiStatus).JSON(resp)
+	}
+	return c.JSON(resp)
+}
+
+// batchStatus returns the status code a single operation would have had as
+// its own request. Operations rolled back with an atomic batch are 424.
+func batchStatus(op domain.BatchOp, err error) int {
+	switch {
+	case err == nil && op == domain.BatchCreate:
+		return fiber.StatusCreated
+	case err == nil && op == domain.BatchDelete:
+		return fiber.StatusNoContent
+	case err == nil:
+		return fiber.StatusOK
+	case pkgerrors.HasCode(err, domain.CodeBatchAborted):
+		return fiber.StatusFailedDependency
+	default:
+		return pkgerrors.StatusOf(err)
+	}
+}
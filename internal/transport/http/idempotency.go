@@ -0,0 +1,190 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Idempotency headers. A retried request carrying the same Idempotency-Key
+// gets the original response back, marked with Idempotent-Replayed.
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+// DefaultIdempotencyTTL is how long responses are kept for replay.
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// maxIdempotencyKeyLength bounds the Idempotency-Key header.
+const maxIdempotencyKeyLength = 255
+
+// replayedHeaders are the response headers stored and replayed along with
+// the status and body.
+var replayedHeaders = []string{fiber.HeaderContentType, fiber.HeaderETag, fiber.HeaderLocation}
+
+// idempotencyStore remembers the response to each idempotency key. A key is
+// claimed when its first request starts and holds the response once that
+// request succeeds; failed requests release the key so it can be retried.
+type idempotencyStore struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*idempotencyEntry
+	expiry  []expiringKey // completed keys, soonest expiry first
+}
+
+type idempotencyEntry struct {
+	fingerprint string
+	response    *storedResponse // nil while the first request is in flight
+	expires     time.Time
+}
+
+type storedResponse struct {
+	status int
+	body   []byte
+	header map[string]string
+}
+
+type expiringKey struct {
+	key   string
+	entry *idempotencyEntry
+}
+
+func newIdempotencyStore(ttl time.Duration) *idempotencyStore {
+	return &idempotencyStore{ttl: ttl, entries: make(map[string]*idempotencyEntry)}
+}
+
+// claim looks up key. It returns the entry of a new claim, or the stored
+// response of a completed request with the same fingerprint. It fails with
+// 422 if key was used for a different request and with 409 while the first
+// request is still running.
+func (s *idempotencyStore) claim(key, fingerprint string) (*idempotencyEntry, *storedResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Every key expires ttl after it completes, so expired keys are all at
+	// the front.
+	now := time.Now()
+	for len(s.expiry) > 0 && !now.Before(s.expiry[0].entry.expires) {
+		if s.entries[s.expiry[0].key] == s.expiry[0].entry {
+			delete(s.entries, s.expiry[0].key)
+		}
+		s.expiry = s.expiry[1:]
+	}
+
+	if e, ok := s.entries[key]; ok {
+		if e.fingerprint != fingerprint {
+			return nil, nil, fiber.NewError(fiber.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request")
+		}
+		if e.response == nil {
+			return nil, nil, fiber.NewError(fiber.StatusConflict, "a request with this Idempotency-Key is still in progress")
+		}
+		return nil, e.response, nil
+	}
+
+	e := &idempotencyEntry{fingerprint: fingerprint}
+	s.entries[key] = e
+	return e, nil, nil
+}
+
+// complete stores the response to a claimed key.
+func (s *idempotencyStore) complete(key string, e *idempotencyEntry, resp *storedResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e.response = resp
+	e.expires = time.Now().Add(s.ttl)
+	s.expiry = append(s.expiry, expiringKey{key: key, entry: e})
+}
+
+// release drops a claim whose request failed.
+func (s *idempotencyStore) release(key string, e *idempotencyEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.entries[key] == e {
+		delete(s.entries, key)
+	}
+}
+
+// idempotent is middleware that honours the Idempotency-Key header. Keys are
+// scoped to the actor, and only successful responses are stored.
+func (h *TaskHandler) idempotent(c *fiber.Ctx) error {
+	key := c.Get(HeaderIdempotencyKey)
+	if key == "" || h.idempotency == nil {
+		return c.Next()
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return fiber.NewError(fiber.StatusBadRequest, "Idempotency-Key is too long")
+	}
+
+	key = domain.ActorFrom(c.UserContext()) + "\x00" + key
+	entry, stored, err := h.idempotency.claim(key, requestFingerprint(c))
+	if err != nil {
+		return err
+	}
+	if stored != nil {
+		for name, value := range stored.header {
+			c.Set(name, value)
+		}
+		c.Set(HeaderIdempotentReplayed, "true")
+		return c.Status(stored.status).Send(stored.body)
+	}
+
+	completed := false
+	defer func() {
+		if !completed {
+			h.idempotency.release(key, entry)
+		}
+	}()
+
+	if err := c.Next(); err != nil {
+		return err
+	}
+	status := c.Response().StatusCode()
+	if status < 200 || status > 299 {
+		return nil
+	}
+
+	resp := &storedResponse{
+		status: status,
+		body:   append([]byte(nil), c.Response().Body()...),
+		header: make(map[string]string),
+	}
+	for _, name := range replayedHeaders {
+		if value := c.GetRespHeader(name); value != "" {
+			resp.header[name] = value
+		}
+	}
+	h.idempotency.complete(key, entry, resp)
+	completed = true
+	return nil
+}
+
+// requestFingerprint hashes the route, path parameters and body of a
+// request. The route is taken relative to its version, so a retry through
+// /tasks matches a request made to /v1/tasks. JSON bodies are compared by
+// value, so formatting and key order do not matter.
+func requestFingerprint(c *fiber.Ctx) string {
+	body := c.Body()
+	var v any
+	if json.Unmarshal(body, &v) == nil {
+		if canonical, err := json.Marshal(v); err == nil {
+			body = canonical
+		}
+	}
+
+	sum := sha256.New()
+	sum.Write([]byte(routeKey(c) + "\n"))
+	for _, name := range c.Route().Params {
+		sum.Write([]byte(name + "=" + c.Params(name) + "\n"))
+	}
+	sum.Write(body)
+	return hex.EncodeToString(sum.Sum(nil))
+}
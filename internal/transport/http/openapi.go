@@ -0,0 +1,538 @@
+package http
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	"github.com/gauravpandey771/task-api/pkg/jsonpatch"
+	"github.com/gofiber/fiber/v2"
+)
+
+// OpenAPIVersion is the version of the OpenAPI specification followed by the
+// document served at /openapi.json.
+const OpenAPIVersion = "3.1.0"
+
+// APIVersion is the version of the API the document describes.
+const APIVersion = "1.0.0"
+
+// schemaRefPrefix is prefixed to the name of a schema to refer to it.
+const schemaRefPrefix = "#/components/schemas/"
+
+//go:embed docs.html
+var docsPage []byte
+
+// docsAssets is where the docs page loads Swagger UI from. The version is
+// pinned, and the page's Content-Security-Policy only allows scripts and
+// styles from this release and the page's own inline script. Swagger UI is
+// not embedded, so the browser viewing the page needs access to unpkg.com;
+// the OpenAPI document itself is served without it.
+const docsAssets = "https://unpkg.com/swagger-ui-dist@5.17.14/"
+
+// docsPolicy is the Content-Security-Policy of the docs page.
+var docsPolicy = func() string {
+	inline := regexp.MustCompile(`(?s)<script>(.*?)</script>`).FindSubmatch(docsPage)
+	sum := sha256.Sum256(inline[1])
+	return "default-src 'none'; " +
+		"script-src " + docsAssets + " 'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'; " +
+		"style-src " + docsAssets + " 'unsafe-inline'; " +
+		"img-src 'self' data:; connect-src 'self'"
+}()
+
+// schema is a JSON Schema.
+type schema = map[string]any
+
+// content maps media types to bodies. Bodies are zero values whose schemas
+// are derived from their type, or schemas used as they are.
+type content map[string]any
+
+// parameter is a query or header parameter of an operation.
+type parameter struct {
+	Name        string
+	In          string // "query" or "header"
+	Schema      schema
+	Required    bool
+	Description string
+}
+
+// operation documents a route. Path parameters are taken from the route.
+type operation struct {
+	ID        string
+	Summary   string
+	Params    []parameter
+	Body      content
+	Responses map[int]any // status -> body or content; nil for no body
+}
+
+// Reusable parameters.
+var (
+	paramActor          = headerParam(HeaderActor, "Who is making the change; recorded in task history")
+	paramIfMatch        = headerParam(fiber.HeaderIfMatch, "Version the task must be at, as an ETag")
+	paramIdempotencyKey = headerParam(HeaderIdempotencyKey, "Makes retries of the request safe; see Safe Retries")
+	paramSort           = queryParam("sort", schema{"type": "string"}, "Comma-separated sort keys, e.g. -updated_at,title")
+	paramPage           = queryParam("page", schema{"type": "integer", "minimum": 1}, "Page number, starting at 1")
+	paramPageSize       = queryParam("page_size", schema{"type": "integer", "minimum": 1}, "Tasks per page")
+	paramCursor         = queryParam("cursor", schema{"type": "string"}, "Cursor of the page to return, from next_cursor")
+)
+
+// operations documents every route, keyed by method and OpenAPI path.
+var operations = map[string]operation{
+	"POST /tasks": {
+		ID:        "createTask",
+		Summary:   "Create a task",
+		Params:    []parameter{paramActor, paramIdempotencyKey},
+		Body:      jsonContent(createTaskRequest{}),
+		Responses: map[int]any{http.StatusCreated: domain.Task{}},
+	},
+	"POST /tasks:batch": {
+		ID:        "batchTasks",
+		Summary:   "Create, update and delete several tasks at once",
+		Params:    []parameter{paramActor},
+		Body:      jsonContent(batchRequest{}),
+		Responses: map[int]any{http.StatusOK: batchResponse{}, http.StatusMultiStatus: batchResponse{}},
+	},
+	"GET /tasks": {
+		ID:      "listTasks",
+		Summary: "List tasks",
+		Params: []parameter{
+			queryParam("status", schema{"$ref": schemaRefPrefix + "TaskStatus"}, "Only tasks with this status"),
+			queryParam("priority", schema{"type": "string"}, "Only tasks with one of these comma-separated priorities"),
+			queryParam("tag", schema{"type": "string"}, "Only tasks with these comma-separated tags"),
+			queryParam("tag_match", schema{"type": "string", "enum": []any{domain.TagMatchAny, domain.TagMatchAll}}, "Whether tasks need any or all of the tags"),
+			queryParam("parent_id", schema{"type": "string"}, "Only subtasks of this task"),
+			queryParam("filter", schema{"type": "string", "maxLength": domain.MaxFilterLength}, "Filter expression, e.g. status = DONE and title ~ \"deploy\""),
+			paramSort, paramPage, paramPageSize, paramCursor,
+		},
+		Responses: map[int]any{http.StatusOK: domain.TaskPage{}},
+	},
+	"GET /tasks/search": {
+		ID:      "searchTasks",
+		Summary: "Search tasks by title and description",
+		Params: []parameter{
+			{Name: "q", In: "query", Schema: schema{"type": "string"}, Required: true, Description: "Search query"},
+			queryParam("limit", schema{"type": "integer", "minimum": 1, "maximum": domain.MaxSearchLimit}, "Most results to return"),
+		},
+		Responses: map[int]any{http.StatusOK: domain.SearchPage{}},
+	},
+	"GET /tasks/trash": {
+		ID:        "listTrash",
+		Summary:   "List deleted tasks",
+		Params:    []parameter{paramSort, paramPage, paramPageSize, paramCursor},
+		Responses: map[int]any{http.StatusOK: domain.TaskPage{}},
+	},
+	"GET /tasks/{id}": {
+		ID:        "getTask",
+		Summary:   "Get a task",
+		Responses: map[int]any{http.StatusOK: domain.Task{}},
+	},
+	"PUT /tasks/{id}": {
+		ID:        "replaceTask",
+		Summary:   "Replace a task",
+		Params:    []parameter{paramActor, paramIfMatch},
+		Body:      jsonContent(createTaskRequest{}),
+		Responses: map[int]any{http.StatusOK: domain.Task{}},
+	},
+	"PATCH /tasks/{id}": {
+		ID:      "patchTask",
+		Summary: "Update a task with a merge patch or a JSON patch",
+		Params:  []parameter{paramActor, paramIfMatch},
+		Body: content{
+			MIMEMergePatch: schema{"type": "object"},
+			MIMEJSONPatch:  []jsonpatch.Operation{},
+		},
+		Responses: map[int]any{http.StatusOK: domain.Task{}},
+	},
+	"DELETE /tasks/{id}": {
+		ID:        "deleteTask",
+		Summary:   "Move a task to the trash",
+		Params:    []parameter{paramActor},
+		Responses: map[int]any{http.StatusNoContent: nil},
+	},
+	"POST /tasks/{id}/restore": {
+		ID:        "restoreTask",
+		Summary:   "Restore a task from the trash",
+		Params:    []parameter{paramActor},
+		Responses: map[int]any{http.StatusOK: domain.Task{}},
+	},
+	"DELETE /tasks/{id}/purge": {
+		ID:        "purgeTask",
+		Summary:   "Delete a task from the trash for good",
+		Params:    []parameter{paramActor},
+		Responses: map[int]any{http.StatusNoContent: nil},
+	},
+	"GET /tasks/{id}/children": {
+		ID:        "listChildren",
+		Summary:   "List the subtasks of a task",
+		Params:    []parameter{paramSort, paramPage, paramPageSize, paramCursor},
+		Responses: map[int]any{http.StatusOK: domain.TaskPage{}},
+	},
+	"POST /tasks/{id}/dependencies": {
+		ID:        "addDependency",
+		Summary:   "Block a task on another",
+		Params:    []parameter{paramActor},
+		Body:      jsonContent(addDependencyRequest{}),
+		Responses: map[int]any{http.StatusOK: domain.Task{}},
+	},
+	"DELETE /tasks/{id}/dependencies/{blockerId}": {
+		ID:        "removeDependency",
+		Summary:   "Unblock a task",
+		Params:    []parameter{paramActor},
+		Responses: map[int]any{http.StatusOK: domain.Task{}},
+	},
+	"GET /tasks/{id}/graph": {
+		ID:        "dependencyGraph",
+		Summary:   "Get the transitive blockers of a task",
+		Responses: map[int]any{http.StatusOK: domain.DependencyGraph{}},
+	},
+	"GET /tasks/{id}/occurrences": {
+		ID:        "previewOccurrences",
+		Summary:   "Preview the next due dates of a recurring task",
+		Params:    []parameter{queryParam("count", schema{"type": "integer", "minimum": 1}, "Number of occurrences")},
+		Responses: map[int]any{http.StatusOK: occurrencesResponse{}},
+	},
+	"GET /tasks/{id}/history": {
+		ID:        "getHistory",
+		Summary:   "List the changes made to a task",
+		Responses: map[int]any{http.StatusOK: []domain.HistoryEntry{}},
+	},
+	"GET /tags": {
+		ID:        "listTags",
+		Summary:   "List tags with the number of tasks using them",
+		Responses: map[int]any{http.StatusOK: []domain.TagCount{}},
+	},
+	"POST /tags/rename": {
+		ID:        "renameTag",
+		Summary:   "Rename a tag, merging it into an existing one",
+		Params:    []parameter{paramActor},
+		Body:      jsonContent(renameTagRequest{}),
+		Responses: map[int]any{http.StatusOK: renameTagResponse{}},
+	},
+	"GET /openapi.json": {
+		ID:        "getOpenAPI",
+		Summary:   "Get this OpenAPI document",
+		Responses: map[int]any{http.StatusOK: schema{"type": "object"}},
+	},
+	"GET /docs": {
+		ID:        "getDocs",
+		Summary:   "Browse the API documentation",
+		Responses: map[int]any{http.StatusOK: content{fiber.MIMETextHTMLCharsetUTF8: schema{"type": "string"}}},
+	},
+}
+
+// OpenAPI handles GET /openapi.json with a document describing the routes
+// served alongside it, e.g. those under /v1 for /v1/openapi.json. It is
+// built on first use, once every route has been registered.
+func (h *TaskHandler) OpenAPI(c *fiber.Ctx) error {
+	m := mountOf(c)
+	doc, ok := h.docs.Load(m)
+	if !ok {
+		data, err := json.Marshal(h.openAPIDocument(c.App().GetRoutes(true), m))
+		if err != nil {
+			return err
+		}
+		doc, _ = h.docs.LoadOrStore(m, data)
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(doc.([]byte))
+}
+
+// Docs handles GET /docs with a page for browsing the OpenAPI document. The
+// page loads Swagger UI from docsAssets and stays blank offline.
+func Docs(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	c.Set(fiber.HeaderContentSecurityPolicy, docsPolicy)
+	return c.Send(docsPage)
+}
+
+// openAPIDocument describes the routes served under m. Routes without an
+// entry in operations are left out.
+func (h *TaskHandler) openAPIDocument(routes []fiber.Route, m mount) map[string]any {
+	b := newSchemaBuilder(h.workflow)
+	paths := make(map[string]map[string]any)
+	for _, r := range routes {
+		if r.Method == fiber.MethodHead || !strings.HasPrefix(r.Path, m.prefix+"/") {
+			continue
+		}
+		path := openAPIPath(strings.TrimPrefix(r.Path, m.prefix))
+		op, ok := operations[r.Method+" "+path]
+		if !ok {
+			continue
+		}
+		if paths[path] == nil {
+			paths[path] = make(map[string]any)
+		}
+		described := b.operation(op, r.Params)
+		if m.deprecated {
+			described["deprecated"] = true
+		}
+		paths[path][strings.ToLower(r.Method)] = described
+	}
+
+	server := m.prefix
+	if server == "" {
+		server = "/"
+	}
+	return map[string]any{
+		"openapi": OpenAPIVersion,
+		"info": map[string]any{
+			"title":   "Task Management API",
+			"version": APIVersion,
+		},
+		"servers": []any{map[string]any{"url": server}},
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": b.defs,
+			"responses": map[string]any{
+				"Problem": map[string]any{
+					"description": "Error, as RFC 7807 problem details",
+					"content":     b.content(content{MIMEProblem: problem{}}, false),
+				},
+			},
+		},
+	}
+}
+
+// routeKey names the route of c by its method and OpenAPI path below its
+// mount, e.g. "GET /tasks/{id}", as in operations.
+func routeKey(c *fiber.Ctx) string {
+	route := c.Route()
+	return route.Method + " " + openAPIPath(strings.TrimPrefix(route.Path, mountOf(c).prefix))
+}
+
+// routeParam matches a Fiber path parameter that is not escaped.
+var routeParam = regexp.MustCompile(`(^|[^\\]):(\w+)`)
+
+// openAPIPath converts a Fiber route path to an OpenAPI path, e.g.
+// /tasks/:id to /tasks/{id}.
+func openAPIPath(path string) string {
+	path = routeParam.ReplaceAllString(path, "$1{$2}")
+	return strings.ReplaceAll(path, `\`, "")
+}
+
+// operation describes op, a route with the given path parameters.
+func (b *schemaBuilder) operation(op operation, pathParams []string) map[string]any {
+	params := []any{}
+	for _, name := range pathParams {
+		params = append(params, map[string]any{"name": name, "in": "path", "required": true, "schema": schema{"type": "string"}})
+	}
+	for _, p := range op.Params {
+		param := map[string]any{"name": p.Name, "in": p.In, "schema": p.Schema}
+		if p.Required {
+			param["required"] = true
+		}
+		if p.Description != "" {
+			param["description"] = p.Description
+		}
+		params = append(params, param)
+	}
+
+	responses := map[string]any{
+		"default": map[string]any{"$ref": "#/components/responses/Problem"},
+	}
+	for status, body := range op.Responses {
+		resp := map[string]any{"description": http.StatusText(status)}
+		switch body := body.(type) {
+		case nil:
+		case content:
+			resp["content"] = b.content(body, false)
+		default:
+			resp["content"] = b.content(jsonContent(body), false)
+		}
+		responses[strconv.Itoa(status)] = resp
+	}
+
+	out := map[string]any{
+		"operationId": op.ID,
+		"summary":     op.Summary,
+		"parameters":  params,
+		"responses":   responses,
+	}
+	if op.Body != nil {
+		out["requestBody"] = map[string]any{"required": true, "content": b.content(op.Body, true)}
+	}
+	return out
+}
+
+// content describes the bodies of c.
+func (b *schemaBuilder) content(c content, request bool) map[string]any {
+	out := make(map[string]any, len(c))
+	for mediaType, body := range c {
+		out[mediaType] = map[string]any{"schema": b.schema(body, request)}
+	}
+	return out
+}
+
+// schema returns the schema of a request or response body.
+func (b *schemaBuilder) schema(body any, request bool) schema {
+	if s, ok := body.(schema); ok {
+		return s
+	}
+	b.request = request
+	return b.of(reflect.TypeOf(body))
+}
+
+// schemaBuilder derives JSON Schemas from Go types. Named structs and enums
+// are added to defs and referenced.
+type schemaBuilder struct {
+	defs  map[string]any
+	enums map[reflect.Type][]any
+
+	// request is set while describing request bodies, whose objects reject
+	// unknown fields and whose fields are only required if tagged so.
+	// Fields of responses are required unless omitempty.
+	request bool
+}
+
+func newSchemaBuilder(w *domain.Workflow) *schemaBuilder {
+	statuses := []any{}
+	for _, s := range w.States() {
+		statuses = append(statuses, s)
+	}
+	priorities := []any{}
+	for _, p := range domain.Priorities {
+		priorities = append(priorities, p)
+	}
+	return &schemaBuilder{
+		defs: make(map[string]any),
+		enums: map[reflect.Type][]any{
+			reflect.TypeOf(domain.TaskStatus("")): statuses,
+			reflect.TypeOf(domain.Priority("")):   priorities,
+			reflect.TypeOf(domain.HistoryAction("")): {
+				domain.HistoryCreated, domain.HistoryUpdated, domain.HistoryDeleted, domain.HistoryRestored, domain.HistoryPurged,
+			},
+		},
+	}
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+// of returns the schema of t.
+func (b *schemaBuilder) of(t reflect.Type) schema {
+	if values, ok := b.enums[t]; ok {
+		name := t.Name()
+		b.defs[name] = schema{"type": "string", "enum": values}
+		return schema{"$ref": schemaRefPrefix + name}
+	}
+
+	switch t {
+	case timeType:
+		return schema{"type": "string", "format": "date-time"}
+	case rawJSONType:
+		return schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return b.of(t.Elem())
+	case reflect.String:
+		return schema{"type": "string"}
+	case reflect.Bool:
+		return schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return schema{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return schema{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return schema{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return schema{"type": "array", "items": b.of(t.Elem())}
+	case reflect.Map:
+		return schema{"type": "object", "additionalProperties": b.of(t.Elem())}
+	case reflect.Struct:
+		return b.object(t)
+	default:
+		return schema{}
+	}
+}
+
+// object adds the schema of struct t to defs and returns a reference to it.
+func (b *schemaBuilder) object(t reflect.Type) schema {
+	name := []rune(t.Name())
+	name[0] = unicode.ToUpper(name[0])
+	ref := schema{"$ref": schemaRefPrefix + string(name)}
+	if _, ok := b.defs[string(name)]; ok {
+		return ref
+	}
+	s := schema{"type": "object"}
+	b.defs[string(name)] = s // placeholder for recursive types
+
+	properties := make(map[string]any)
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+		fieldName, opts, _ := strings.Cut(tag, ",")
+		if fieldName == "" {
+			fieldName = f.Name
+		}
+
+		prop := b.of(f.Type)
+		isRequired := !b.request && !strings.Contains(opts, "omitempty")
+		for _, opt := range strings.Split(f.Tag.Get("openapi"), ",") {
+			key, value, _ := strings.Cut(opt, "=")
+			switch key {
+			case "required":
+				isRequired = true
+			case "format":
+				prop["format"] = value
+			case "enum":
+				values := []any{}
+				for _, v := range strings.Split(value, "|") {
+					values = append(values, v)
+				}
+				prop["enum"] = values
+			}
+		}
+
+		properties[fieldName] = prop
+		if isRequired {
+			required = append(required, fieldName)
+		}
+	}
+
+	s["properties"] = properties
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	if b.request {
+		s["additionalProperties"] = false
+	}
+	if r, ok := reflect.Zero(t).Interface().(schemaRefiner); ok {
+		r.refineSchema(b, s)
+	}
+	return ref
+}
+
+// schemaRefiner is implemented by types whose schema depends on the values
+// of their fields, which struct tags cannot describe.
+type schemaRefiner interface {
+	refineSchema(b *schemaBuilder, s schema)
+}
+
+// jsonContent is a JSON body.
+func jsonContent(body any) content {
+	return content{fiber.MIMEApplicationJSON: body}
+}
+
+func queryParam(name string, s schema, description string) parameter {
+	return parameter{Name: name, In: "query", Schema: s, Description: description}
+}
+
+func headerParam(name, description string) parameter {
+	return parameter{Name: name, In: "header", Schema: schema{"type": "string"}, Description: description}
+}
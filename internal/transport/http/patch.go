@@ -0,0 +1,124 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"reflect"
+	"slices"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	"github.com/gauravpandey771/task-api/pkg/jsonpatch"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Patch media types accepted by PATCH /tasks/:id.
+const (
+	MIMEMergePatch = "application/merge-patch+json"
+	MIMEJSONPatch  = "application/json-patch+json"
+)
+
+// writableFields are the task fields a patch may change. Each is always
+// present in the patched document, so JSON Patch can replace it even when
+// it is empty.
+var writableFields = map[string]any{
+	"parent_id":   "",
+	"title":       "",
+	"description": "",
+	"status":      "",
+	"priority":    "",
+	"tags":        []any{},
+	"due_date":    "",
+	"recurrence":  "",
+}
+
+// readOnlyFields are the task fields maintained by the service.
+var readOnlyFields = []string{"id", "blocked_by", "version", "created_at", "updated_at", "deleted_at"}
+
+// taskDocument returns a task as a decoded JSON object, the document a patch
+// is applied to.
+func taskDocument(task *domain.Task) (map[string]any, error) {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	for field, empty := range writableFields {
+		if _, ok := doc[field]; !ok {
+			doc[field] = empty
+		}
+	}
+	return doc, nil
+}
+
+// applyPatch applies the request body to doc according to its content type.
+func applyPatch(c *fiber.Ctx, doc map[string]any) (any, error) {
+	mediaType, _, _ := mime.ParseMediaType(c.Get(fiber.HeaderContentType))
+	switch mediaType {
+	case MIMEMergePatch:
+		var patch any
+		if err := json.Unmarshal(c.Body(), &patch); err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "invalid JSON body")
+		}
+		return jsonpatch.MergePatch(doc, patch), nil
+
+	case MIMEJSONPatch:
+		var ops []jsonpatch.Operation
+		if err := json.Unmarshal(c.Body(), &ops); err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "invalid JSON Patch document, expected an array of operations")
+		}
+		patched, err := jsonpatch.Apply(doc, ops)
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			return nil, fiber.NewError(fiber.StatusConflict, err.Error())
+		}
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		return patched, nil
+
+	default:
+		return nil, fiber.NewError(fiber.StatusUnsupportedMediaType,
+			fmt.Sprintf("unsupported Content-Type, expected %s or %s", MIMEMergePatch, MIMEJSONPatch))
+	}
+}
+
+// patchedInput checks that a patch left the read-only fields of doc alone
+// and converts the patched document to service input.
+func patchedInput(doc map[string]any, patched any) (domain.CreateTaskInput, error) {
+	obj, ok := patched.(map[string]any)
+	if !ok {
+		return domain.CreateTaskInput{}, fiber.NewError(fiber.StatusBadRequest, "patched task must be a JSON object")
+	}
+
+	writable := make(map[string]any, len(writableFields))
+	for field, value := range obj {
+		if _, ok := writableFields[field]; ok {
+			writable[field] = value
+		} else if !slices.Contains(readOnlyFields, field) {
+			return domain.CreateTaskInput{}, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("unknown field %q", field))
+		}
+	}
+	for _, field := range readOnlyFields {
+		if !reflect.DeepEqual(obj[field], doc[field]) {
+			return domain.CreateTaskInput{}, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("%s is read-only", field))
+		}
+	}
+
+	data, err := json.Marshal(writable)
+	if err != nil {
+		return domain.CreateTaskInput{}, err
+	}
+	var req createTaskRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return domain.CreateTaskInput{}, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("invalid value for %s", typeErr.Field))
+		}
+		return domain.CreateTaskInput{}, fiber.NewError(fiber.StatusBadRequest, "invalid patched task")
+	}
+	return req.input()
+}
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/gofiber/fiber/v2"
+)
+
+// MIMEProblem is the media type of every error response (RFC 7807).
+const MIMEProblem = "application/problem+json"
+
+// ProblemTypePrefix is prefixed to an error code to form its problem type.
+const ProblemTypePrefix = "urn:task-api:problem:"
+
+// problem is the body of an error response. Code, Errors and Position are
+// extension members: the stable error code, the invalid fields, and for
+// filter errors the offending position in the filter.
+type problem struct {
+	Type     string                 `json:"type"`
+	Title    string                 `json:"title"`
+	Status   int                    `json:"status"`
+	Detail   string                 `json:"detail"`
+	Instance string                 `json:"instance"`
+	Code     string                 `json:"code"`
+	Errors   []pkgerrors.FieldError `json:"errors"`
+	Position *int                   `json:"position,omitempty"`
+}
+
+// newProblem describes err. Application and Fiber errors keep their status
+// and message; anything else is reported as an internal error. Filter errors
+// also report the invalid filter and the position of the problem.
+func newProblem(c *fiber.Ctx, err error) *problem {
+	status, code, detail := fiber.StatusInternalServerError, "", "internal error"
+	fields := []pkgerrors.FieldError{}
+
+	var appErr *pkgerrors.AppError
+	var fiberErr *fiber.Error
+	switch {
+	case errors.As(err, &appErr):
+		status, code, detail = appErr.Status(), appErr.Code, appErr.Message
+		if appErr.Fields != nil {
+			fields = appErr.Fields
+		}
+	case errors.As(err, &fiberErr):
+		status, detail = fiberErr.Code, fiberErr.Message
+	}
+	if code == "" {
+		code = statusCode(status)
+	}
+
+	p := &problem{
+		Type:     ProblemTypePrefix + code,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: c.OriginalURL(),
+		Code:     code,
+		Errors:   fields,
+	}
+
+	var filterErr *domain.FilterError
+	if errors.As(err, &filterErr) {
+		p.Errors = []pkgerrors.FieldError{{Field: "filter", Code: code, Message: detail}}
+		p.Position = &filterErr.Pos
+	}
+	return p
+}
+
+// withStatus returns a copy of p reported under another status.
+func (p problem) withStatus(status int) *problem {
+	p.Status = status
+	p.Title = http.StatusText(status)
+	return &p
+}
+
+// statusCode names a status for errors that carry no code of their own,
+// e.g. "precondition_failed".
+func statusCode(status int) string {
+	return strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+}
+
+// writeProblem sends p as the response.
+func writeProblem(c *fiber.Ctx, p *problem) error {
+	return c.Status(p.Status).JSON(p, MIMEProblem)
+}
+
+// errorHandler renders every error returned by a handler as a problem. Server
+// errors are logged along with their cause, which clients do not see.
+func errorHandler(c *fiber.Ctx, err error) error {
+	p := newProblem(c, err)
+	logServerError(c, p, err)
+	return writeProblem(c, p)
+}
+
+// logServerError logs err if it was reported to the client as p, a server
+// error.
+func logServerError(c *fiber.Ctx, p *problem, err error) {
+	if p.Status >= fiber.StatusInternalServerError {
+		log.Printf("%s %s: %d %s: %s", c.Method(), c.OriginalURL(), p.Status, p.Code, describe(err))
+	}
+}
+
+// describe spells out err along with the causes wrapped by application
+// errors, whose messages leave them out.
+func describe(err error) string {
+	msg := err.Error()
+	for {
+		var appErr *pkgerrors.AppError
+		if !errors.As(err, &appErr) || appErr.Err == nil {
+			return msg
+		}
+		err = appErr.Err
+		msg += ": " + err.Error()
+	}
+}
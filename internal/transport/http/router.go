@@ -1,26 +1,45 @@
 package http
 
 import (
+	"github.com/gauravpandey771/task-api/internal/domain"
 	"github.com/gofiber/fiber/v2"
 )
 
-// NewApp creates and configures a new Fiber application.
-func NewApp(handler *TaskHandler) *fiber.App {
+// HeaderActor names the request header that identifies who made a change.
+// It is recorded in task history.
+const HeaderActor = "X-Actor"
+
+// NewApp creates and configures a new Fiber application. The handler is
+// served as API version 1 under /v1, and at the unprefixed paths it was
+// first served at, which are deprecated.
+func NewApp(handler *TaskHandler, opts ...AppOption) *fiber.App {
+	cfg := appConfig{
+		versions: []Version{{Name: CurrentVersion, Routes: handler}},
+		sunset:   DefaultLegacySunset,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	app := fiber.New(fiber.Config{
-		ErrorHandler: func(c *fiber.Ctx, err error) error {
-			if e, ok := err.(*fiber.Error); ok {
-				return c.Status(e.Code).JSON(fiber.Map{
-					"error": e.Message,
-				})
-			}
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "internal error",
-			})
-		},
+		ErrorHandler: errorHandler,
 	})
 
-	// Register API routes directly (routes use /tasks path)
-	handler.RegisterRoutes(app)
+	// Attribute every change to the requesting actor
+	app.Use(func(c *fiber.Ctx) error {
+		if actor := c.Get(HeaderActor); actor != "" {
+			c.SetUserContext(domain.WithActor(c.UserContext(), actor))
+		}
+		return c.Next()
+	})
+
+	// Register each API version under its own prefix, e.g. /v1/tasks
+	for _, v := range cfg.versions {
+		v.Routes.RegisterRoutes(versioned(app, mount{prefix: "/" + v.Name}))
+	}
+
+	// Keep the unprefixed routes as deprecated aliases of v1
+	handler.RegisterRoutes(versioned(app, mount{deprecated: true}, deprecated(cfg.sunset, cfg.enforceSunset)))
 
 	return app
 }
@@ -1,6 +1,13 @@
 package http
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gauravpandey771/task-api/internal/domain"
@@ -10,76 +17,156 @@ import (
 
 // TaskHandler handles HTTP requests for tasks.
 type TaskHandler struct {
-	service domain.TaskService
+	service     domain.TaskService
+	idempotency *idempotencyStore // nil when Idempotency-Key is ignored
+	workflow    *domain.Workflow  // statuses documented in the OpenAPI document
+	schemas     requestSchemas
+	docs        sync.Map // mount -> OpenAPI document
 }
 
 // Request/Response DTOs
 type createTaskRequest struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Status      string `json:"status"`
-	DueDate     string `json:"due_date"` // ISO8601 format
+	ParentID    string            `json:"parent_id"`
+	Title       string            `json:"title" openapi:"required"`
+	Description string            `json:"description"`
+	Status      domain.TaskStatus `json:"status"`
+	Priority    domain.Priority   `json:"priority"`
+	Tags        []string          `json:"tags"`
+	DueDate     string            `json:"due_date" openapi:"required,format=date-time"` // ISO8601 format
+	Recurrence  string            `json:"recurrence"`
 }
 
-type updateTaskRequest struct {
-	Title       *string `json:"title"`
-	Description *string `json:"description"`
-	Status      *string `json:"status"`
-	DueDate     *string `json:"due_date"` // ISO8601 format
+// input converts the request to service input. Empty status and priority
+// take their defaults.
+func (r createTaskRequest) input() (domain.CreateTaskInput, error) {
+	input := domain.CreateTaskInput{
+		ParentID:    r.ParentID,
+		Title:       r.Title,
+		Description: r.Description,
+		Tags:        r.Tags,
+		Recurrence:  r.Recurrence,
+	}
+
+	// Parse status if provided
+	if r.Status != "" {
+		s := r.Status
+		input.Status = &s
+	}
+
+	// Parse priority if provided
+	if r.Priority != "" {
+		p := r.Priority
+		input.Priority = &p
+	}
+
+	// Parse due date if provided
+	if r.DueDate != "" {
+		due, err := time.Parse(time.RFC3339, r.DueDate)
+		if err != nil {
+			return input, invalidDueDate()
+		}
+		input.DueDate = due
+	}
+
+	return input, nil
+}
+
+type addDependencyRequest struct {
+	BlockerID string `json:"blocker_id" openapi:"required"`
+}
+
+type renameTagRequest struct {
+	From string `json:"from" openapi:"required"`
+	To   string `json:"to" openapi:"required"`
+}
+
+type renameTagResponse struct {
+	Updated int `json:"updated"` // number of tasks changed
+}
+
+type occurrencesResponse struct {
+	Occurrences []time.Time `json:"occurrences"`
+}
+
+// HandlerOption configures optional behaviour of a TaskHandler.
+type HandlerOption func(*TaskHandler)
+
+// WithIdempotencyTTL sets how long responses to requests carrying an
+// Idempotency-Key are kept for replay, DefaultIdempotencyTTL by default. A
+// zero TTL disables Idempotency-Key support.
+func WithIdempotencyTTL(ttl time.Duration) HandlerOption {
+	return func(h *TaskHandler) {
+		h.idempotency = nil
+		if ttl > 0 {
+			h.idempotency = newIdempotencyStore(ttl)
+		}
+	}
+}
+
+// WithWorkflow sets the workflow whose statuses the API documents. It should
+// be the workflow the service enforces, domain.DefaultWorkflow by default.
+func WithWorkflow(w *domain.Workflow) HandlerOption {
+	return func(h *TaskHandler) {
+		h.workflow = w
+	}
 }
 
 // NewTaskHandler creates a new TaskHandler.
-func NewTaskHandler(service domain.TaskService) *TaskHandler {
-	return &TaskHandler{service: service}
+func NewTaskHandler(service domain.TaskService, opts ...HandlerOption) *TaskHandler {
+	h := &TaskHandler{
+		service:     service,
+		idempotency: newIdempotencyStore(DefaultIdempotencyTTL),
+		workflow:    domain.DefaultWorkflow(),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // RegisterRoutes registers all task routes with a Fiber router.
 func (h *TaskHandler) RegisterRoutes(r fiber.Router) {
-	r.Post("/tasks", h.CreateTask)
+	r.Post("/tasks", h.validateBody, h.idempotent, h.CreateTask)
+	r.Post("/tasks\\:batch", h.validateBody, h.Batch)
+	r.Get("/tasks/trash", h.ListTrash)
+	r.Get("/tasks/search", h.SearchTasks)
 	r.Get("/tasks/:id", h.GetTask)
-	r.Put("/tasks/:id", h.UpdateTask)
+	r.Put("/tasks/:id", h.validateBody, h.ReplaceTask)
+	r.Patch("/tasks/:id", h.validateBody, h.PatchTask)
 	r.Delete("/tasks/:id", h.DeleteTask)
+	r.Post("/tasks/:id/restore", h.RestoreTask)
+	r.Delete("/tasks/:id/purge", h.PurgeTask)
+	r.Get("/tasks/:id/children", h.ListChildren)
+	r.Post("/tasks/:id/dependencies", h.validateBody, h.AddDependency)
+	r.Delete("/tasks/:id/dependencies/:blockerId", h.RemoveDependency)
+	r.Get("/tasks/:id/graph", h.DependencyGraph)
+	r.Get("/tasks/:id/occurrences", h.PreviewOccurrences)
+	r.Get("/tasks/:id/history", h.GetHistory)
 	r.Get("/tasks", h.ListTasks)
+	r.Get("/tags", h.ListTags)
+	r.Post("/tags/rename", h.validateBody, h.RenameTag)
+	r.Get("/openapi.json", h.OpenAPI)
+	r.Get("/docs", Docs)
 }
 
 // CreateTask handles POST /tasks
 func (h *TaskHandler) CreateTask(c *fiber.Ctx) error {
 	var req createTaskRequest
-	if err := c.BodyParser(&req); err != nil {
+	if err := json.Unmarshal(c.Body(), &req); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON body")
 	}
-
-	// Parse status if provided
-	var statusPtr *domain.TaskStatus
-	if req.Status != "" {
-		s := domain.TaskStatus(req.Status)
-		statusPtr = &s
-	}
-
-	// Parse due date if provided
-	var due time.Time
-	var err error
-	if req.DueDate != "" {
-		due, err = time.Parse(time.RFC3339, req.DueDate)
-		if err != nil {
-			return fiber.NewError(fiber.StatusBadRequest, "invalid due_date format, expected RFC3339")
-		}
+	input, err := req.input()
+	if err != nil {
+		return err
 	}
 
 	// Create task via service
-	task, err := h.service.CreateTask(domain.CreateTaskInput{
-		Title:       req.Title,
-		Description: req.Description,
-		Status:      statusPtr,
-		DueDate:     due,
-	})
+	task, err := h.service.CreateTask(c.UserContext(), input)
 	if err != nil {
-		if pkgerrors.IsValidation(err) {
-			return fiber.NewError(fiber.StatusBadRequest, err.Error())
-		}
-		return fiber.NewError(fiber.StatusInternalServerError, "internal error")
+		return err
 	}
 
+	setETag(c, task)
 	return c.Status(fiber.StatusCreated).JSON(task)
 }
 
@@ -87,85 +174,120 @@ func (h *TaskHandler) CreateTask(c *fiber.Ctx) error {
 func (h *TaskHandler) GetTask(c *fiber.Ctx) error {
 	id := c.Params("id")
 
-	task, err := h.service.GetTask(id)
+	task, err := h.service.GetTask(c.UserContext(), id)
 	if err != nil {
-		if pkgerrors.IsNotFound(err) {
-			return fiber.NewError(fiber.StatusNotFound, "task not found")
-		}
-		return fiber.NewError(fiber.StatusInternalServerError, "internal error")
+		return err
 	}
 
+	setETag(c, task)
 	return c.JSON(task)
 }
 
-// UpdateTask handles PUT /tasks/:id
-func (h *TaskHandler) UpdateTask(c *fiber.Ctx) error {
-	id := c.Params("id")
+// ReplaceTask handles PUT /tasks/:id. The body replaces the whole task:
+// optional fields that are left out are reset to their defaults.
+func (h *TaskHandler) ReplaceTask(c *fiber.Ctx) error {
+	// Parse If-Match precondition if provided
+	expectedVersion, err := h.ifMatchVersion(c)
+	if err != nil {
+		return err
+	}
 
-	var req updateTaskRequest
-	if err := c.BodyParser(&req); err != nil {
+	var req createTaskRequest
+	if err := json.Unmarshal(c.Body(), &req); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON body")
 	}
+	input, err := req.input()
+	if err != nil {
+		return err
+	}
 
-	// Parse status if provided
-	var statusPtr *domain.TaskStatus
-	if req.Status != nil {
-		s := domain.TaskStatus(*req.Status)
-		statusPtr = &s
+	task, err := h.service.ReplaceTask(c.UserContext(), c.Params("id"), domain.ReplaceTaskInput{
+		CreateTaskInput: input,
+		ExpectedVersion: expectedVersion,
+	})
+	if err != nil {
+		return updateError(err, expectedVersion)
 	}
 
-	// Parse due date if provided
-	var duePtr *time.Time
-	if req.DueDate != nil {
-		if *req.DueDate != "" {
-			d, err := time.Parse(time.RFC3339, *req.DueDate)
-			if err != nil {
-				return fiber.NewError(fiber.StatusBadRequest, "invalid due_date format, expected RFC3339")
-			}
-			duePtr = &d
-		}
+	setETag(c, task)
+	return c.JSON(task)
+}
+
+// PatchTask handles PATCH /tasks/:id with either a JSON Merge Patch
+// (application/merge-patch+json) or a JSON Patch (application/json-patch+json)
+// applied to the task as returned by GET. Read-only fields may be tested but
+// not changed.
+func (h *TaskHandler) PatchTask(c *fiber.Ctx) error {
+	// Parse If-Match precondition if provided
+	expectedVersion, err := h.ifMatchVersion(c)
+	if err != nil {
+		return err
+	}
+
+	task, err := h.service.GetTask(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	if expectedVersion != nil && *expectedVersion != task.Version {
+		return pkgerrors.NewPreconditionError(domain.CodeVersionConflict, domain.ErrVersionConflict)
 	}
 
-	// Update task via service
-	task, err := h.service.UpdateTask(id, domain.UpdateTaskInput{
-		Title:       req.Title,
-		Description: req.Description,
-		Status:      statusPtr,
-		DueDate:     duePtr,
+	doc, err := taskDocument(task)
+	if err != nil {
+		return err
+	}
+	patched, err := applyPatch(c, doc)
+	if err != nil {
+		return err
+	}
+	input, err := patchedInput(doc, patched)
+	if err != nil {
+		return err
+	}
+
+	// Replace the version that was patched, so a concurrent write is
+	// reported instead of overwritten.
+	version := task.Version
+	task, err = h.service.ReplaceTask(c.UserContext(), task.ID, domain.ReplaceTaskInput{
+		CreateTaskInput: input,
+		ExpectedVersion: &version,
 	})
 	if err != nil {
-		if pkgerrors.IsValidation(err) {
-			return fiber.NewError(fiber.StatusBadRequest, err.Error())
-		}
-		if pkgerrors.IsNotFound(err) {
-			return fiber.NewError(fiber.StatusNotFound, "task not found")
-		}
-		return fiber.NewError(fiber.StatusInternalServerError, "internal error")
+		return updateError(err, expectedVersion)
 	}
 
+	setETag(c, task)
 	return c.JSON(task)
 }
 
+// updateError maps errors from replacing a task. A stale If-Match is a
+// failed precondition; without one the update lost a race with a concurrent
+// writer and stays a conflict.
+func updateError(err error, expectedVersion *int64) error {
+	if expectedVersion != nil && pkgerrors.HasCode(err, domain.CodeVersionConflict) {
+		return pkgerrors.Wrap(err, pkgerrors.ErrTypePrecondition, domain.CodeVersionConflict, domain.ErrVersionConflict)
+	}
+	return err
+}
+
 // DeleteTask handles DELETE /tasks/:id
 func (h *TaskHandler) DeleteTask(c *fiber.Ctx) error {
 	id := c.Params("id")
 
-	err := h.service.DeleteTask(id)
+	err := h.service.DeleteTask(c.UserContext(), id)
 	if err != nil {
-		if pkgerrors.IsNotFound(err) {
-			return fiber.NewError(fiber.StatusNotFound, "task not found")
-		}
-		return fiber.NewError(fiber.StatusInternalServerError, "internal error")
+		return err
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
-// ListTasks handles GET /tasks with optional filters
+// ListTasks handles GET /tasks with optional filters. Pages are selected
+// with cursor, or with page for backward compatibility.
 func (h *TaskHandler) ListTasks(c *fiber.Ctx) error {
 	// Parse query parameters
 	statusStr := c.Query("status")
-	page := c.QueryInt("page", 1)
+	pageNum := c.QueryInt("page", 1)
 	pageSize := c.QueryInt("page_size", 10)
 
 	// Parse status filter if provided
@@ -175,18 +297,318 @@ func (h *TaskHandler) ListTasks(c *fiber.Ctx) error {
 		statusPtr = &s
 	}
 
+	// Parse sort order, e.g. sort=-updated_at,title
+	sortKeys, err := domain.ParseSort(c.Query("sort"))
+	if err != nil {
+		return err
+	}
+
+	// Parse filter expression, e.g. filter=status in (PENDING,DONE) and title ~ "deploy"
+	expr, err := domain.ParseFilter(c.Query("filter"))
+	if err != nil {
+		return err
+	}
+
 	// List tasks via service
-	tasks, err := h.service.ListTasks(domain.TaskFilter{
-		Status:   statusPtr,
+	page, err := h.service.QueryTasks(c.UserContext(), domain.TaskFilter{
+		ParentID:   c.Query("parent_id"),
+		Status:     statusPtr,
+		Priorities: queryPriorities(c),
+		Tags:       queryList(c, "tag"),
+		TagMatch:   domain.TagMatch(c.Query("tag_match")),
+		Expr:       expr,
+		Sort:       sortKeys,
+		Page:       pageNum,
+		PageSize:   pageSize,
+		Cursor:     c.Query("cursor"),
+	})
+	if err != nil {
+		return err
+	}
+
+	setLinks(c, page.NextCursor)
+	return c.JSON(page)
+}
+
+// SearchTasks handles GET /tasks/search?q=...
+func (h *TaskHandler) SearchTasks(c *fiber.Ctx) error {
+	results, err := h.service.SearchTasks(c.UserContext(), c.Query("q"), c.QueryInt("limit", 0))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(results)
+}
+
+// ListChildren handles GET /tasks/:id/children
+func (h *TaskHandler) ListChildren(c *fiber.Ctx) error {
+	id := c.Params("id")
+	page := c.QueryInt("page", 1)
+	pageSize := c.QueryInt("page_size", 10)
+
+	sortKeys, err := domain.ParseSort(c.Query("sort"))
+	if err != nil {
+		return err
+	}
+
+	tasks, err := h.service.ListChildren(c.UserContext(), id, domain.TaskFilter{
+		Sort:     sortKeys,
 		Page:     page,
 		PageSize: pageSize,
+		Cursor:   c.Query("cursor"),
 	})
 	if err != nil {
-		if pkgerrors.IsValidation(err) {
-			return fiber.NewError(fiber.StatusBadRequest, err.Error())
-		}
-		return fiber.NewError(fiber.StatusInternalServerError, "internal error")
+		return err
 	}
 
+	setLinks(c, tasks.NextCursor)
 	return c.JSON(tasks)
 }
+
+// AddDependency handles POST /tasks/:id/dependencies
+func (h *TaskHandler) AddDependency(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req addDependencyRequest
+	if err := json.Unmarshal(c.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON body")
+	}
+
+	task, err := h.service.AddDependency(c.UserContext(), id, req.BlockerID)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, task)
+	return c.JSON(task)
+}
+
+// RemoveDependency handles DELETE /tasks/:id/dependencies/:blockerId
+func (h *TaskHandler) RemoveDependency(c *fiber.Ctx) error {
+	task, err := h.service.RemoveDependency(c.UserContext(), c.Params("id"), c.Params("blockerId"))
+	if err != nil {
+		return err
+	}
+
+	setETag(c, task)
+	return c.JSON(task)
+}
+
+// DependencyGraph handles GET /tasks/:id/graph
+func (h *TaskHandler) DependencyGraph(c *fiber.Ctx) error {
+	graph, err := h.service.DependencyGraph(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(graph)
+}
+
+// PreviewOccurrences handles GET /tasks/:id/occurrences
+func (h *TaskHandler) PreviewOccurrences(c *fiber.Ctx) error {
+	occurrences, err := h.service.PreviewOccurrences(c.UserContext(), c.Params("id"), c.QueryInt("count", 5))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(occurrencesResponse{Occurrences: occurrences})
+}
+
+// GetHistory handles GET /tasks/:id/history
+func (h *TaskHandler) GetHistory(c *fiber.Ctx) error {
+	entries, err := h.service.GetHistory(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(entries)
+}
+
+// ListTrash handles GET /tasks/trash
+func (h *TaskHandler) ListTrash(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	pageSize := c.QueryInt("page_size", 10)
+
+	sortKeys, err := domain.ParseSort(c.Query("sort"))
+	if err != nil {
+		return err
+	}
+
+	tasks, err := h.service.ListTrash(c.UserContext(), domain.TaskFilter{
+		Sort:     sortKeys,
+		Page:     page,
+		PageSize: pageSize,
+		Cursor:   c.Query("cursor"),
+	})
+	if err != nil {
+		return err
+	}
+
+	setLinks(c, tasks.NextCursor)
+	return c.JSON(tasks)
+}
+
+// RestoreTask handles POST /tasks/:id/restore
+func (h *TaskHandler) RestoreTask(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	task, err := h.service.RestoreTask(c.UserContext(), id)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, task)
+	return c.JSON(task)
+}
+
+// PurgeTask handles DELETE /tasks/:id/purge
+func (h *TaskHandler) PurgeTask(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if err := h.service.PurgeTask(c.UserContext(), id); err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListTags handles GET /tags
+func (h *TaskHandler) ListTags(c *fiber.Ctx) error {
+	tags, err := h.service.ListTags(c.UserContext())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(tags)
+}
+
+// RenameTag handles POST /tags/rename. Renaming onto an existing tag merges
+// the two.
+func (h *TaskHandler) RenameTag(c *fiber.Ctx) error {
+	var req renameTagRequest
+	if err := json.Unmarshal(c.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON body")
+	}
+
+	n, err := h.service.RenameTag(c.UserContext(), req.From, req.To)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(renameTagResponse{Updated: n})
+}
+
+// invalidDueDate reports a due_date that is not RFC3339.
+func invalidDueDate() error {
+	return pkgerrors.NewFieldErrors(pkgerrors.FieldError{
+		Field:   "due_date",
+		Code:    domain.CodeDueDateInvalid,
+		Message: domain.ErrDueDateInvalid,
+	})
+}
+
+// setLinks sets an RFC 8288 Link header pointing at the first page of a
+// listing and, unless this is the last page, the next one. Other query
+// parameters are kept.
+func setLinks(c *fiber.Ctx, nextCursor string) {
+	query, _ := url.ParseQuery(string(c.Request().URI().QueryString()))
+	query.Del("page")
+	query.Del("cursor")
+
+	link := func(rel string) string {
+		target := c.Path()
+		if encoded := query.Encode(); encoded != "" {
+			target += "?" + encoded
+		}
+		return fmt.Sprintf("<%s>; rel=%q", target, rel)
+	}
+
+	links := []string{link("first")}
+	if nextCursor != "" {
+		query.Set("cursor", nextCursor)
+		links = append(links, link("next"))
+	}
+	c.Append(fiber.HeaderLink, links...)
+}
+
+// queryPriorities collects priority filters given either as repeated
+// parameters (priority=HIGH&priority=URGENT) or comma-separated values.
+func queryPriorities(c *fiber.Ctx) []domain.Priority {
+	var out []domain.Priority
+	for _, p := range queryList(c, "priority") {
+		out = append(out, domain.Priority(p))
+	}
+	return out
+}
+
+// queryList collects the values of a query parameter given either repeated
+// (tag=a&tag=b) or comma-separated (tag=a,b).
+func queryList(c *fiber.Ctx, name string) []string {
+	var out []string
+	for _, raw := range c.Context().QueryArgs().PeekMulti(name) {
+		for _, v := range strings.Split(string(raw), ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
+
+// setETag sets the ETag response header from the task version.
+func setETag(c *fiber.Ctx, task *domain.Task) {
+	c.Set(fiber.HeaderETag, `"`+strconv.FormatInt(task.Version, 10)+`"`)
+}
+
+// ifMatchVersion returns the version of task :id that the If-Match header
+// requires, or nil if it requires none. When the header lists several
+// versions the task is read to find which of them, if any, is current.
+func (h *TaskHandler) ifMatchVersion(c *fiber.Ctx) (*int64, error) {
+	versions, err := parseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil || versions == nil {
+		return nil, err
+	}
+	if len(versions) == 1 {
+		return &versions[0], nil
+	}
+
+	if len(versions) > 1 {
+		task, err := h.service.GetTask(c.UserContext(), c.Params("id"))
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(versions, task.Version) {
+			return &task.Version, nil
+		}
+	}
+	return nil, pkgerrors.NewPreconditionError(domain.CodeVersionConflict, domain.ErrVersionConflict)
+}
+
+// parseIfMatch extracts the versions listed in an If-Match header. An empty
+// header or "*" means no version check and returns nil. If-Match uses strong
+// comparison (RFC 9110), so weak tags never match and are left out; a header
+// of only weak tags returns an empty list, which matches no version.
+func parseIfMatch(header string) ([]int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	versions := []int64{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		weak := strings.HasPrefix(tag, "W/")
+		tag = strings.TrimPrefix(tag, "W/")
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "invalid If-Match header")
+		}
+		version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "invalid If-Match header")
+		}
+		if !weak {
+			versions = append(versions, version)
+		}
+	}
+	return versions, nil
+}
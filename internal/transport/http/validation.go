@@ -0,0 +1,173 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/gauravpandey771/task-api/pkg/jsonschema"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Codes of request bodies that do not match the OpenAPI document, by the
+// JSON Schema keyword that failed.
+var schemaCodes = map[string]string{
+	"additionalProperties": "field_unknown",
+	"required":             "field_required",
+	"type":                 "type_invalid",
+	"enum":                 "enum_invalid",
+	"const":                "enum_invalid",
+	"format":               "format_invalid",
+	"minimum":              "range_invalid",
+	"maximum":              "range_invalid",
+	"maxLength":            "length_invalid",
+}
+
+// requestSchemas holds the request body schemas of every operation, keyed
+// by method, OpenAPI path and media type.
+type requestSchemas struct {
+	once      sync.Once
+	bodies    map[string]schema
+	validator *jsonschema.Validator
+}
+
+// bodySchema returns the schema of the request body of key and the
+// validator for it. Schemas are built on first use.
+func (h *TaskHandler) bodySchema(key string) (schema, *jsonschema.Validator) {
+	s := &h.schemas
+	s.once.Do(func() {
+		b := newSchemaBuilder(h.workflow)
+		s.bodies = make(map[string]schema)
+		for route, op := range operations {
+			for mediaType, body := range op.Body {
+				s.bodies[route+" "+mediaType] = b.schema(body, true)
+			}
+		}
+		s.validator = &jsonschema.Validator{Defs: b.defs, RefPrefix: schemaRefPrefix}
+	})
+	return s.bodies[key], s.validator
+}
+
+// domainChecks validate the body of a route as its handler would, without
+// writing anything, keyed by method and OpenAPI path. They let validateBody
+// report the domain's errors along with the schema's.
+var domainChecks = map[string]func(h *TaskHandler, c *fiber.Ctx) error{
+	"POST /tasks":     (*TaskHandler).checkCreate,
+	"PUT /tasks/{id}": (*TaskHandler).checkReplace,
+}
+
+// validateBody is middleware that checks the request body against the
+// schema of the route in the OpenAPI document, rejecting unknown fields,
+// mistyped values, unknown enum values and malformed dates before the
+// handler sees them. Media types the route does not list are answered with
+// 415 Unsupported Media Type.
+//
+// A body that does not match never reaches the handler. Routes with a
+// domain check report its errors too, so every invalid field is listed at
+// once. Nested fields are named by their path, e.g. "operations.0.op".
+func (h *TaskHandler) validateBody(c *fiber.Ctx) error {
+	route := routeKey(c)
+	mediaType, _, _ := mime.ParseMediaType(c.Get(fiber.HeaderContentType))
+	s, validator := h.bodySchema(route + " " + mediaType)
+	if s == nil {
+		accepted := make([]string, 0, len(operations[route].Body))
+		for listed := range operations[route].Body {
+			accepted = append(accepted, listed)
+		}
+		slices.Sort(accepted)
+		return fiber.NewError(fiber.StatusUnsupportedMediaType,
+			"unsupported Content-Type, expected "+strings.Join(accepted, " or "))
+	}
+
+	var doc any
+	if err := json.Unmarshal(c.Body(), &doc); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON body")
+	}
+	violations := validator.Validate(s, doc)
+	if len(violations) == 0 {
+		return c.Next()
+	}
+
+	fields := make([]pkgerrors.FieldError, len(violations))
+	for i, v := range violations {
+		field := fieldName(v.Pointer)
+		name := field
+		if name == "" {
+			name = "body"
+		}
+		fields[i] = pkgerrors.FieldError{Field: field, Code: schemaCodes[v.Keyword], Message: name + " " + v.Message}
+	}
+
+	var err error
+	if check, ok := domainChecks[route]; ok {
+		err = check(h, c)
+	}
+	return mergeFieldErrors(err, fields)
+}
+
+// checkCreate validates a POST /tasks body with the service.
+func (h *TaskHandler) checkCreate(c *fiber.Ctx) error {
+	var req createTaskRequest
+	if err := json.Unmarshal(c.Body(), &req); err != nil {
+		return nil // mistyped fields are the schema's to report
+	}
+	input, err := req.input()
+	if err != nil {
+		return err
+	}
+	return h.service.ValidateCreate(c.UserContext(), input)
+}
+
+// checkReplace validates a PUT /tasks/:id body with the service.
+func (h *TaskHandler) checkReplace(c *fiber.Ctx) error {
+	var req createTaskRequest
+	if err := json.Unmarshal(c.Body(), &req); err != nil {
+		return nil // mistyped fields are the schema's to report
+	}
+	input, err := req.input()
+	if err != nil {
+		return err
+	}
+	return h.service.ValidateReplace(c.UserContext(), c.Params("id"), domain.ReplaceTaskInput{CreateTaskInput: input})
+}
+
+// mergeFieldErrors adds schema violations to the field errors of err, the
+// error of a domain check. Fields the domain reported keep its more
+// specific error; its other errors are dropped.
+func mergeFieldErrors(err error, violations []pkgerrors.FieldError) error {
+	var fields []pkgerrors.FieldError
+	var appErr *pkgerrors.AppError
+	if errors.As(err, &appErr) {
+		fields = append(fields, appErr.Fields...)
+	}
+
+	reported := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		reported[f.Field] = true
+	}
+	for _, v := range violations {
+		if !reported[v.Field] {
+			fields = append(fields, v)
+		}
+	}
+	return pkgerrors.NewFieldErrors(fields...)
+}
+
+// fieldName names the value a JSON pointer locates the way field errors
+// do: "title" at the top level and "operations.0.op" below it. The body
+// itself has no name.
+func fieldName(pointer string) string {
+	if pointer == "" {
+		return ""
+	}
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return strings.Join(tokens, ".")
+}
@@ -0,0 +1,172 @@
+package http
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CurrentVersion is the API version the unprefixed legacy routes alias.
+const CurrentVersion = "v1"
+
+// Headers of deprecated routes (RFC 9745, RFC 8594).
+const (
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+)
+
+// LegacyDeprecatedAt is when the unprefixed routes were deprecated in
+// favour of /v1.
+var LegacyDeprecatedAt = time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+
+// DefaultLegacySunset is the sunset date announced for the unprefixed
+// routes, after which they may be removed. It is only advertised: the
+// routes keep being served unless WithLegacySunset is used.
+var DefaultLegacySunset = LegacyDeprecatedAt.AddDate(0, 6, 0)
+
+// Routes is a set of handlers that can be served as an API version.
+// TaskHandler serves v1; later versions can share its services.
+type Routes interface {
+	RegisterRoutes(r fiber.Router)
+}
+
+// Version is a set of routes served under /<Name>, e.g. /v2.
+type Version struct {
+	Name   string
+	Routes Routes
+}
+
+// AppOption configures optional behaviour of NewApp.
+type AppOption func(*appConfig)
+
+type appConfig struct {
+	versions      []Version
+	sunset        time.Time
+	enforceSunset bool // answer 410 Gone once sunset has passed
+}
+
+// WithVersion serves another API version alongside v1.
+func WithVersion(name string, routes Routes) AppOption {
+	return func(cfg *appConfig) {
+		cfg.versions = append(cfg.versions, Version{Name: name, Routes: routes})
+	}
+}
+
+// WithLegacySunset sets when the unprefixed routes stop being served,
+// instead of DefaultLegacySunset. From then on they answer 410 Gone.
+func WithLegacySunset(t time.Time) AppOption {
+	return func(cfg *appConfig) {
+		cfg.sunset = t
+		cfg.enforceSunset = true
+	}
+}
+
+// mount describes where a set of routes is served. It is stored in the
+// request locals of every route.
+type mount struct {
+	prefix     string // e.g. "/v1", or "" for the legacy routes
+	deprecated bool
+}
+
+const localsMount = "mount"
+
+// mountOf returns where the route of c is served.
+func mountOf(c *fiber.Ctx) mount {
+	m, _ := c.Locals(localsMount).(mount)
+	return m
+}
+
+// versioned returns a router registering routes under m.prefix, each led by
+// handlers that run before the route's own.
+func versioned(r fiber.Router, m mount, handlers ...fiber.Handler) fiber.Router {
+	if m.prefix != "" {
+		r = r.Group(m.prefix)
+	}
+	handlers = append([]fiber.Handler{func(c *fiber.Ctx) error {
+		c.Locals(localsMount, m)
+		return c.Next()
+	}}, handlers...)
+	return &mountedRouter{Router: r, handlers: handlers}
+}
+
+// deprecated is middleware marking a legacy route as deprecated, pointing at
+// its successor under the current version. The successor link follows any
+// links the handler sets. If enforce is set, once sunset has passed the
+// route is no longer served and answers 410 Gone.
+func deprecated(sunset time.Time, enforce bool) fiber.Handler {
+	deprecation := "@" + strconv.FormatInt(LegacyDeprecatedAt.Unix(), 10)
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+	return func(c *fiber.Ctx) error {
+		c.Set(HeaderDeprecation, deprecation)
+		c.Set(HeaderSunset, sunsetDate)
+		successor := `</` + CurrentVersion + c.Path() + `>; rel="successor-version"`
+		if enforce && !time.Now().Before(sunset) {
+			c.Append(fiber.HeaderLink, successor)
+			return fiber.NewError(fiber.StatusGone, "this route was removed on "+sunsetDate+", use /"+CurrentVersion+c.Path())
+		}
+		err := c.Next()
+		c.Append(fiber.HeaderLink, successor)
+		return err
+	}
+}
+
+// mountedRouter prepends handlers to every route registered through it.
+// Route groups share a single Use middleware across every path under their
+// prefix, so an unprefixed legacy group would also run for /v1; per-route
+// handlers do not.
+type mountedRouter struct {
+	fiber.Router
+	handlers []fiber.Handler
+}
+
+func (r *mountedRouter) with(handlers []fiber.Handler) []fiber.Handler {
+	return append(slices.Clone(r.handlers), handlers...)
+}
+
+func (r *mountedRouter) Get(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Get(path, r.with(handlers)...)
+	return r
+}
+
+func (r *mountedRouter) Head(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Head(path, r.with(handlers)...)
+	return r
+}
+
+func (r *mountedRouter) Post(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Post(path, r.with(handlers)...)
+	return r
+}
+
+func (r *mountedRouter) Put(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Put(path, r.with(handlers)...)
+	return r
+}
+
+func (r *mountedRouter) Patch(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Patch(path, r.with(handlers)...)
+	return r
+}
+
+func (r *mountedRouter) Delete(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Delete(path, r.with(handlers)...)
+	return r
+}
+
+func (r *mountedRouter) Options(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Options(path, r.with(handlers)...)
+	return r
+}
+
+func (r *mountedRouter) All(path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.All(path, r.with(handlers)...)
+	return r
+}
+
+func (r *mountedRouter) Add(method, path string, handlers ...fiber.Handler) fiber.Router {
+	r.Router.Add(method, path, r.with(handlers)...)
+	return r
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
+	"time"
 
 	"github.com/gauravpandey771/task-api/internal/domain"
 	"github.com/gauravpandey771/task-api/internal/repository"
@@ -9,19 +12,108 @@ import (
 )
 
 func main() {
-	// Initialize repository (in-memory)
-	repo := repository.NewInMemoryTaskRepository()
+	store := flag.String("store", "memory", "storage backend: memory, sqlite or file")
+	dbPath := flag.String("db", "tasks.db", "SQLite database path (used with -store=sqlite)")
+	dataDir := flag.String("data-dir", "data", "log and snapshot directory (used with -store=file)")
+	compactEvery := flag.Duration("compact-interval", 5*time.Minute, "log compaction interval (used with -store=file, 0 disables)")
+	trashRetention := flag.Duration("trash-retention", 30*24*time.Hour, "how long deleted tasks stay in the trash")
+	purgeEvery := flag.Duration("purge-interval", time.Hour, "how often expired trash is purged (0 disables)")
+	completion := flag.String("completion", string(domain.CompletionBlock), "parent completion rule: block or auto")
+	deletePolicy := flag.String("delete-children", string(domain.DeleteOrphan), "what deleting a parent does to its children: orphan or cascade")
+	workflowPath := flag.String("workflow", "", "JSON status workflow config (default: built-in PENDING/IN_PROGRESS/DONE workflow)")
+	idempotencyTTL := flag.Duration("idempotency-ttl", httphandler.DefaultIdempotencyTTL, "how long responses to requests with an Idempotency-Key are replayed (0 disables)")
+	flag.Parse()
+
+	hierarchy := domain.HierarchyPolicy{
+		Completion: domain.CompletionRule(*completion),
+		Delete:     domain.DeletePolicy(*deletePolicy),
+	}
+	switch hierarchy.Completion {
+	case domain.CompletionBlock, domain.CompletionAuto:
+	default:
+		log.Fatalf("unknown completion rule %q, expected block or auto", *completion)
+	}
+	switch hierarchy.Delete {
+	case domain.DeleteOrphan, domain.DeleteCascade:
+	default:
+		log.Fatalf("unknown delete policy %q, expected orphan or cascade", *deletePolicy)
+	}
+
+	// Initialize repository
+	var repo domain.TaskRepository
+	var history domain.HistoryRepository
+	switch *store {
+	case "memory":
+		repo = repository.NewInMemoryTaskRepository()
+		history = repository.NewInMemoryHistoryRepository()
+	case "sqlite":
+		sqliteRepo, err := repository.NewSQLiteTaskRepository(*dbPath)
+		if err != nil {
+			log.Fatalf("failed to open sqlite database: %v", err)
+		}
+		defer sqliteRepo.Close()
+		repo = sqliteRepo
+		sqliteHistory, err := sqliteRepo.History()
+		if err != nil {
+			log.Fatalf("failed to open task history: %v", err)
+		}
+		history = sqliteHistory
+	case "file":
+		fileRepo, err := repository.NewFileTaskRepository(*dataDir, *compactEvery)
+		if err != nil {
+			log.Fatalf("failed to open task log: %v", err)
+		}
+		defer fileRepo.Close()
+		repo = fileRepo
+		fileHistory, err := repository.NewFileHistoryRepository(*dataDir)
+		if err != nil {
+			log.Fatalf("failed to open task history: %v", err)
+		}
+		defer fileHistory.Close()
+		history = fileHistory
+	default:
+		log.Fatalf("unknown store %q, expected memory, sqlite or file", *store)
+	}
+
+	// Keep a full-text index of the tasks in step with every write
+	indexed, err := repository.NewIndexedTaskRepository(context.Background(), repo)
+	if err != nil {
+		log.Fatalf("failed to build search index: %v", err)
+	}
+	repo = indexed
+
+	workflow := domain.DefaultWorkflow()
+	if *workflowPath != "" {
+		w, err := domain.LoadWorkflow(*workflowPath)
+		if err != nil {
+			log.Fatalf("failed to load workflow: %v", err)
+		}
+		workflow = w
+	}
 
 	// Initialize service
-	service := domain.NewTaskService(repo)
+	service := domain.NewTaskService(repo,
+		domain.WithHierarchyPolicy(hierarchy),
+		domain.WithWorkflow(workflow),
+		domain.WithHistory(history),
+		domain.WithSearch(indexed),
+	)
+
+	// Purge expired trash in the background
+	if *purgeEvery > 0 {
+		go domain.RunTrashPurger(context.Background(), service, *trashRetention, *purgeEvery)
+	}
 
 	// Initialize HTTP handler
-	handler := httphandler.NewTaskHandler(service)
+	handler := httphandler.NewTaskHandler(service,
+		httphandler.WithIdempotencyTTL(*idempotencyTTL),
+		httphandler.WithWorkflow(workflow),
+	)
 
 	// Create and start Fiber app
 	app := httphandler.NewApp(handler)
 
-	log.Println("Starting Task Management API on :8080...")
+	log.Printf("Starting Task Management API on :8080 (store=%s)...", *store)
 	if err := app.Listen(":8080"); err != nil {
 		log.Fatalf("failed to start server: %v", err)
 	}
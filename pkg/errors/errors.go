@@ -1,16 +1,91 @@
 package errors
 
-import "errors"
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrTypeValidation   = "validation"
+	ErrTypeNotFound     = "not_found"
+	ErrTypeConflict     = "conflict"
+	ErrTypePrecondition = "precondition_failed"
+	ErrTypeUnauthorized = "unauthorized"
+	ErrTypeForbidden    = "forbidden"
+	ErrTypeRateLimited  = "rate_limited"
+	ErrTypeUnavailable  = "unavailable"
+	ErrTypeInternal     = "internal"
+)
+
+// Sentinels for matching errors by type with errors.Is.
+var (
+	ErrValidation   = &AppError{Type: ErrTypeValidation}
+	ErrNotFound     = &AppError{Type: ErrTypeNotFound}
+	ErrConflict     = &AppError{Type: ErrTypeConflict}
+	ErrPrecondition = &AppError{Type: ErrTypePrecondition}
+	ErrUnauthorized = &AppError{Type: ErrTypeUnauthorized}
+	ErrForbidden    = &AppError{Type: ErrTypeForbidden}
+	ErrRateLimited  = &AppError{Type: ErrTypeRateLimited}
+	ErrUnavailable  = &AppError{Type: ErrTypeUnavailable}
+	ErrInternal     = &AppError{Type: ErrTypeInternal}
+)
 
+// CodeValidationFailed is the code of a validation error with several
+// invalid fields.
+const CodeValidationFailed = "validation_failed"
+
+// statuses maps error types to the HTTP status they are reported with.
 var (
-	ErrTypeValidation = "validation"
-	ErrTypeNotFound   = "not_found"
+	statusMu sync.RWMutex
+	statuses = map[string]int{
+		ErrTypeValidation:   http.StatusBadRequest,
+		ErrTypeNotFound:     http.StatusNotFound,
+		ErrTypeConflict:     http.StatusConflict,
+		ErrTypePrecondition: http.StatusPreconditionFailed,
+		ErrTypeUnauthorized: http.StatusUnauthorized,
+		ErrTypeForbidden:    http.StatusForbidden,
+		ErrTypeRateLimited:  http.StatusTooManyRequests,
+		ErrTypeUnavailable:  http.StatusServiceUnavailable,
+		ErrTypeInternal:     http.StatusInternalServerError,
+	}
 )
 
-// AppError is a custom error type with a type field.
+// Register maps an error type to the HTTP status it is reported with,
+// replacing any earlier mapping.
+func Register(errType string, status int) {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	statuses[errType] = status
+}
+
+// StatusOf returns the HTTP status of the first AppError in err's chain, or
+// 500 if there is none or its type is not registered.
+func StatusOf(err error) int {
+	var appErr *AppError
+	if !errors.As(err, &appErr) {
+		return http.StatusInternalServerError
+	}
+	return appErr.Status()
+}
+
+// AppError is a custom error type with a type field. Code is a stable,
+// machine-readable identifier for the error, Fields lists the invalid
+// fields of a validation error, and Err is the underlying cause, if any.
 type AppError struct {
 	Type    string
+	Code    string
 	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+// FieldError is a single invalid field, named by its JSON name.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
 
 // Error implements the error interface.
@@ -18,30 +93,119 @@ func (e *AppError) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the underlying cause.
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether e matches target: an AppError of the same type and,
+// if target has a code, the same code.
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	if !ok {
+		return false
+	}
+	return e.Type == t.Type && (t.Code == "" || e.Code == t.Code)
+}
+
+// Status returns the HTTP status code registered for the error type.
+func (e *AppError) Status() int {
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+	if status, ok := statuses[e.Type]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
 // NewValidationError creates a validation error.
-func NewValidationError(msg string) error {
-	return &AppError{Type: ErrTypeValidation, Message: msg}
+func NewValidationError(code, msg string) error {
+	return &AppError{Type: ErrTypeValidation, Code: code, Message: msg}
+}
+
+// NewFieldErrors creates a validation error for one or more invalid fields.
+// Its message joins theirs, and a single field lends the error its code.
+func NewFieldErrors(fields ...FieldError) error {
+	messages := make([]string, len(fields))
+	for i, f := range fields {
+		messages[i] = f.Message
+	}
+	code := CodeValidationFailed
+	if len(fields) == 1 {
+		code = fields[0].Code
+	}
+	return &AppError{Type: ErrTypeValidation, Code: code, Message: strings.Join(messages, "; "), Fields: fields}
 }
 
 // NewNotFoundError creates a not found error.
-func NewNotFoundError(msg string) error {
-	return &AppError{Type: ErrTypeNotFound, Message: msg}
+func NewNotFoundError(code, msg string) error {
+	return &AppError{Type: ErrTypeNotFound, Code: code, Message: msg}
 }
 
-// IsValidation checks if an error is a validation error.
-func IsValidation(err error) bool {
-	var appErr *AppError
-	if errors.As(err, &appErr) {
-		return appErr.Type == ErrTypeValidation
+// NewConflictError creates a conflict error.
+func NewConflictError(code, msg string) error {
+	return &AppError{Type: ErrTypeConflict, Code: code, Message: msg}
+}
+
+// NewPreconditionError creates an error for a failed request precondition.
+func NewPreconditionError(code, msg string) error {
+	return &AppError{Type: ErrTypePrecondition, Code: code, Message: msg}
+}
+
+// NewUnauthorizedError creates an error for a missing or invalid identity.
+func NewUnauthorizedError(code, msg string) error {
+	return &AppError{Type: ErrTypeUnauthorized, Code: code, Message: msg}
+}
+
+// NewForbiddenError creates an error for a caller that may not do something.
+func NewForbiddenError(code, msg string) error {
+	return &AppError{Type: ErrTypeForbidden, Code: code, Message: msg}
+}
+
+// NewRateLimitedError creates an error for a caller that sent too many
+// requests.
+func NewRateLimitedError(code, msg string) error {
+	return &AppError{Type: ErrTypeRateLimited, Code: code, Message: msg}
+}
+
+// NewUnavailableError creates an error for a dependency that cannot be
+// reached, wrapping its cause.
+func NewUnavailableError(code, msg string, cause error) error {
+	return &AppError{Type: ErrTypeUnavailable, Code: code, Message: msg, Err: cause}
+}
+
+// Wrap creates an error of the given type with err as its cause. The cause
+// is kept for logging and errors.Is; only msg is shown to clients.
+func Wrap(err error, errType, code, msg string) error {
+	return &AppError{Type: errType, Code: code, Message: msg, Err: err}
+}
+
+// HasCode reports whether err's chain holds an AppError with the given code.
+func HasCode(err error, code string) bool {
+	for err != nil {
+		var appErr *AppError
+		if !errors.As(err, &appErr) {
+			return false
+		}
+		if appErr.Code == code {
+			return true
+		}
+		err = appErr.Err
 	}
 	return false
 }
 
+// IsValidation checks if an error is a validation error.
+func IsValidation(err error) bool {
+	return errors.Is(err, ErrValidation)
+}
+
 // IsNotFound checks if an error is a not found error.
 func IsNotFound(err error) bool {
-	var appErr *AppError
-	if errors.As(err, &appErr) {
-		return appErr.Type == ErrTypeNotFound
-	}
-	return false
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsConflict checks if an error is a conflict error.
+func IsConflict(err error) bool {
+	return errors.Is(err, ErrConflict)
 }
@@ -0,0 +1,312 @@
+// Package jsonpatch applies JSON Merge Patch (RFC 7396) and JSON Patch
+// (RFC 6902) documents to JSON values decoded into any.
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrTestFailed is returned, wrapped, when a test operation does not match.
+var ErrTestFailed = errors.New("test failed")
+
+// MergePatch applies a merge patch to target and returns the result. Object
+// members set to null in the patch are removed; any other patch value
+// replaces the target. target is not modified.
+func MergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	out := make(map[string]any)
+	if t, ok := target.(map[string]any); ok {
+		for k, v := range t {
+			out[k] = v
+		}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(out, k)
+		} else {
+			out[k] = MergePatch(out[k], v)
+		}
+	}
+	return out
+}
+
+// Operation is a single JSON Patch operation.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Apply applies operations in order to doc and returns the result. Either
+// every operation applies or an error is returned; doc is not modified.
+func Apply(doc any, ops []Operation) (any, error) {
+	doc = clone(doc)
+	for i, op := range ops {
+		var err error
+		if doc, err = apply(doc, op); err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+// apply runs a single operation.
+func apply(doc any, op Operation) (any, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if len(op.Value) == 0 {
+			return nil, errors.New(`missing "value"`)
+		}
+		var value any
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, fmt.Errorf(`invalid "value": %w`, err)
+		}
+		switch op.Op {
+		case "add":
+			return add(doc, path, value)
+		case "replace":
+			return replace(doc, path, value)
+		default:
+			current, err := get(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, ErrTestFailed
+			}
+			return doc, nil
+		}
+
+	case "remove":
+		doc, _, err := remove(doc, path)
+		return doc, err
+
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, fmt.Errorf(`invalid "from": %w`, err)
+		}
+		var value any
+		if op.Op == "move" {
+			if len(from) < len(path) && slices.Equal(from, path[:len(from)]) {
+				return nil, errors.New("cannot move a value into itself")
+			}
+			if doc, value, err = remove(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			if value, err = get(doc, from); err != nil {
+				return nil, err
+			}
+			value = clone(value)
+		}
+		return add(doc, path, value)
+
+	default:
+		return nil, fmt.Errorf("unknown op %q", op.Op)
+	}
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into unescaped tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid path %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// arrayIndex parses an array index token that must be below limit.
+func arrayIndex(token string, limit int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if i >= limit {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+var errNotFound = errors.New("path not found")
+
+// get returns the value at path.
+func get(node any, path []string) (any, error) {
+	for _, token := range path {
+		switch n := node.(type) {
+		case map[string]any:
+			child, ok := n[token]
+			if !ok {
+				return nil, errNotFound
+			}
+			node = child
+		case []any:
+			i, err := arrayIndex(token, len(n))
+			if err != nil {
+				return nil, err
+			}
+			node = n[i]
+		default:
+			return nil, errNotFound
+		}
+	}
+	return node, nil
+}
+
+// modify descends to the container holding the last token of path and
+// calls leaf on it. It returns node, which leaf may have reallocated.
+func modify(node any, path []string, leaf func(container any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return leaf(node, path[0])
+	}
+
+	switch n := node.(type) {
+	case map[string]any:
+		child, ok := n[path[0]]
+		if !ok {
+			return nil, errNotFound
+		}
+		child, err := modify(child, path[1:], leaf)
+		if err != nil {
+			return nil, err
+		}
+		n[path[0]] = child
+		return n, nil
+	case []any:
+		i, err := arrayIndex(path[0], len(n))
+		if err != nil {
+			return nil, err
+		}
+		child, err := modify(n[i], path[1:], leaf)
+		if err != nil {
+			return nil, err
+		}
+		n[i] = child
+		return n, nil
+	default:
+		return nil, errNotFound
+	}
+}
+
+// add sets an object member or inserts into an array ("-" appends).
+func add(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return modify(doc, path, func(container any, token string) (any, error) {
+		switch n := container.(type) {
+		case map[string]any:
+			n[token] = value
+			return n, nil
+		case []any:
+			if token == "-" {
+				return append(n, value), nil
+			}
+			i, err := arrayIndex(token, len(n)+1)
+			if err != nil {
+				return nil, err
+			}
+			return slices.Insert(n, i, value), nil
+		default:
+			return nil, errNotFound
+		}
+	})
+}
+
+// replace sets an existing object member or array element.
+func replace(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return modify(doc, path, func(container any, token string) (any, error) {
+		switch n := container.(type) {
+		case map[string]any:
+			if _, ok := n[token]; !ok {
+				return nil, errNotFound
+			}
+			n[token] = value
+			return n, nil
+		case []any:
+			i, err := arrayIndex(token, len(n))
+			if err != nil {
+				return nil, err
+			}
+			n[i] = value
+			return n, nil
+		default:
+			return nil, errNotFound
+		}
+	})
+}
+
+// remove deletes an existing object member or array element and returns it.
+func remove(doc any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, errors.New("cannot remove the whole document")
+	}
+	var removed any
+	doc, err := modify(doc, path, func(container any, token string) (any, error) {
+		switch n := container.(type) {
+		case map[string]any:
+			v, ok := n[token]
+			if !ok {
+				return nil, errNotFound
+			}
+			removed = v
+			delete(n, token)
+			return n, nil
+		case []any:
+			i, err := arrayIndex(token, len(n))
+			if err != nil {
+				return nil, err
+			}
+			removed = n[i]
+			return slices.Delete(n, i, i+1), nil
+		default:
+			return nil, errNotFound
+		}
+	})
+	return doc, removed, err
+}
+
+// clone deep-copies a decoded JSON value.
+func clone(v any) any {
+	switch n := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(n))
+		for k, child := range n {
+			out[k] = clone(child)
+		}
+		return out
+	case []any:
+		out := make([]any, len(n))
+		for i, child := range n {
+			out[i] = clone(child)
+		}
+		return out
+	default:
+		return v
+	}
+}
@@ -0,0 +1,264 @@
+// Package jsonschema validates JSON values decoded into any against the
+// subset of JSON Schema used by OpenAPI 3.1 documents: $ref, type, enum,
+// const, format (date-time), properties, required, additionalProperties,
+// items, minimum, maximum, maxLength, allOf and if/then/else.
+package jsonschema
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Error is a value that does not match its schema. Pointer locates the
+// value in the document (RFC 6901) and Keyword names the failed check.
+type Error struct {
+	Pointer string
+	Keyword string
+	Message string
+}
+
+// Error implements the error interface.
+func (e Error) Error() string {
+	return e.Pointer + ": " + e.Message
+}
+
+// Validator validates values against schemas whose $refs point into Defs,
+// e.g. "#/components/schemas/Task" with RefPrefix "#/components/schemas/".
+type Validator struct {
+	Defs      map[string]any
+	RefPrefix string
+}
+
+// Validate returns every mismatch between doc and schema, in document order.
+func (v *Validator) Validate(schema map[string]any, doc any) []Error {
+	var errs []Error
+	v.validate(schema, doc, "", &errs)
+	return errs
+}
+
+func (v *Validator) validate(schema map[string]any, value any, pointer string, errs *[]Error) {
+	fail := func(keyword, format string, args ...any) {
+		*errs = append(*errs, Error{Pointer: pointer, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		target, ok := v.Defs[strings.TrimPrefix(ref, v.RefPrefix)].(map[string]any)
+		if !ok {
+			fail("$ref", "unresolved reference %s", ref)
+			return
+		}
+		v.validate(target, value, pointer, errs)
+	}
+
+	if t, ok := schema["type"]; ok && !hasType(value, t) {
+		fail("type", "must be %s", describeType(t))
+		return
+	}
+
+	if enum, ok := schema["enum"]; ok && !inEnum(value, enum) {
+		fail("enum", "must be one of %s", joinValues(enum))
+	}
+	if c, ok := schema["const"]; ok && fmt.Sprint(c) != fmt.Sprint(value) {
+		fail("const", "must be %v", c)
+	}
+
+	switch value := value.(type) {
+	case string:
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339, value); err != nil {
+				fail("format", "must be an RFC3339 date-time")
+			}
+		}
+		if max, ok := number(schema["maxLength"]); ok && float64(len([]rune(value))) > max {
+			fail("maxLength", "must be at most %v characters", max)
+		}
+
+	case float64:
+		if min, ok := number(schema["minimum"]); ok && value < min {
+			fail("minimum", "must be at least %v", min)
+		}
+		if max, ok := number(schema["maximum"]); ok && value > max {
+			fail("maximum", "must be at most %v", max)
+		}
+
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range value {
+				v.validate(items, item, fmt.Sprintf("%s/%d", pointer, i), errs)
+			}
+		}
+
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		for _, name := range requiredFields(schema) {
+			if _, ok := value[name]; !ok {
+				*errs = append(*errs, Error{Pointer: pointer + "/" + escape(name), Keyword: "required", Message: "is required"})
+			}
+		}
+
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child := pointer + "/" + escape(name)
+			if prop, ok := properties[name].(map[string]any); ok {
+				v.validate(prop, value[name], child, errs)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					*errs = append(*errs, Error{Pointer: child, Keyword: "additionalProperties", Message: "is not a known field"})
+				}
+			case map[string]any:
+				v.validate(additional, value[name], child, errs)
+			}
+		}
+	}
+
+	if all, ok := schema["allOf"].([]any); ok {
+		for _, sub := range all {
+			if sub, ok := sub.(map[string]any); ok {
+				v.validate(sub, value, pointer, errs)
+			}
+		}
+	}
+
+	// The outcome of if only picks between then and else
+	if cond, ok := schema["if"].(map[string]any); ok {
+		var condErrs []Error
+		v.validate(cond, value, pointer, &condErrs)
+		branch := "else"
+		if len(condErrs) == 0 {
+			branch = "then"
+		}
+		if sub, ok := schema[branch].(map[string]any); ok {
+			v.validate(sub, value, pointer, errs)
+		}
+	}
+}
+
+// hasType reports whether value is of type t, a type name or a list of them.
+func hasType(value any, t any) bool {
+	switch t := t.(type) {
+	case string:
+		switch t {
+		case "string":
+			_, ok := value.(string)
+			return ok
+		case "boolean":
+			_, ok := value.(bool)
+			return ok
+		case "number":
+			_, ok := value.(float64)
+			return ok
+		case "integer":
+			n, ok := value.(float64)
+			return ok && n == math.Trunc(n)
+		case "array":
+			_, ok := value.([]any)
+			return ok
+		case "object":
+			_, ok := value.(map[string]any)
+			return ok
+		case "null":
+			return value == nil
+		}
+		return true
+	case []any:
+		return slices.ContainsFunc(t, func(t any) bool { return hasType(value, t) })
+	case []string:
+		return slices.ContainsFunc(t, func(t string) bool { return hasType(value, t) })
+	}
+	return true
+}
+
+func describeType(t any) string {
+	switch t := t.(type) {
+	case string:
+		switch t {
+		case "array", "integer", "object":
+			return "an " + t
+		case "null":
+			return "null"
+		}
+		return "a " + t
+	case []string:
+		names := make([]string, len(t))
+		for i, name := range t {
+			names[i] = describeType(name)
+		}
+		return strings.Join(names, " or ")
+	case []any:
+		names := make([]string, len(t))
+		for i, name := range t {
+			names[i] = describeType(name)
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+// inEnum reports whether value is one of enum. Values are compared by their
+// string form, so enums of named string types match decoded strings.
+func inEnum(value any, enum any) bool {
+	values, ok := enum.([]any)
+	if !ok {
+		return true
+	}
+	for _, v := range values {
+		if fmt.Sprint(v) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinValues(enum any) string {
+	values, _ := enum.([]any)
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = fmt.Sprint(v)
+	}
+	return strings.Join(out, ", ")
+}
+
+func requiredFields(schema map[string]any) []string {
+	switch required := schema["required"].(type) {
+	case []string:
+		return required
+	case []any:
+		out := make([]string, 0, len(required))
+		for _, name := range required {
+			if s, ok := name.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// number converts a numeric schema value to float64.
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// escape escapes a member name for use in a JSON pointer.
+func escape(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
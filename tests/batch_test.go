@@ -0,0 +1,342 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	"github.com/gauravpandey771/task-api/internal/repository"
+	httphandler "github.com/gauravpandey771/task-api/internal/transport/http"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestApplyBatch_BestEffort tests that failing operations leave the others
+// applied
+func TestApplyBatch_BestEffort(t *testing.T) {
+	svc := newTestService()
+	ctx := context.Background()
+	due := time.Now().Add(time.Hour)
+	existing, err := svc.CreateTask(ctx, domain.CreateTaskInput{Title: "Existing", DueDate: due})
+	require.NoError(t, err)
+
+	title := "Renamed"
+	results, err := svc.ApplyBatch(ctx, []domain.BatchOperation{
+		{Op: domain.BatchCreate, Create: domain.CreateTaskInput{Title: "New", DueDate: due}},
+		{Op: domain.BatchCreate, Create: domain.CreateTaskInput{DueDate: due}},
+		{Op: domain.BatchUpdate, ID: existing.ID, Update: domain.UpdateTaskInput{Title: &title}},
+		{Op: domain.BatchDelete, ID: "missing"},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, "New", results[0].Task.Title)
+	assert.True(t, pkgerrors.IsValidation(results[1].Err))
+	assert.NoError(t, results[2].Err)
+	assert.Equal(t, "Renamed", results[2].Task.Title)
+	assert.True(t, pkgerrors.IsNotFound(results[3].Err))
+
+	tasks, err := svc.ListTasks(ctx, domain.TaskFilter{})
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+
+	_, err = svc.ApplyBatch(ctx, nil)
+	assert.True(t, pkgerrors.IsValidation(err))
+	_, err = svc.ApplyBatch(ctx, make([]domain.BatchOperation, domain.MaxBatchSize+1))
+	assert.True(t, pkgerrors.IsValidation(err))
+}
+
+// TestApplyBatchAtomic tests that a failing operation rolls back the whole
+// batch, history included
+func TestApplyBatchAtomic(t *testing.T) {
+	history := repository.NewInMemoryHistoryRepository()
+	svc := domain.NewTaskService(repository.NewInMemoryTaskRepository(), domain.WithHistory(history))
+	ctx := context.Background()
+	due := time.Now().Add(time.Hour)
+	kept, err := svc.CreateTask(ctx, domain.CreateTaskInput{Title: "Kept", DueDate: due})
+	require.NoError(t, err)
+	trashed, err := svc.CreateTask(ctx, domain.CreateTaskInput{Title: "Trashed", DueDate: due})
+	require.NoError(t, err)
+
+	title := "Renamed"
+	results, err := svc.ApplyBatchAtomic(ctx, []domain.BatchOperation{
+		{Op: domain.BatchCreate, Create: domain.CreateTaskInput{Title: "New", DueDate: due}},
+		{Op: domain.BatchUpdate, ID: kept.ID, Update: domain.UpdateTaskInput{Title: &title}},
+		{Op: domain.BatchDelete, ID: trashed.ID},
+		{Op: domain.BatchUpdate, ID: "missing", Update: domain.UpdateTaskInput{Title: &title}},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	for _, r := range results[:3] {
+		assert.Nil(t, r.Task)
+		assert.True(t, pkgerrors.IsConflict(r.Err))
+		assert.Equal(t, domain.ErrBatchAborted, r.Err.Error())
+	}
+	assert.True(t, pkgerrors.IsNotFound(results[3].Err))
+
+	tasks, err := svc.ListTasks(ctx, domain.TaskFilter{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Kept", "Trashed"}, []string{tasks[0].Title, tasks[1].Title})
+	got, err := svc.GetTask(ctx, kept.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), got.Version)
+	entries, err := svc.GetHistory(ctx, kept.ID)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// The same batch without the failing operation applies in full
+	results, err = svc.ApplyBatchAtomic(ctx, []domain.BatchOperation{
+		{Op: domain.BatchCreate, Create: domain.CreateTaskInput{Title: "New", DueDate: due}},
+		{Op: domain.BatchUpdate, ID: kept.ID, Update: domain.UpdateTaskInput{Title: &title}},
+		{Op: domain.BatchDelete, ID: trashed.ID},
+	})
+	require.NoError(t, err)
+	for _, r := range results {
+		assert.NoError(t, r.Err)
+	}
+	tasks, err = svc.ListTasks(ctx, domain.TaskFilter{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"New", "Renamed"}, []string{tasks[0].Title, tasks[1].Title})
+	entries, err = svc.GetHistory(ctx, kept.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, domain.HistoryUpdated, entries[1].Action)
+}
+
+// TestApplyBatchAtomic_Search tests that the search index only sees applied
+// batches
+func TestApplyBatchAtomic_Search(t *testing.T) {
+	svc := newSearchService(t)
+	ctx := context.Background()
+	due := time.Now().Add(time.Hour)
+
+	results, err := svc.ApplyBatchAtomic(ctx, []domain.BatchOperation{
+		{Op: domain.BatchCreate, Create: domain.CreateTaskInput{Title: "Deploy API", DueDate: due}},
+		{Op: domain.BatchCreate, Create: domain.CreateTaskInput{Title: "Broken"}},
+	})
+	require.NoError(t, err)
+	assert.Error(t, results[1].Err)
+	assert.Empty(t, searchTitles(t, svc, "deploy"))
+
+	results, err = svc.ApplyBatchAtomic(ctx, []domain.BatchOperation{
+		{Op: domain.BatchCreate, Create: domain.CreateTaskInput{Title: "Deploy API", DueDate: due}},
+		{Op: domain.BatchCreate, Create: domain.CreateTaskInput{Title: "Deploy docs", DueDate: due}},
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Deploy API", "Deploy docs"}, searchTitles(t, svc, "deploy"))
+
+	_, err = svc.ApplyBatchAtomic(ctx, []domain.BatchOperation{{Op: domain.BatchDelete, ID: results[0].Task.ID}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Deploy docs"}, searchTitles(t, svc, "deploy"))
+}
+
+// TestApplyBatchAtomic_Backends tests atomic batches on every store
+func TestApplyBatchAtomic_Backends(t *testing.T) {
+	for name, repo := range queryBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			svc := domain.NewTaskService(repo)
+			ctx := context.Background()
+			due := time.Now().Add(time.Hour)
+			kept, err := svc.CreateTask(ctx, domain.CreateTaskInput{Title: "Kept", DueDate: due})
+			require.NoError(t, err)
+
+			title := "Renamed"
+			results, err := svc.ApplyBatchAtomic(ctx, []domain.BatchOperation{
+				{Op: domain.BatchCreate, Create: domain.CreateTaskInput{Title: "New", DueDate: due}},
+				{Op: domain.BatchUpdate, ID: kept.ID, Update: domain.UpdateTaskInput{Title: &title}},
+				{Op: domain.BatchDelete, ID: "missing"},
+			})
+			require.NoError(t, err)
+			assert.True(t, pkgerrors.IsNotFound(results[2].Err))
+			tasks, err := svc.ListTasks(ctx, domain.TaskFilter{})
+			require.NoError(t, err)
+			require.Len(t, tasks, 1)
+			assert.Equal(t, "Kept", tasks[0].Title)
+
+			results, err = svc.ApplyBatchAtomic(ctx, []domain.BatchOperation{
+				{Op: domain.BatchCreate, Create: domain.CreateTaskInput{Title: "New", DueDate: due}},
+				{Op: domain.BatchUpdate, ID: kept.ID, Update: domain.UpdateTaskInput{Title: &title}},
+			})
+			require.NoError(t, err)
+			for _, r := range results {
+				assert.NoError(t, r.Err)
+			}
+			tasks, err = svc.ListTasks(ctx, domain.TaskFilter{})
+			require.NoError(t, err)
+			require.Len(t, tasks, 2)
+			assert.ElementsMatch(t, []string{"New", "Renamed"}, []string{tasks[0].Title, tasks[1].Title})
+		})
+	}
+}
+
+// TestApplyBatchAtomic_FileReopen tests that a batch is replayed from the log
+func TestApplyBatchAtomic_FileReopen(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := repository.NewFileTaskRepository(dir, 0)
+	require.NoError(t, err)
+	ctx := context.Background()
+	due := time.Now().Add(time.Hour)
+
+	results, err := domain.NewTaskService(repo).ApplyBatchAtomic(ctx, []domain.BatchOperation{
+		{Op: domain.BatchCreate, Create: domain.CreateTaskInput{Title: "First", DueDate: due}},
+		{Op: domain.BatchCreate, Create: domain.CreateTaskInput{Title: "Second", DueDate: due}},
+	})
+	require.NoError(t, err)
+	_, err = domain.NewTaskService(repo).ApplyBatchAtomic(ctx, []domain.BatchOperation{
+		{Op: domain.BatchDelete, ID: results[0].Task.ID},
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.Close())
+
+	repo, err = repository.NewFileTaskRepository(dir, 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Close() })
+	tasks, err := domain.NewTaskService(repo).ListTasks(ctx, domain.TaskFilter{})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "Second", tasks[0].Title)
+}
+
+// TestApplyBatchAtomic_Unsupported tests stores without atomic batches
+func TestApplyBatchAtomic_Unsupported(t *testing.T) {
+	// Embedding only the interface hides the store's Batch method
+	repo := struct{ domain.TaskRepository }{repository.NewInMemoryTaskRepository()}
+	svc := domain.NewTaskService(repo)
+	ops := []domain.BatchOperation{{Op: domain.BatchCreate, Create: domain.CreateTaskInput{Title: "New", DueDate: time.Now().Add(time.Hour)}}}
+
+	_, err := svc.ApplyBatchAtomic(context.Background(), ops)
+	assert.True(t, pkgerrors.IsValidation(err))
+	results, err := svc.ApplyBatch(context.Background(), ops)
+	require.NoError(t, err)
+	assert.NoError(t, results[0].Err)
+}
+
+// TestHandler_Batch tests POST /tasks:batch
+func TestHandler_Batch(t *testing.T) {
+	app := newFiberTestApp()
+	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	existing := postTask(t, app, map[string]any{"title": "Existing", "due_date": due})
+	id := existing["id"].(string)
+
+	type batchResponse struct {
+		Mode      string `json:"mode"`
+		Succeeded int    `json:"succeeded"`
+		Failed    int    `json:"failed"`
+		Results   []struct {
+			Status int            `json:"status"`
+			Task   map[string]any `json:"task"`
+			Error  struct {
+				Status int    `json:"status"`
+				Code   string `json:"code"`
+				Detail string `json:"detail"`
+			} `json:"error"`
+		} `json:"results"`
+	}
+	batch := func(body map[string]any) (int, batchResponse) {
+		b, _ := json.Marshal(body)
+		req, _ := http.NewRequest(http.MethodPost, "/v1/tasks:batch", bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, 5000)
+		require.NoError(t, err)
+		respBody, _ := io.ReadAll(resp.Body)
+		var out batchResponse
+		json.Unmarshal(respBody, &out)
+		return resp.StatusCode, out
+	}
+	ops := []map[string]any{
+		{"op": "create", "task": map[string]any{"title": "New", "due_date": due}},
+		{"op": "update", "id": id, "version": 1, "task": map[string]any{"priority": "HIGH"}},
+		{"op": "update", "id": id, "version": 1, "task": map[string]any{"priority": "LOW"}},
+	}
+
+	// Atomic by default: the stale version aborts the batch
+	status, resp := batch(map[string]any{"operations": ops})
+	require.Equal(t, http.StatusMultiStatus, status)
+	assert.Equal(t, "atomic", resp.Mode)
+	assert.Equal(t, 0, resp.Succeeded)
+	assert.Equal(t, 3, resp.Failed)
+	require.Len(t, resp.Results, 3)
+	assert.Equal(t, http.StatusFailedDependency, resp.Results[0].Status)
+	assert.Equal(t, http.StatusFailedDependency, resp.Results[1].Status)
+	assert.Equal(t, http.StatusConflict, resp.Results[2].Status)
+	assert.Equal(t, domain.ErrVersionConflict, resp.Results[2].Error.Detail)
+	assert.Equal(t, domain.CodeVersionConflict, resp.Results[2].Error.Code)
+	assert.Equal(t, domain.CodeBatchAborted, resp.Results[0].Error.Code)
+	assert.Equal(t, http.StatusFailedDependency, resp.Results[0].Error.Status)
+
+	status, resp = batch(map[string]any{"mode": "best_effort", "operations": ops})
+	require.Equal(t, http.StatusMultiStatus, status)
+	assert.Equal(t, 2, resp.Succeeded)
+	assert.Equal(t, http.StatusCreated, resp.Results[0].Status)
+	assert.Equal(t, "New", resp.Results[0].Task["title"])
+	assert.Equal(t, http.StatusOK, resp.Results[1].Status)
+	assert.Equal(t, "HIGH", resp.Results[1].Task["priority"])
+	assert.Equal(t, http.StatusConflict, resp.Results[2].Status)
+
+	status, resp = batch(map[string]any{"operations": []map[string]any{
+		{"op": "update", "id": id, "task": map[string]any{"title": "Renamed"}},
+		{"op": "delete", "id": resp.Results[0].Task["id"]},
+	}})
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 2, resp.Succeeded)
+	assert.Equal(t, "HIGH", resp.Results[0].Task["priority"], "updates are partial")
+	assert.Equal(t, http.StatusNoContent, resp.Results[1].Status)
+
+	// Malformed requests are rejected before anything is applied
+	for _, body := range []map[string]any{
+		{"operations": []map[string]any{}},
+		{"mode": "eventually", "operations": ops},
+		{"operations": []map[string]any{{"op": "upsert"}}},
+		{"operations": []map[string]any{{"op": "create", "task": map[string]any{"title": "x", "due_date": "tomorrow"}}}},
+		{"operations": []map[string]any{{"op": "create", "task": map[string]any{"title": "x", "due_date": due, "colour": "red"}}}},
+		{"operations": []map[string]any{{"op": "create"}}},
+		// An empty due date is rejected, not ignored
+		{"operations": []map[string]any{{"op": "update", "id": id, "task": map[string]any{"due_date": ""}}}},
+		{"operations": []map[string]any{{"op": "update", "id": id, "task": map[string]any{"priority": "CRITICAL"}}}},
+	} {
+		status, _ := batch(body)
+		assert.Equal(t, http.StatusBadRequest, status, body)
+	}
+}
+
+// TestHandler_Batch_SQLite tests atomic batches on SQLite
+func TestHandler_Batch_SQLite(t *testing.T) {
+	repo, err := repository.NewSQLiteTaskRepository(filepath.Join(t.TempDir(), "tasks.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Close() })
+	app := httphandler.NewApp(httphandler.NewTaskHandler(domain.NewTaskService(repo)))
+	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+
+	b, _ := json.Marshal(map[string]any{"operations": []map[string]any{
+		{"op": "create", "task": map[string]any{"title": "New", "due_date": due}},
+		{"op": "delete", "id": "x"},
+	}})
+	req, _ := http.NewRequest(http.MethodPost, "/v1/tasks:batch", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusMultiStatus, resp.StatusCode)
+	tasks, err := repo.ListAll(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+
+	b, _ = json.Marshal(map[string]any{"operations": []map[string]any{
+		{"op": "create", "task": map[string]any{"title": "New", "due_date": due}},
+	}})
+	req, _ = http.NewRequest(http.MethodPost, "/v1/tasks:batch", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = app.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	tasks, err = repo.ListAll(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+}
@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	"github.com/gauravpandey771/task-api/internal/repository"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRepository_Update_StaleVersion tests that every backend rejects stale writes
+func TestRepository_Update_StaleVersion(t *testing.T) {
+	for name, repo := range queryBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			task := &domain.Task{Title: "Task", Status: domain.StatusPending, DueDate: time.Now().Add(24 * time.Hour)}
+			require.NoError(t, repo.Create(context.Background(), task))
+			assert.Equal(t, int64(1), task.Version)
+
+			first, _ := repo.GetByID(context.Background(), task.ID)
+			second, _ := repo.GetByID(context.Background(), task.ID)
+
+			first.Title = "First"
+			require.NoError(t, repo.Update(context.Background(), first))
+			assert.Equal(t, int64(2), first.Version)
+
+			second.Title = "Second"
+			err := repo.Update(context.Background(), second)
+			require.Error(t, err)
+			assert.True(t, pkgerrors.IsConflict(err))
+
+			stored, _ := repo.GetByID(context.Background(), task.ID)
+			assert.Equal(t, "First", stored.Title)
+			assert.Equal(t, int64(2), stored.Version)
+		})
+	}
+}
+
+// TestUpdateTask_ExpectedVersion tests the service version check
+func TestUpdateTask_ExpectedVersion(t *testing.T) {
+	svc := domain.NewTaskService(repository.NewInMemoryTaskRepository())
+	created, _ := svc.CreateTask(context.Background(), domain.CreateTaskInput{Title: "Task", DueDate: time.Now().Add(24 * time.Hour)})
+
+	title := "Updated"
+	stale := int64(5)
+	_, err := svc.UpdateTask(context.Background(), created.ID, domain.UpdateTaskInput{Title: &title, ExpectedVersion: &stale})
+	require.Error(t, err)
+	assert.True(t, pkgerrors.IsConflict(err))
+
+	current := created.Version
+	updated, err := svc.UpdateTask(context.Background(), created.ID, domain.UpdateTaskInput{Title: &title, ExpectedVersion: &current})
+	require.NoError(t, err)
+	assert.Equal(t, current+1, updated.Version)
+}
+
+// TestHandler_UpdateTask_IfMatch tests ETag and If-Match handling on PUT
+func TestHandler_UpdateTask_IfMatch(t *testing.T) {
+	app := newFiberTestApp()
+	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	b, _ := json.Marshal(map[string]any{"title": "Task", "due_date": due})
+	req, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req, 5000)
+	assert.Equal(t, `"1"`, resp.Header.Get("ETag"))
+	respBody, _ := io.ReadAll(resp.Body)
+	var created map[string]any
+	json.Unmarshal(respBody, &created)
+	id := created["id"].(string)
+
+	put := func(ifMatch string) *http.Response {
+		body, _ := json.Marshal(map[string]any{"title": "Updated", "due_date": due})
+		r, _ := http.NewRequest(http.MethodPut, "/v1/tasks/"+id, bytes.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			r.Header.Set("If-Match", ifMatch)
+		}
+		res, _ := app.Test(r, 5000)
+		return res
+	}
+
+	ok := put(`"1"`)
+	assert.Equal(t, http.StatusOK, ok.StatusCode)
+	assert.Equal(t, `"2"`, ok.Header.Get("ETag"))
+
+	assert.Equal(t, http.StatusPreconditionFailed, put(`"1"`).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, put("not-an-etag").StatusCode)
+	assert.Equal(t, http.StatusOK, put("*").StatusCode)
+
+	getReq, _ := http.NewRequest(http.MethodGet, "/v1/tasks/"+id, nil)
+	getResp, _ := app.Test(getReq, 5000)
+	assert.Equal(t, `"3"`, getResp.Header.Get("ETag"))
+
+	// Weak tags never match; a list matches if any of its tags does
+	assert.Equal(t, http.StatusPreconditionFailed, put(`W/"3"`).StatusCode)
+	assert.Equal(t, http.StatusPreconditionFailed, put(`"1", W/"3"`).StatusCode)
+	assert.Equal(t, http.StatusPreconditionFailed, put(`"1", "2"`).StatusCode)
+	ok = put(`"1", "3"`)
+	assert.Equal(t, http.StatusOK, ok.StatusCode)
+	assert.Equal(t, `"4"`, ok.Header.Get("ETag"))
+	assert.Equal(t, http.StatusBadRequest, put(`"4", not-an-etag`).StatusCode)
+}
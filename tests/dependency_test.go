@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDependencies_CycleDetection tests rejecting self and transitive cycles
+func TestDependencies_CycleDetection(t *testing.T) {
+	svc := newTestService()
+	ctx := context.Background()
+	a := createChild(t, svc, "", "a")
+	b := createChild(t, svc, "", "b")
+	c := createChild(t, svc, "", "c")
+
+	_, err := svc.AddDependency(ctx, b.ID, a.ID)
+	require.NoError(t, err)
+	_, err = svc.AddDependency(ctx, c.ID, b.ID)
+	require.NoError(t, err)
+
+	for _, edge := range [][2]string{{a.ID, a.ID}, {a.ID, b.ID}, {a.ID, c.ID}} {
+		_, err = svc.AddDependency(ctx, edge[0], edge[1])
+		assert.True(t, pkgerrors.IsValidation(err))
+		assert.Equal(t, domain.ErrDependencyCycle, err.Error())
+	}
+
+	_, err = svc.AddDependency(ctx, a.ID, "missing")
+	assert.True(t, pkgerrors.IsValidation(err))
+	assert.Equal(t, domain.ErrBlockerNotFound, err.Error())
+
+	// Re-adding an edge is a no-op
+	again, err := svc.AddDependency(ctx, b.ID, a.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{a.ID}, again.BlockedBy)
+
+	removed, err := svc.RemoveDependency(ctx, b.ID, a.ID)
+	require.NoError(t, err)
+	assert.Empty(t, removed.BlockedBy)
+	_, err = svc.RemoveDependency(ctx, b.ID, a.ID)
+	assert.True(t, pkgerrors.IsNotFound(err))
+}
+
+// TestDependencies_ConcurrentCycle tests that concurrent additions of
+// opposite edges cannot both succeed
+func TestDependencies_ConcurrentCycle(t *testing.T) {
+	svc := newTestService()
+	ctx := context.Background()
+	for i := 0; i < 50; i++ {
+		a := createChild(t, svc, "", "a")
+		b := createChild(t, svc, "", "b")
+
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		for j, edge := range [][2]string{{a.ID, b.ID}, {b.ID, a.ID}} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, errs[j] = svc.AddDependency(ctx, edge[0], edge[1])
+			}()
+		}
+		wg.Wait()
+
+		require.False(t, errs[0] == nil && errs[1] == nil, "both edges of a cycle were added")
+	}
+}
+
+// TestDependencies_BlockStart tests that blocked tasks cannot move to IN_PROGRESS
+func TestDependencies_BlockStart(t *testing.T) {
+	for name, repo := range queryBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			svc := domain.NewTaskService(repo)
+			ctx := context.Background()
+			inProgress, done := domain.StatusInProgress, domain.StatusDone
+
+			blocker := createChild(t, svc, "", "blocker")
+			task := createChild(t, svc, "", "task")
+			_, err := svc.AddDependency(ctx, task.ID, blocker.ID)
+			require.NoError(t, err)
+
+			_, err = svc.UpdateTask(ctx, task.ID, domain.UpdateTaskInput{Status: &inProgress})
+			assert.True(t, pkgerrors.IsValidation(err))
+			assert.Equal(t, domain.ErrTaskBlocked, err.Error())
+
+			_, err = svc.UpdateTask(ctx, blocker.ID, domain.UpdateTaskInput{Status: &done})
+			require.NoError(t, err)
+			started, err := svc.UpdateTask(ctx, task.ID, domain.UpdateTaskInput{Status: &inProgress})
+			require.NoError(t, err)
+			assert.Equal(t, domain.StatusInProgress, started.Status)
+		})
+	}
+}
+
+// TestDependencies_Graph tests that blockers come back in topological order
+func TestDependencies_Graph(t *testing.T) {
+	svc := newTestService()
+	ctx := context.Background()
+
+	// d waits on b and c, which both wait on a
+	a := createChild(t, svc, "", "a")
+	b := createChild(t, svc, "", "b")
+	c := createChild(t, svc, "", "c")
+	d := createChild(t, svc, "", "d")
+	for _, edge := range [][2]string{{b.ID, a.ID}, {c.ID, a.ID}, {d.ID, b.ID}, {d.ID, c.ID}} {
+		_, err := svc.AddDependency(ctx, edge[0], edge[1])
+		require.NoError(t, err)
+	}
+
+	graph, err := svc.DependencyGraph(ctx, d.ID)
+	require.NoError(t, err)
+	require.Len(t, graph.Tasks, 3)
+	assert.Equal(t, a.ID, graph.Tasks[0].ID)
+	assert.ElementsMatch(t, []string{b.ID, c.ID}, ids(graph.Tasks[1:]))
+	assert.Len(t, graph.Edges, 4)
+
+	position := map[string]int{d.ID: len(graph.Tasks)}
+	for i, task := range graph.Tasks {
+		position[task.ID] = i
+	}
+	for _, e := range graph.Edges {
+		assert.Less(t, position[e.Blocker], position[e.Blocked])
+	}
+
+	leaf, err := svc.DependencyGraph(ctx, a.ID)
+	require.NoError(t, err)
+	assert.Empty(t, leaf.Tasks)
+}
+
+// TestHandler_Dependencies tests the dependency endpoints
+func TestHandler_Dependencies(t *testing.T) {
+	app := newFiberTestApp()
+	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	create := func(title string) string {
+		var task map[string]any
+		decodeBody(t, sendJSON(t, app, http.MethodPost, "/v1/tasks", map[string]any{"title": title, "due_date": due}), &task)
+		return task["id"].(string)
+	}
+	blocker, task := create("blocker"), create("task")
+
+	resp := sendJSON(t, app, http.MethodPost, "/v1/tasks/"+task+"/dependencies", map[string]any{"blocker_id": blocker})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `"2"`, resp.Header.Get("ETag"))
+
+	assert.Equal(t, http.StatusBadRequest, sendJSON(t, app, http.MethodPost, "/v1/tasks/"+blocker+"/dependencies", map[string]any{"blocker_id": task}).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, sendJSON(t, app, http.MethodPut, "/v1/tasks/"+task, map[string]any{"title": "task", "status": "IN_PROGRESS", "due_date": due}).StatusCode)
+
+	var graph domain.DependencyGraph
+	decodeBody(t, sendJSON(t, app, http.MethodGet, "/v1/tasks/"+task+"/graph", nil), &graph)
+	require.Len(t, graph.Tasks, 1)
+	assert.Equal(t, blocker, graph.Tasks[0].ID)
+	assert.Equal(t, []domain.DependencyEdge{{Blocker: blocker, Blocked: task}}, graph.Edges)
+
+	assert.Equal(t, http.StatusOK, sendJSON(t, app, http.MethodDelete, "/v1/tasks/"+task+"/dependencies/"+blocker, nil).StatusCode)
+	assert.Equal(t, http.StatusNotFound, sendJSON(t, app, http.MethodDelete, "/v1/tasks/"+task+"/dependencies/"+blocker, nil).StatusCode)
+	assert.Equal(t, http.StatusNotFound, sendJSON(t, app, http.MethodGet, "/v1/tasks/missing/graph", nil).StatusCode)
+}
@@ -29,7 +29,7 @@ func TestCreateTaskValidation_EmptyTitle(t *testing.T) {
 	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
 	body := map[string]any{"title": "", "due_date": due}
 	b, _ := json.Marshal(body)
-	req, _ := http.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(b))
+	req, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader(b))
 	req.Header.Set("Content-Type", "application/json")
 	resp, _ := app.Test(req, 5000)
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
@@ -40,7 +40,7 @@ func TestCreateTaskValidation_MissingDueDate(t *testing.T) {
 	app := newFiberTestApp()
 	body := map[string]any{"title": "Task"}
 	b, _ := json.Marshal(body)
-	req, _ := http.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(b))
+	req, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader(b))
 	req.Header.Set("Content-Type", "application/json")
 	resp, _ := app.Test(req, 5000)
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
@@ -52,7 +52,7 @@ func TestCreateTaskValidation_PastDueDate(t *testing.T) {
 	due := time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339)
 	body := map[string]any{"title": "Task", "due_date": due}
 	b, _ := json.Marshal(body)
-	req, _ := http.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(b))
+	req, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader(b))
 	req.Header.Set("Content-Type", "application/json")
 	resp, _ := app.Test(req, 5000)
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
@@ -64,7 +64,7 @@ func TestCreateTaskValidation_InvalidStatus(t *testing.T) {
 	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
 	body := map[string]any{"title": "Task", "status": "INVALID", "due_date": due}
 	b, _ := json.Marshal(body)
-	req, _ := http.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(b))
+	req, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader(b))
 	req.Header.Set("Content-Type", "application/json")
 	resp, _ := app.Test(req, 5000)
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
@@ -76,7 +76,7 @@ func TestCreateTask_WithAllFields(t *testing.T) {
 	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
 	body := map[string]any{"title": "Complete", "description": "Full", "status": "IN_PROGRESS", "due_date": due}
 	b, _ := json.Marshal(body)
-	req, _ := http.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(b))
+	req, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader(b))
 	req.Header.Set("Content-Type", "application/json")
 	resp, _ := app.Test(req, 5000)
 	assert.Equal(t, http.StatusCreated, resp.StatusCode)
@@ -91,7 +91,7 @@ func TestCreateTask_WithAllFields(t *testing.T) {
 // TestError_GetTask_NotFound tests 404 for non-existent task (different from service test)
 func TestError_GetTask_NotFound(t *testing.T) {
 	app := newFiberTestApp()
-	req, _ := http.NewRequest(http.MethodGet, "/tasks/non-existent", nil)
+	req, _ := http.NewRequest(http.MethodGet, "/v1/tasks/non-existent", nil)
 	resp, _ := app.Test(req, 5000)
 	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 }
@@ -99,9 +99,10 @@ func TestError_GetTask_NotFound(t *testing.T) {
 // TestError_UpdateTask_NotFound tests 404 when updating non-existent task (different from service test)
 func TestError_UpdateTask_NotFound(t *testing.T) {
 	app := newFiberTestApp()
-	body := map[string]any{"title": "Updated"}
+	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	body := map[string]any{"title": "Updated", "due_date": due}
 	b, _ := json.Marshal(body)
-	req, _ := http.NewRequest(http.MethodPut, "/tasks/non-existent", bytes.NewReader(b))
+	req, _ := http.NewRequest(http.MethodPut, "/v1/tasks/non-existent", bytes.NewReader(b))
 	req.Header.Set("Content-Type", "application/json")
 	resp, _ := app.Test(req, 5000)
 	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
@@ -113,7 +114,7 @@ func TestUpdateTask_InvalidStatus(t *testing.T) {
 	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
 	body := map[string]any{"title": "Task", "due_date": due}
 	b, _ := json.Marshal(body)
-	req, _ := http.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(b))
+	req, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader(b))
 	req.Header.Set("Content-Type", "application/json")
 	resp, _ := app.Test(req, 5000)
 	respBody, _ := io.ReadAll(resp.Body)
@@ -121,9 +122,9 @@ func TestUpdateTask_InvalidStatus(t *testing.T) {
 	json.Unmarshal(respBody, &created)
 	id := created["id"].(string)
 
-	updateBody := map[string]any{"status": "INVALID"}
+	updateBody := map[string]any{"title": "Task", "status": "INVALID", "due_date": due}
 	updateB, _ := json.Marshal(updateBody)
-	updateReq, _ := http.NewRequest(http.MethodPut, "/tasks/"+id, bytes.NewReader(updateB))
+	updateReq, _ := http.NewRequest(http.MethodPut, "/v1/tasks/"+id, bytes.NewReader(updateB))
 	updateReq.Header.Set("Content-Type", "application/json")
 	updateResp, _ := app.Test(updateReq, 5000)
 	assert.Equal(t, http.StatusBadRequest, updateResp.StatusCode)
@@ -132,7 +133,7 @@ func TestUpdateTask_InvalidStatus(t *testing.T) {
 // TestError_DeleteTask_NotFound tests 404 when deleting non-existent task (different from service test)
 func TestError_DeleteTask_NotFound(t *testing.T) {
 	app := newFiberTestApp()
-	req, _ := http.NewRequest(http.MethodDelete, "/tasks/non-existent", nil)
+	req, _ := http.NewRequest(http.MethodDelete, "/v1/tasks/non-existent", nil)
 	resp, _ := app.Test(req, 5000)
 	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 }
@@ -144,21 +145,20 @@ func TestHandler_ListTasks_FilterByStatus(t *testing.T) {
 
 	body1 := map[string]any{"title": "Pending", "due_date": due}
 	b1, _ := json.Marshal(body1)
-	req1, _ := http.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(b1))
+	req1, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader(b1))
 	req1.Header.Set("Content-Type", "application/json")
 	app.Test(req1, 5000)
 
 	body2 := map[string]any{"title": "Done", "status": "DONE", "due_date": due}
 	b2, _ := json.Marshal(body2)
-	req2, _ := http.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(b2))
+	req2, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader(b2))
 	req2.Header.Set("Content-Type", "application/json")
 	app.Test(req2, 5000)
 
-	req, _ := http.NewRequest(http.MethodGet, "/tasks?status=DONE", nil)
+	req, _ := http.NewRequest(http.MethodGet, "/v1/tasks?status=DONE", nil)
 	resp, _ := app.Test(req, 5000)
 	respBody, _ := io.ReadAll(resp.Body)
-	var tasks []map[string]any
-	json.Unmarshal(respBody, &tasks)
+	tasks := listItems(t, respBody)
 	assert.Equal(t, 1, len(tasks))
 	assert.Equal(t, "Done", tasks[0]["title"])
 }
@@ -170,23 +170,21 @@ func TestHandler_ListTasks_Pagination(t *testing.T) {
 	for i := 0; i < 15; i++ {
 		body := map[string]any{"title": "Task", "due_date": due}
 		b, _ := json.Marshal(body)
-		req, _ := http.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(b))
+		req, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader(b))
 		req.Header.Set("Content-Type", "application/json")
 		app.Test(req, 5000)
 	}
 
-	req1, _ := http.NewRequest(http.MethodGet, "/tasks?page=1&page_size=10", nil)
+	req1, _ := http.NewRequest(http.MethodGet, "/v1/tasks?page=1&page_size=10", nil)
 	resp1, _ := app.Test(req1, 5000)
 	body1, _ := io.ReadAll(resp1.Body)
-	var page1 []map[string]any
-	json.Unmarshal(body1, &page1)
+	page1 := listItems(t, body1)
 	assert.Equal(t, 10, len(page1))
 
-	req2, _ := http.NewRequest(http.MethodGet, "/tasks?page=2&page_size=10", nil)
+	req2, _ := http.NewRequest(http.MethodGet, "/v1/tasks?page=2&page_size=10", nil)
 	resp2, _ := app.Test(req2, 5000)
 	body2, _ := io.ReadAll(resp2.Body)
-	var page2 []map[string]any
-	json.Unmarshal(body2, &page2)
+	page2 := listItems(t, body2)
 	assert.Equal(t, 5, len(page2))
 }
 
@@ -197,15 +195,14 @@ func TestListTasks_DefaultValues(t *testing.T) {
 	for i := 0; i < 5; i++ {
 		body := map[string]any{"title": "Task", "due_date": due}
 		b, _ := json.Marshal(body)
-		req, _ := http.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(b))
+		req, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader(b))
 		req.Header.Set("Content-Type", "application/json")
 		app.Test(req, 5000)
 	}
-	req, _ := http.NewRequest(http.MethodGet, "/tasks", nil)
+	req, _ := http.NewRequest(http.MethodGet, "/v1/tasks", nil)
 	resp, _ := app.Test(req, 5000)
 	respBody, _ := io.ReadAll(resp.Body)
-	var tasks []map[string]any
-	json.Unmarshal(respBody, &tasks)
+	tasks := listItems(t, respBody)
 	assert.Equal(t, 5, len(tasks))
 }
 
@@ -215,22 +212,21 @@ func TestHandler_ListTasks_SortedByDueDate(t *testing.T) {
 	due2 := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
 	body2 := map[string]any{"title": "Task 2", "due_date": due2}
 	b2, _ := json.Marshal(body2)
-	req2, _ := http.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(b2))
+	req2, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader(b2))
 	req2.Header.Set("Content-Type", "application/json")
 	app.Test(req2, 5000)
 
 	due1 := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
 	body1 := map[string]any{"title": "Task 1", "due_date": due1}
 	b1, _ := json.Marshal(body1)
-	req1, _ := http.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(b1))
+	req1, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader(b1))
 	req1.Header.Set("Content-Type", "application/json")
 	app.Test(req1, 5000)
 
-	req, _ := http.NewRequest(http.MethodGet, "/tasks", nil)
+	req, _ := http.NewRequest(http.MethodGet, "/v1/tasks", nil)
 	resp, _ := app.Test(req, 5000)
 	respBody, _ := io.ReadAll(resp.Body)
-	var tasks []map[string]any
-	json.Unmarshal(respBody, &tasks)
+	tasks := listItems(t, respBody)
 	assert.Equal(t, "Task 1", tasks[0]["title"])
 	assert.Equal(t, "Task 2", tasks[1]["title"])
 }
@@ -238,7 +234,7 @@ func TestHandler_ListTasks_SortedByDueDate(t *testing.T) {
 // TestInvalidJSON tests invalid JSON handling
 func TestInvalidJSON(t *testing.T) {
 	app := newFiberTestApp()
-	req, _ := http.NewRequest(http.MethodPost, "/tasks", bytes.NewReader([]byte("{invalid")))
+	req, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader([]byte("{invalid")))
 	req.Header.Set("Content-Type", "application/json")
 	resp, _ := app.Test(req, 5000)
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	httphandler "github.com/gauravpandey771/task-api/internal/transport/http"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStatusOf tests the mapping of error types to statuses
+func TestStatusOf(t *testing.T) {
+	cause := errors.New("connection refused")
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{pkgerrors.NewValidationError("c", "m"), http.StatusBadRequest},
+		{pkgerrors.NewNotFoundError("c", "m"), http.StatusNotFound},
+		{pkgerrors.NewConflictError("c", "m"), http.StatusConflict},
+		{pkgerrors.NewPreconditionError("c", "m"), http.StatusPreconditionFailed},
+		{pkgerrors.NewUnauthorizedError("c", "m"), http.StatusUnauthorized},
+		{pkgerrors.NewForbiddenError("c", "m"), http.StatusForbidden},
+		{pkgerrors.NewRateLimitedError("c", "m"), http.StatusTooManyRequests},
+		{pkgerrors.NewUnavailableError("c", "m", cause), http.StatusServiceUnavailable},
+		{fmt.Errorf("get task: %w", pkgerrors.NewNotFoundError("c", "m")), http.StatusNotFound},
+		{cause, http.StatusInternalServerError},
+		{&pkgerrors.AppError{Type: "unregistered"}, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.status, pkgerrors.StatusOf(tt.err), tt.err.Error())
+	}
+
+	pkgerrors.Register("teapot", http.StatusTeapot)
+	t.Cleanup(func() { pkgerrors.Register("teapot", http.StatusInternalServerError) })
+	assert.Equal(t, http.StatusTeapot, pkgerrors.StatusOf(&pkgerrors.AppError{Type: "teapot"}))
+}
+
+// TestAppError_Chains tests errors.Is and errors.As through wrapping
+func TestAppError_Chains(t *testing.T) {
+	cause := errors.New("disk full")
+	err := fmt.Errorf("save: %w", pkgerrors.Wrap(cause, pkgerrors.ErrTypeUnavailable, "store_unavailable", "store unavailable"))
+
+	assert.ErrorIs(t, err, cause)
+	assert.ErrorIs(t, err, pkgerrors.ErrUnavailable)
+	assert.ErrorIs(t, err, &pkgerrors.AppError{Type: pkgerrors.ErrTypeUnavailable, Code: "store_unavailable"})
+	assert.NotErrorIs(t, err, &pkgerrors.AppError{Type: pkgerrors.ErrTypeUnavailable, Code: "other"})
+	assert.NotErrorIs(t, err, pkgerrors.ErrNotFound)
+	assert.False(t, pkgerrors.IsNotFound(err))
+
+	var appErr *pkgerrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, "store unavailable", appErr.Error())
+	assert.True(t, pkgerrors.HasCode(err, "store_unavailable"))
+
+	// A wrapped error keeps the codes of its causes
+	conflict := pkgerrors.NewConflictError(domain.CodeVersionConflict, domain.ErrVersionConflict)
+	err = pkgerrors.Wrap(conflict, pkgerrors.ErrTypePrecondition, "stale", "stale")
+	assert.True(t, pkgerrors.HasCode(err, domain.CodeVersionConflict))
+	assert.True(t, pkgerrors.IsConflict(err))
+	assert.Equal(t, http.StatusPreconditionFailed, pkgerrors.StatusOf(err))
+}
+
+// failingService fails every read with err
+type failingService struct {
+	domain.TaskService
+	err error
+}
+
+func (s *failingService) GetTask(context.Context, string) (*domain.Task, error) {
+	return nil, s.err
+}
+
+// TestHandler_ErrorMiddleware tests that handler errors are mapped in one
+// place and server errors are logged with their cause
+func TestHandler_ErrorMiddleware(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	get := func(err error) (int, map[string]any) {
+		app := httphandler.NewApp(httphandler.NewTaskHandler(&failingService{TaskService: newTestService(), err: err}))
+		req, _ := http.NewRequest(http.MethodGet, "/v1/tasks/1", nil)
+		resp, reqErr := app.Test(req, 5000)
+		require.NoError(t, reqErr)
+		body, _ := io.ReadAll(resp.Body)
+		var out map[string]any
+		require.NoError(t, json.Unmarshal(body, &out))
+		return resp.StatusCode, out
+	}
+
+	status, body := get(fmt.Errorf("lookup: %w", pkgerrors.NewNotFoundError(domain.CodeTaskNotFound, domain.ErrTaskNotFound)))
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, domain.CodeTaskNotFound, body["code"])
+	assert.Empty(t, logs.String(), "client errors are not logged")
+
+	status, body = get(pkgerrors.NewRateLimitedError("too_many_requests", "slow down"))
+	assert.Equal(t, http.StatusTooManyRequests, status)
+	assert.Equal(t, "slow down", body["detail"])
+
+	status, body = get(errors.New("sql: database is closed"))
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, "internal error", body["detail"])
+	assert.Contains(t, logs.String(), "GET /v1/tasks/1: 500")
+	assert.Contains(t, logs.String(), "sql: database is closed")
+
+	logs.Reset()
+	status, body = get(pkgerrors.NewUnavailableError("store_unavailable", "store unavailable", errors.New("dial tcp: timeout")))
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "store unavailable", body["detail"])
+	assert.NotContains(t, fmt.Sprint(body), "dial tcp")
+	assert.Contains(t, logs.String(), "store unavailable: dial tcp: timeout")
+}
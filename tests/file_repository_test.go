@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	"github.com/gauravpandey771/task-api/internal/repository"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Helper to open a file repository without background compaction
+func openFileRepo(t *testing.T, dir string) *repository.FileTaskRepository {
+	t.Helper()
+	repo, err := repository.NewFileTaskRepository(dir, 0)
+	require.NoError(t, err)
+	return repo
+}
+
+// TestFileRepository_CRUD tests basic repository behavior
+func TestFileRepository_CRUD(t *testing.T) {
+	repo := openFileRepo(t, t.TempDir())
+	defer repo.Close()
+
+	task := &domain.Task{Title: "Task", Status: domain.StatusPending, DueDate: time.Now().Add(24 * time.Hour)}
+	require.NoError(t, repo.Create(context.Background(), task))
+	assert.NotEmpty(t, task.ID)
+
+	task.Title = "Updated"
+	require.NoError(t, repo.Update(context.Background(), task))
+
+	retrieved, err := repo.GetByID(context.Background(), task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Updated", retrieved.Title)
+
+	require.NoError(t, repo.Delete(context.Background(), task.ID))
+	_, err = repo.GetByID(context.Background(), task.ID)
+	assert.True(t, pkgerrors.IsNotFound(err))
+
+	assert.True(t, pkgerrors.IsNotFound(repo.Delete(context.Background(), task.ID)))
+	assert.True(t, pkgerrors.IsNotFound(repo.Update(context.Background(), &domain.Task{ID: "missing"})))
+}
+
+// TestFileRepository_ReplayOnReopen tests rebuilding state from the log
+func TestFileRepository_ReplayOnReopen(t *testing.T) {
+	dir := t.TempDir()
+	repo := openFileRepo(t, dir)
+
+	kept := &domain.Task{Title: "Kept", Status: domain.StatusPending, DueDate: time.Now().Add(24 * time.Hour)}
+	gone := &domain.Task{Title: "Gone", Status: domain.StatusPending, DueDate: time.Now().Add(24 * time.Hour)}
+	require.NoError(t, repo.Create(context.Background(), kept))
+	require.NoError(t, repo.Create(context.Background(), gone))
+	kept.Status = domain.StatusDone
+	require.NoError(t, repo.Update(context.Background(), kept))
+	require.NoError(t, repo.Delete(context.Background(), gone.ID))
+	require.NoError(t, repo.Close())
+
+	reopened := openFileRepo(t, dir)
+	defer reopened.Close()
+
+	tasks, err := reopened.ListAll(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, len(tasks))
+	assert.Equal(t, kept.ID, tasks[0].ID)
+	assert.Equal(t, domain.StatusDone, tasks[0].Status)
+}
+
+// TestFileRepository_TruncatedLastLine tests recovery from a torn write
+func TestFileRepository_TruncatedLastLine(t *testing.T) {
+	dir := t.TempDir()
+	repo := openFileRepo(t, dir)
+	task := &domain.Task{Title: "Survivor", Status: domain.StatusPending, DueDate: time.Now().Add(24 * time.Hour)}
+	require.NoError(t, repo.Create(context.Background(), task))
+	require.NoError(t, repo.Close())
+
+	// Simulate a crash halfway through appending the next entry
+	f, err := os.OpenFile(filepath.Join(dir, "tasks.log"), os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"op":"create","task":{"id":"half`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	reopened := openFileRepo(t, dir)
+	tasks, err := reopened.ListAll(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, len(tasks))
+	assert.Equal(t, "Survivor", tasks[0].Title)
+
+	// New writes after recovery must still replay cleanly
+	require.NoError(t, reopened.Create(context.Background(), &domain.Task{Title: "After", Status: domain.StatusPending, DueDate: time.Now().Add(24 * time.Hour)}))
+	require.NoError(t, reopened.Close())
+
+	again := openFileRepo(t, dir)
+	defer again.Close()
+	tasks, err = again.ListAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(tasks))
+}
+
+// TestFileRepository_UnterminatedLastLine tests that a complete entry whose
+// newline was never written is dropped rather than joined onto the next one
+func TestFileRepository_UnterminatedLastLine(t *testing.T) {
+	dir := t.TempDir()
+	repo := openFileRepo(t, dir)
+	require.NoError(t, repo.Create(context.Background(), &domain.Task{Title: "Survivor", Status: domain.StatusPending, DueDate: time.Now().Add(24 * time.Hour)}))
+	require.NoError(t, repo.Close())
+
+	f, err := os.OpenFile(filepath.Join(dir, "tasks.log"), os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"op":"create","task":{"id":"unacked","title":"Unacked"}}`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	reopened := openFileRepo(t, dir)
+	tasks, err := reopened.ListAll(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, len(tasks))
+	assert.Equal(t, "Survivor", tasks[0].Title)
+
+	require.NoError(t, reopened.Create(context.Background(), &domain.Task{Title: "After", Status: domain.StatusPending, DueDate: time.Now().Add(24 * time.Hour)}))
+	require.NoError(t, reopened.Close())
+
+	again := openFileRepo(t, dir)
+	defer again.Close()
+	tasks, err = again.ListAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(tasks))
+}
+
+// TestFileRepository_CorruptMiddleLine tests that mid-log corruption is reported
+func TestFileRepository_CorruptMiddleLine(t *testing.T) {
+	dir := t.TempDir()
+	log := "{not json}\n" + `{"op":"delete","id":"x"}` + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tasks.log"), []byte(log), 0o644))
+
+	_, err := repository.NewFileTaskRepository(dir, 0)
+	assert.Error(t, err)
+}
+
+// TestFileRepository_Compact tests snapshotting and log truncation
+func TestFileRepository_Compact(t *testing.T) {
+	dir := t.TempDir()
+	repo := openFileRepo(t, dir)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, repo.Create(context.Background(), &domain.Task{Title: "Task", Status: domain.StatusPending, DueDate: time.Now().Add(24 * time.Hour)}))
+	}
+
+	require.NoError(t, repo.Compact())
+	info, err := os.Stat(filepath.Join(dir, "tasks.log"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), info.Size())
+
+	extra := &domain.Task{Title: "Extra", Status: domain.StatusPending, DueDate: time.Now().Add(24 * time.Hour)}
+	require.NoError(t, repo.Create(context.Background(), extra))
+	require.NoError(t, repo.Close())
+
+	reopened := openFileRepo(t, dir)
+	defer reopened.Close()
+	tasks, err := reopened.ListAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 6, len(tasks))
+	_, err = reopened.GetByID(context.Background(), extra.ID)
+	assert.NoError(t, err)
+}
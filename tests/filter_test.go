@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseFilter tests the AST built for a typical expression
+func TestParseFilter(t *testing.T) {
+	expr, err := domain.ParseFilter(`status in (PENDING, IN_PROGRESS) and due_date < 2026-11-01 and title ~ "deploy"`)
+	require.NoError(t, err)
+
+	and, ok := expr.(*domain.FilterAnd)
+	require.True(t, ok)
+	require.Len(t, and.Terms, 3)
+	assert.Equal(t, &domain.FilterComparison{Field: domain.FilterStatus, Op: domain.OpIn, Values: []any{"PENDING", "IN_PROGRESS"}}, and.Terms[0])
+	assert.Equal(t, &domain.FilterComparison{Field: domain.FilterDueDate, Op: domain.OpLt, Values: []any{time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)}}, and.Terms[1])
+	assert.Equal(t, &domain.FilterComparison{Field: domain.FilterTitle, Op: domain.OpContains, Values: []any{"deploy"}}, and.Terms[2])
+
+	// and binds tighter than or; keywords are case-insensitive
+	expr, err = domain.ParseFilter(`priority >= high OR NOT tags = Bug AND parent_id = ""`)
+	require.NoError(t, err)
+	or, ok := expr.(*domain.FilterOr)
+	require.True(t, ok)
+	require.Len(t, or.Terms, 2)
+	assert.Equal(t, &domain.FilterComparison{Field: domain.FilterPriority, Op: domain.OpGe, Values: []any{domain.PriorityHigh}}, or.Terms[0])
+	assert.IsType(t, &domain.FilterAnd{}, or.Terms[1])
+
+	expr, err = domain.ParseFilter("   ")
+	require.NoError(t, err)
+	assert.Nil(t, expr)
+}
+
+// TestParseFilter_Errors tests error messages and positions
+func TestParseFilter_Errors(t *testing.T) {
+	cases := []struct {
+		input string
+		pos   int
+		msg   string
+	}{
+		{`colour = red`, 1, `unknown field "colour"`},
+		{`status < DONE`, 8, `operator "<" not supported for status`},
+		{`title ~ "deploy`, 9, "unterminated string"},
+		{`status = DONE and`, 18, "expected field name, got end of expression"},
+		{`due_date > tomorrow`, 12, `invalid date "tomorrow"`},
+		{`priority = CRITICAL`, 12, `unknown priority "CRITICAL"`},
+		{`status in (DONE PENDING)`, 17, `expected "," or ")"`},
+		{`(status = DONE`, 15, `expected ")"`},
+		{`status = DONE )`, 15, `unexpected ")"`},
+		{`status ! DONE`, 8, `expected "!="`},
+		{`status = DONE; drop`, 14, "unexpected character ';'"},
+	}
+	for _, tc := range cases {
+		_, err := domain.ParseFilter(tc.input)
+		var filterErr *domain.FilterError
+		require.ErrorAs(t, err, &filterErr, tc.input)
+		assert.Equal(t, tc.pos, filterErr.Pos, tc.input)
+		assert.Contains(t, filterErr.Msg, tc.msg, tc.input)
+		assert.True(t, pkgerrors.IsValidation(err), tc.input)
+	}
+}
+
+// TestRepositoryQuery_FilterExpr tests that every backend evaluates
+// expressions the same way
+func TestRepositoryQuery_FilterExpr(t *testing.T) {
+	base := time.Date(2030, 1, 10, 12, 0, 0, 0, time.UTC)
+	seed := []*domain.Task{
+		{Title: "Deploy API", Status: domain.StatusPending, Priority: domain.PriorityHigh, Tags: []string{"ops"}, DueDate: base},
+		{Title: "Write docs", Status: domain.StatusInProgress, Priority: domain.PriorityLow, Tags: []string{"docs", "ops"}, DueDate: base.AddDate(0, 0, 5)},
+		{Title: "Redeploy web", Status: domain.StatusDone, Priority: domain.PriorityUrgent, DueDate: base.AddDate(0, 1, 0)},
+		{Title: "Plan sprint", Status: domain.StatusPending, Priority: domain.PriorityMedium, Description: "DEPLOY plan", DueDate: base.AddDate(0, 0, -3)},
+	}
+
+	cases := []struct {
+		filter string
+		want   []string
+	}{
+		{`status in (PENDING, IN_PROGRESS)`, []string{"Plan sprint", "Deploy API", "Write docs"}},
+		{`title ~ "deploy"`, []string{"Deploy API", "Redeploy web"}},
+		{`title ~ deploy or description ~ deploy`, []string{"Plan sprint", "Deploy API", "Redeploy web"}},
+		{`due_date < 2030-01-11 and not status = DONE`, []string{"Plan sprint", "Deploy API"}},
+		{`due_date >= "2030-01-10T12:00:00Z"`, []string{"Deploy API", "Write docs", "Redeploy web"}},
+		{`priority > medium`, []string{"Deploy API", "Redeploy web"}},
+		{`priority in (LOW, URGENT)`, []string{"Write docs", "Redeploy web"}},
+		{`tags = ops and tags != docs`, []string{"Deploy API"}},
+		{`tags in (docs, misc)`, []string{"Write docs"}},
+		{`(status = DONE or priority = LOW) and title != "Write docs"`, []string{"Redeploy web"}},
+	}
+
+	for name, repo := range queryBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			for _, task := range seed {
+				copy := *task
+				require.NoError(t, repo.Create(ctx, &copy))
+			}
+
+			for _, tc := range cases {
+				expr, err := domain.ParseFilter(tc.filter)
+				require.NoError(t, err, tc.filter)
+				page, err := repo.Query(ctx, domain.TaskFilter{Expr: expr, Sort: []domain.SortKey{{Field: domain.SortByDueDate}}})
+				require.NoError(t, err, tc.filter)
+
+				var titles []string
+				for _, task := range page.Tasks {
+					titles = append(titles, task.Title)
+				}
+				assert.Equal(t, tc.want, titles, tc.filter)
+				assert.Equal(t, len(tc.want), page.Total, tc.filter)
+			}
+		})
+	}
+}
+
+// TestHandler_ListTasks_Filter tests the filter parameter and structured
+// parse errors
+func TestHandler_ListTasks_Filter(t *testing.T) {
+	app := newFiberTestApp()
+	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	postTask(t, app, map[string]any{"title": "Deploy API", "due_date": due})
+	postTask(t, app, map[string]any{"title": "Write docs", "status": "IN_PROGRESS", "due_date": due})
+
+	get := func(filter string) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, "/v1/tasks?filter="+url.QueryEscape(filter), nil)
+		resp, err := app.Test(req, 5000)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := get(`status in (PENDING, DONE) and title ~ "deploy"`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	items := listItems(t, body)
+	require.Len(t, items, 1)
+	assert.Equal(t, "Deploy API", items[0]["title"])
+
+	resp = get(`status = DONE and colour = red`)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	body, _ = io.ReadAll(resp.Body)
+	var problem map[string]any
+	require.NoError(t, json.Unmarshal(body, &problem))
+	assert.Equal(t, float64(19), problem["position"])
+	assert.Equal(t, `invalid filter at position 19: unknown field "colour"`, problem["detail"])
+	assert.Equal(t, "filter", problem["errors"].([]any)[0].(map[string]any)["field"])
+}
@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// sendJSON sends a request to app with body, if not nil, encoded as JSON
+func sendJSON(t *testing.T, app *fiber.App, method, path string, body any) *http.Response {
+	var r io.Reader
+	if body != nil {
+		b, _ := json.Marshal(body)
+		r = bytes.NewReader(b)
+	}
+	req, _ := http.NewRequest(method, path, r)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, 5000)
+	require.NoError(t, err)
+	return resp
+}
+
+// decodeBody decodes the body of resp into v
+func decodeBody(t *testing.T, resp *http.Response, v any) {
+	body, _ := io.ReadAll(resp.Body)
+	require.NoError(t, json.Unmarshal(body, v))
+}
+
+// postTaskStatus posts a task and returns the response status code
+func postTaskStatus(t *testing.T, app *fiber.App, body map[string]any) int {
+	return sendJSON(t, app, http.MethodPost, "/v1/tasks", body).StatusCode
+}
+
+// postTask posts a task that must be created and returns its JSON
+func postTask(t *testing.T, app *fiber.App, body map[string]any) map[string]any {
+	resp := sendJSON(t, app, http.MethodPost, "/v1/tasks", body)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var task map[string]any
+	decodeBody(t, resp, &task)
+	return task
+}
@@ -0,0 +1,258 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	"github.com/gauravpandey771/task-api/internal/repository"
+	httphandler "github.com/gauravpandey771/task-api/internal/transport/http"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type historyBackend struct {
+	repo    domain.TaskRepository
+	history domain.HistoryRepository
+}
+
+// historyBackends returns a task and history repository pair for each store
+func historyBackends(t *testing.T) map[string]historyBackend {
+	dir := t.TempDir()
+	fileRepo, err := repository.NewFileTaskRepository(dir, 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { fileRepo.Close() })
+	fileHistory, err := repository.NewFileHistoryRepository(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() { fileHistory.Close() })
+
+	sqliteRepo, err := repository.NewSQLiteTaskRepository(filepath.Join(t.TempDir(), "tasks.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { sqliteRepo.Close() })
+	sqliteHistory, err := sqliteRepo.History()
+	require.NoError(t, err)
+
+	return map[string]historyBackend{
+		"memory": {repository.NewInMemoryTaskRepository(), repository.NewInMemoryHistoryRepository()},
+		"file":   {fileRepo, fileHistory},
+		"sqlite": {sqliteRepo, sqliteHistory},
+	}
+}
+
+func changesByField(entry *domain.HistoryEntry) map[string]domain.FieldChange {
+	out := make(map[string]domain.FieldChange, len(entry.Changes))
+	for _, c := range entry.Changes {
+		out[c.Field] = c
+	}
+	return out
+}
+
+// TestHistory_Lifecycle tests entries and field diffs across every store
+func TestHistory_Lifecycle(t *testing.T) {
+	for name, backend := range historyBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			svc := domain.NewTaskService(backend.repo, domain.WithHistory(backend.history))
+			ctx := domain.WithActor(context.Background(), "alice")
+
+			task, err := svc.CreateTask(ctx, domain.CreateTaskInput{
+				Title:   "Write report",
+				DueDate: time.Now().Add(24 * time.Hour),
+				Tags:    []string{"work"},
+			})
+			require.NoError(t, err)
+
+			title := "Write final report"
+			status := domain.StatusInProgress
+			_, err = svc.UpdateTask(ctx, task.ID, domain.UpdateTaskInput{Title: &title, Status: &status})
+			require.NoError(t, err)
+			require.NoError(t, svc.DeleteTask(ctx, task.ID))
+			_, err = svc.RestoreTask(ctx, task.ID)
+			require.NoError(t, err)
+
+			entries, err := svc.GetHistory(ctx, task.ID)
+			require.NoError(t, err)
+			require.Len(t, entries, 4)
+
+			actions := []domain.HistoryAction{domain.HistoryCreated, domain.HistoryUpdated, domain.HistoryDeleted, domain.HistoryRestored}
+			for i, e := range entries {
+				assert.Equal(t, actions[i], e.Action)
+				assert.Equal(t, "alice", e.Actor)
+				assert.Equal(t, task.ID, e.TaskID)
+				assert.NotEmpty(t, e.ID)
+				assert.False(t, e.At.IsZero())
+			}
+
+			created := changesByField(entries[0])
+			assert.Nil(t, created["title"].Old)
+			assert.Equal(t, "Write report", created["title"].New)
+			assert.Equal(t, []any{"work"}, created["tags"].New)
+			assert.NotContains(t, created, "id")
+			assert.NotContains(t, created, "version")
+
+			updated := changesByField(entries[1])
+			require.Len(t, updated, 2)
+			assert.Equal(t, domain.FieldChange{Field: "title", Old: "Write report", New: "Write final report"}, updated["title"])
+			assert.Equal(t, domain.FieldChange{Field: "status", Old: "PENDING", New: "IN_PROGRESS"}, updated["status"])
+
+			deleted := changesByField(entries[2])
+			require.Len(t, deleted, 1)
+			assert.Nil(t, deleted["deleted_at"].Old)
+			assert.NotNil(t, deleted["deleted_at"].New)
+
+			restored := changesByField(entries[3])
+			require.Len(t, restored, 1)
+			assert.Nil(t, restored["deleted_at"].New)
+		})
+	}
+}
+
+// TestHistory_SurvivesPurge tests that history outlives the task
+func TestHistory_SurvivesPurge(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	svc := domain.NewTaskService(repo, domain.WithHistory(repository.NewInMemoryHistoryRepository()))
+	ctx := context.Background()
+
+	task := createChild(t, svc, "", "temporary")
+	require.NoError(t, svc.DeleteTask(ctx, task.ID))
+	require.NoError(t, svc.PurgeTask(ctx, task.ID))
+
+	entries, err := svc.GetHistory(ctx, task.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, domain.HistoryPurged, entries[2].Action)
+	assert.Empty(t, entries[2].Changes)
+	assert.Equal(t, "anonymous", entries[0].Actor)
+
+	_, err = svc.GetHistory(ctx, "missing")
+	assert.True(t, pkgerrors.IsNotFound(err))
+}
+
+// failingHistory is a history store that cannot store anything
+type failingHistory struct {
+	domain.HistoryRepository
+}
+
+func (failingHistory) Append(context.Context, *domain.HistoryEntry) error {
+	return errors.New("disk full")
+}
+
+// TestHistory_AppendFailure tests that a change is reported as made even
+// when its history cannot be recorded
+func TestHistory_AppendFailure(t *testing.T) {
+	svc := domain.NewTaskService(repository.NewInMemoryTaskRepository(), domain.WithHistory(failingHistory{}))
+	ctx := context.Background()
+
+	task := createChild(t, svc, "", "task")
+	title := "Renamed"
+	updated, err := svc.UpdateTask(ctx, task.ID, domain.UpdateTaskInput{Title: &title})
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", updated.Title)
+	require.NoError(t, svc.DeleteTask(ctx, task.ID))
+	require.NoError(t, svc.PurgeTask(ctx, task.ID))
+
+	results, err := svc.ApplyBatchAtomic(ctx, []domain.BatchOperation{
+		{Op: domain.BatchCreate, Create: domain.CreateTaskInput{Title: "New", DueDate: time.Now().Add(time.Hour)}},
+	})
+	require.NoError(t, err)
+	assert.NoError(t, results[0].Err)
+}
+
+// TestHistory_FileReplay tests that file history survives a restart and a
+// torn final line
+func TestHistory_FileReplay(t *testing.T) {
+	dir := t.TempDir()
+	history, err := repository.NewFileHistoryRepository(dir)
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, history.Append(ctx, &domain.HistoryEntry{TaskID: "t1", Action: domain.HistoryCreated, Actor: "bob", At: time.Now().UTC()}))
+	require.NoError(t, history.Close())
+
+	f, err := os.OpenFile(filepath.Join(dir, "history.log"), os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"id":"torn","task_id":"t1"`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	history, err = repository.NewFileHistoryRepository(dir)
+	require.NoError(t, err)
+	defer history.Close()
+
+	entries, err := history.ListByTask(ctx, "t1")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "bob", entries[0].Actor)
+}
+
+// TestHistory_FileUnterminatedLastLine tests that a final line that parses
+// but has no newline is dropped, so later appends are not joined onto it
+func TestHistory_FileUnterminatedLastLine(t *testing.T) {
+	dir := t.TempDir()
+	history, err := repository.NewFileHistoryRepository(dir)
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, history.Append(ctx, &domain.HistoryEntry{TaskID: "t1", Action: domain.HistoryCreated, Actor: "bob", At: time.Now().UTC()}))
+	require.NoError(t, history.Close())
+
+	f, err := os.OpenFile(filepath.Join(dir, "history.log"), os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"id":"torn","task_id":"t1","action":"updated","actor":"eve"}`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	history, err = repository.NewFileHistoryRepository(dir)
+	require.NoError(t, err)
+	require.NoError(t, history.Append(ctx, &domain.HistoryEntry{TaskID: "t1", Action: domain.HistoryUpdated, Actor: "alice", At: time.Now().UTC()}))
+	require.NoError(t, history.Close())
+
+	history, err = repository.NewFileHistoryRepository(dir)
+	require.NoError(t, err)
+	defer history.Close()
+	entries, err := history.ListByTask(ctx, "t1")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "bob", entries[0].Actor)
+	assert.Equal(t, "alice", entries[1].Actor)
+}
+
+// TestHistory_Endpoint tests GET /tasks/:id/history and the actor header
+func TestHistory_Endpoint(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	svc := domain.NewTaskService(repo, domain.WithHistory(repository.NewInMemoryHistoryRepository()))
+	app := httphandler.NewApp(httphandler.NewTaskHandler(svc))
+
+	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	task := postTask(t, app, map[string]any{"title": "Audit me", "due_date": due})
+
+	req, _ := http.NewRequest(http.MethodDelete, "/v1/tasks/"+task["id"].(string), nil)
+	req.Header.Set(httphandler.HeaderActor, "carol")
+	resp, err := app.Test(req, 5000)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	req, _ = http.NewRequest(http.MethodGet, "/v1/tasks/"+task["id"].(string)+"/history", nil)
+	resp, err = app.Test(req, 5000)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, _ := io.ReadAll(resp.Body)
+	var entries []map[string]any
+	require.NoError(t, json.Unmarshal(body, &entries))
+	require.Len(t, entries, 2)
+	assert.Equal(t, "created", entries[0]["action"])
+	assert.Equal(t, "anonymous", entries[0]["actor"])
+	assert.Equal(t, "deleted", entries[1]["action"])
+	assert.Equal(t, "carol", entries[1]["actor"])
+
+	req, _ = http.NewRequest(http.MethodGet, "/v1/tasks/missing/history", nil)
+	resp, err = app.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
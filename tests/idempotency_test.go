@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	httphandler "github.com/gauravpandey771/task-api/internal/transport/http"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// postIdempotent posts a raw body to /tasks with the given headers
+func postIdempotent(t *testing.T, app *fiber.App, body string, headers ...string) (*http.Response, map[string]any) {
+	req, _ := http.NewRequest(http.MethodPost, "/v1/tasks", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	resp, err := app.Test(req, 5000)
+	require.NoError(t, err)
+	respBody, _ := io.ReadAll(resp.Body)
+	var out map[string]any
+	json.Unmarshal(respBody, &out)
+	return resp, out
+}
+
+func countTasks(t *testing.T, svc domain.TaskService) int {
+	tasks, err := svc.ListTasks(context.Background(), domain.TaskFilter{})
+	require.NoError(t, err)
+	return len(tasks)
+}
+
+// TestHandler_IdempotencyKey tests replays, mismatches and released keys
+func TestHandler_IdempotencyKey(t *testing.T) {
+	svc := newTestService()
+	app := httphandler.NewApp(httphandler.NewTaskHandler(svc))
+	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	body := `{"title": "Task", "due_date": "` + due + `"}`
+
+	first, created := postIdempotent(t, app, body, "Idempotency-Key", "k1")
+	require.Equal(t, http.StatusCreated, first.StatusCode)
+	assert.Empty(t, first.Header.Get("Idempotent-Replayed"))
+
+	// Key order and whitespace do not change the fingerprint
+	retry, replayed := postIdempotent(t, app, `{"due_date":"`+due+`","title":"Task"}`, "Idempotency-Key", "k1")
+	require.Equal(t, http.StatusCreated, retry.StatusCode)
+	assert.Equal(t, "true", retry.Header.Get("Idempotent-Replayed"))
+	assert.Equal(t, `"1"`, retry.Header.Get("ETag"))
+	assert.Equal(t, "application/json", retry.Header.Get("Content-Type"))
+	assert.Equal(t, created, replayed)
+	assert.Equal(t, 1, countTasks(t, svc))
+
+	resp, out := postIdempotent(t, app, `{"title": "Other", "due_date": "`+due+`"}`, "Idempotency-Key", "k1")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	assert.NotEmpty(t, out["detail"])
+
+	// Keys are scoped to the actor
+	resp, _ = postIdempotent(t, app, body, "Idempotency-Key", "k1", "X-Actor", "alice")
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("Idempotent-Replayed"))
+	assert.Equal(t, 2, countTasks(t, svc))
+
+	// A failed request does not use up its key
+	resp, _ = postIdempotent(t, app, `{"title": ""}`, "Idempotency-Key", "k2")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp, _ = postIdempotent(t, app, body, "Idempotency-Key", "k2")
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("Idempotent-Replayed"))
+
+	// Requests without a key are never deduplicated
+	postIdempotent(t, app, body)
+	postIdempotent(t, app, body)
+	assert.Equal(t, 5, countTasks(t, svc))
+
+	resp, _ = postIdempotent(t, app, body, "Idempotency-Key", strings.Repeat("k", 256))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+// TestHandler_IdempotencyAcrossVersions tests that a retry through /v1
+// replays a request made to the legacy path
+func TestHandler_IdempotencyAcrossVersions(t *testing.T) {
+	svc := newTestService()
+	app := httphandler.NewApp(httphandler.NewTaskHandler(svc))
+	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	body := `{"title": "Task", "due_date": "` + due + `"}`
+
+	req, _ := http.NewRequest(http.MethodPost, "/tasks", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", "k")
+	first, err := app.Test(req, 5000)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, first.StatusCode)
+
+	retry, _ := postIdempotent(t, app, body, "Idempotency-Key", "k")
+	assert.Equal(t, http.StatusCreated, retry.StatusCode)
+	assert.Equal(t, "true", retry.Header.Get("Idempotent-Replayed"))
+	assert.Equal(t, 1, countTasks(t, svc))
+}
+
+// TestHandler_IdempotencyTTL tests expiry and disabling idempotency
+func TestHandler_IdempotencyTTL(t *testing.T) {
+	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	body := `{"title": "Task", "due_date": "` + due + `"}`
+
+	svc := newTestService()
+	app := httphandler.NewApp(httphandler.NewTaskHandler(svc, httphandler.WithIdempotencyTTL(50*time.Millisecond)))
+	postIdempotent(t, app, body, "Idempotency-Key", "k")
+	resp, _ := postIdempotent(t, app, body, "Idempotency-Key", "k")
+	assert.Equal(t, "true", resp.Header.Get("Idempotent-Replayed"))
+	time.Sleep(100 * time.Millisecond)
+	resp, _ = postIdempotent(t, app, `{"title": "Changed", "due_date": "`+due+`"}`, "Idempotency-Key", "k")
+	assert.Equal(t, http.StatusCreated, resp.StatusCode, "an expired key can be reused")
+	assert.Equal(t, 2, countTasks(t, svc))
+
+	svc = newTestService()
+	app = httphandler.NewApp(httphandler.NewTaskHandler(svc, httphandler.WithIdempotencyTTL(0)))
+	postIdempotent(t, app, body, "Idempotency-Key", "k")
+	postIdempotent(t, app, body, "Idempotency-Key", "k")
+	assert.Equal(t, 2, countTasks(t, svc))
+}
+
+// blockingService holds CreateTask until release is closed
+type blockingService struct {
+	domain.TaskService
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingService) CreateTask(ctx context.Context, input domain.CreateTaskInput) (*domain.Task, error) {
+	close(s.started)
+	<-s.release
+	return s.TaskService.CreateTask(ctx, input)
+}
+
+// TestHandler_IdempotencyInFlight tests a retry racing the first request
+func TestHandler_IdempotencyInFlight(t *testing.T) {
+	svc := &blockingService{TaskService: newTestService(), started: make(chan struct{}), release: make(chan struct{})}
+	app := httphandler.NewApp(httphandler.NewTaskHandler(svc))
+	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	body := `{"title": "Task", "due_date": "` + due + `"}`
+
+	done := make(chan int)
+	go func() {
+		req, _ := http.NewRequest(http.MethodPost, "/v1/tasks", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", "k")
+		resp, err := app.Test(req, 5000)
+		if err != nil {
+			done <- 0
+			return
+		}
+		done <- resp.StatusCode
+	}()
+
+	<-svc.started
+	resp, _ := postIdempotent(t, app, body, "Idempotency-Key", "k")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	close(svc.release)
+	assert.Equal(t, http.StatusCreated, <-done)
+
+	resp, _ = postIdempotent(t, app, body, "Idempotency-Key", "k")
+	assert.Equal(t, "true", resp.Header.Get("Idempotent-Replayed"))
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	"github.com/gauravpandey771/task-api/internal/repository"
+	httphandler "github.com/gauravpandey771/task-api/internal/transport/http"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// getOpenAPI fetches and decodes the v1 OpenAPI document of app
+func getOpenAPI(t *testing.T, app *fiber.App) map[string]any {
+	req, _ := http.NewRequest(http.MethodGet, "/v1/openapi.json", nil)
+	resp, err := app.Test(req, 5000)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, fiber.MIMEApplicationJSON, resp.Header.Get("Content-Type"))
+	body, _ := io.ReadAll(resp.Body)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(body, &doc))
+	return doc
+}
+
+// TestOpenAPI_CoversRoutes fails for routes missing from the OpenAPI document
+func TestOpenAPI_CoversRoutes(t *testing.T) {
+	app := newFiberTestApp()
+	doc := getOpenAPI(t, app)
+	assert.Equal(t, "3.1.0", doc["openapi"])
+	paths := doc["paths"].(map[string]any)
+
+	// Legacy routes alias those of v1
+	version := regexp.MustCompile(`^/v1/`)
+	param := regexp.MustCompile(`(^|[^\\]):(\w+)`)
+	for _, r := range app.GetRoutes(true) {
+		if r.Method == fiber.MethodHead {
+			continue
+		}
+		path := version.ReplaceAllString(r.Path, "/")
+		path = strings.ReplaceAll(param.ReplaceAllString(path, "$1{$2}"), `\`, "")
+		item, ok := paths[path].(map[string]any)
+		if assert.True(t, ok, "no spec entry for %s %s", r.Method, path) {
+			assert.Contains(t, item, strings.ToLower(r.Method), "no spec entry for %s %s", r.Method, path)
+		}
+	}
+}
+
+// TestOpenAPI_Schemas tests schemas derived from the DTOs and domain.Task
+func TestOpenAPI_Schemas(t *testing.T) {
+	doc := getOpenAPI(t, newFiberTestApp())
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+
+	task := schemas["Task"].(map[string]any)
+	assert.Contains(t, task["required"], "id")
+	assert.NotContains(t, task["required"], "deleted_at", "omitempty fields are optional")
+	props := task["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "string", "format": "date-time"}, props["due_date"])
+	assert.Equal(t, map[string]any{"$ref": "#/components/schemas/TaskStatus"}, props["status"])
+	assert.Equal(t, []any{"PENDING", "IN_PROGRESS", "DONE"}, schemas["TaskStatus"].(map[string]any)["enum"])
+
+	create := schemas["CreateTaskRequest"].(map[string]any)
+	assert.ElementsMatch(t, []any{"title", "due_date"}, create["required"])
+	assert.Equal(t, false, create["additionalProperties"])
+	assert.Equal(t, "date-time", create["properties"].(map[string]any)["due_date"].(map[string]any)["format"])
+
+	op := doc["paths"].(map[string]any)["/tasks/{id}"].(map[string]any)["put"].(map[string]any)
+	assert.Equal(t, "replaceTask", op["operationId"])
+	params := op["parameters"].([]any)
+	assert.Equal(t, map[string]any{"name": "id", "in": "path", "required": true, "schema": map[string]any{"type": "string"}}, params[0])
+	responses := op["responses"].(map[string]any)
+	assert.Contains(t, responses, "200")
+	assert.Equal(t, map[string]any{"$ref": "#/components/responses/Problem"}, responses["default"])
+
+	assert.Contains(t, doc["paths"], "/tasks:batch")
+	update := schemas["UpdateTaskRequest"].(map[string]any)
+	assert.Equal(t, "date-time", update["properties"].(map[string]any)["due_date"].(map[string]any)["format"])
+	assert.Len(t, schemas["BatchOperationRequest"].(map[string]any)["allOf"], 2, "task is described by op")
+}
+
+// TestOpenAPI_Workflow tests that the documented statuses follow the workflow
+func TestOpenAPI_Workflow(t *testing.T) {
+	workflow, err := domain.NewWorkflow(domain.WorkflowConfig{
+		Initial: "TODO",
+		States:  []domain.TaskStatus{"TODO", "REVIEW"},
+	})
+	require.NoError(t, err)
+	svc := domain.NewTaskService(repository.NewInMemoryTaskRepository(), domain.WithWorkflow(workflow))
+	app := httphandler.NewApp(httphandler.NewTaskHandler(svc, httphandler.WithWorkflow(workflow)))
+
+	schemas := getOpenAPI(t, app)["components"].(map[string]any)["schemas"].(map[string]any)
+	assert.Equal(t, []any{"TODO", "REVIEW"}, schemas["TaskStatus"].(map[string]any)["enum"])
+}
+
+// TestDocsPage tests the page for browsing the OpenAPI document
+func TestDocsPage(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/v1/docs", nil)
+	resp, err := newFiberTestApp().Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "openapi.json")
+	assert.NotContains(t, string(body), "swagger-ui-dist@5/", "the Swagger UI version is pinned")
+	assert.Contains(t, resp.Header.Get("Content-Security-Policy"), "script-src https://unpkg.com/swagger-ui-dist@5.17.14/ 'sha256-")
+}
@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// taskList is the response envelope of GET /tasks
+type taskList struct {
+	Items      []map[string]any `json:"items"`
+	Total      int              `json:"total"`
+	NextCursor string           `json:"next_cursor"`
+}
+
+// listItems decodes a GET /tasks response and returns its items
+func listItems(t *testing.T, body []byte) []map[string]any {
+	var list taskList
+	require.NoError(t, json.Unmarshal(body, &list))
+	return list.Items
+}
+
+// TestRepositoryQuery_CursorSurvivesChanges tests that a cursor keeps its
+// position when tasks are inserted before it or the cursor task is removed
+func TestRepositoryQuery_CursorSurvivesChanges(t *testing.T) {
+	for name, repo := range queryBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			seeded := seedTasks(t, repo, 5)
+
+			first, err := repo.Query(ctx, domain.TaskFilter{PageSize: 2})
+			require.NoError(t, err)
+			require.Equal(t, ids(seeded[:2]), ids(first.Tasks))
+
+			early := &domain.Task{Title: "early", Status: domain.StatusPending, DueDate: seeded[0].DueDate.Add(-time.Hour)}
+			require.NoError(t, repo.Create(ctx, early))
+			require.NoError(t, repo.Delete(ctx, seeded[1].ID))
+
+			next, err := repo.Query(ctx, domain.TaskFilter{PageSize: 2, Cursor: first.NextCursor})
+			require.NoError(t, err)
+			assert.Equal(t, ids(seeded[2:4]), ids(next.Tasks))
+			assert.Equal(t, 5, next.Total)
+		})
+	}
+}
+
+// TestCursor_Invalid tests rejecting malformed cursors and cursors issued
+// for another sort order
+func TestCursor_Invalid(t *testing.T) {
+	task := &domain.Task{ID: "a", Title: "x", DueDate: time.Now()}
+	token := domain.EncodeCursor(task, domain.DefaultSort)
+
+	after, err := domain.DecodeCursor(token, domain.DefaultSort)
+	require.NoError(t, err)
+	assert.Equal(t, "a", after.ID)
+	assert.True(t, after.DueDate.Equal(task.DueDate))
+
+	for _, bad := range []string{"a", "!!!", token[:len(token)-2]} {
+		_, err := domain.DecodeCursor(bad, domain.DefaultSort)
+		assert.True(t, pkgerrors.IsValidation(err), bad)
+	}
+	_, err = domain.DecodeCursor(token, []domain.SortKey{{Field: domain.SortByTitle}})
+	assert.True(t, pkgerrors.IsValidation(err))
+}
+
+// TestHandler_ListTasks_Cursor tests the envelope, Link headers and walking
+// pages by cursor
+func TestHandler_ListTasks_Cursor(t *testing.T) {
+	app := newFiberTestApp()
+	base := time.Now().Add(24 * time.Hour).UTC()
+	for i := 0; i < 5; i++ {
+		postTask(t, app, map[string]any{"title": "Task", "due_date": base.Add(time.Duration(i) * time.Hour).Format(time.RFC3339)})
+	}
+
+	get := func(target string) (*http.Response, taskList) {
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		resp, err := app.Test(req, 5000)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		var list taskList
+		require.NoError(t, json.Unmarshal(body, &list))
+		return resp, list
+	}
+
+	resp, list := get("/v1/tasks?page_size=2&status=PENDING")
+	assert.Equal(t, 5, list.Total)
+	assert.Len(t, list.Items, 2)
+	require.NotEmpty(t, list.NextCursor)
+
+	next := url.Values{"cursor": {list.NextCursor}, "page_size": {"2"}, "status": {"PENDING"}}
+	links := resp.Header.Get("Link")
+	assert.Contains(t, links, `</v1/tasks?page_size=2&status=PENDING>; rel="first"`)
+	assert.Contains(t, links, "</v1/tasks?"+next.Encode()+`>; rel="next"`)
+
+	seen := 0
+	target := "/v1/tasks?page_size=2&status=PENDING"
+	for {
+		resp, list := get(target)
+		seen += len(list.Items)
+		if list.NextCursor == "" {
+			assert.NotContains(t, resp.Header.Get("Link"), `rel="next"`)
+			break
+		}
+		link := strings.Split(resp.Header.Get("Link"), ", ")[1]
+		target = strings.TrimSuffix(strings.TrimPrefix(link, "<"), `>; rel="next"`)
+	}
+	assert.Equal(t, 5, seen)
+
+	// Offset paging still works and returns the same envelope
+	_, list = get("/v1/tasks?page=3&page_size=2")
+	assert.Len(t, list.Items, 1)
+	assert.Empty(t, list.NextCursor)
+
+	req, _ := http.NewRequest(http.MethodGet, "/v1/tasks?cursor=bogus", nil)
+	badResp, _ := app.Test(req, 5000)
+	assert.Equal(t, http.StatusBadRequest, badResp.StatusCode)
+}
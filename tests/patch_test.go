@@ -0,0 +1,192 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gauravpandey771/task-api/pkg/jsonpatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeJSON(t *testing.T, s string) any {
+	var v any
+	require.NoError(t, json.Unmarshal([]byte(s), &v))
+	return v
+}
+
+// TestMergePatch tests the RFC 7396 merge rules
+func TestMergePatch(t *testing.T) {
+	target := decodeJSON(t, `{"a": "b", "c": {"d": "e", "f": "g"}, "tags": ["x"]}`)
+	patch := decodeJSON(t, `{"a": "z", "c": {"f": null}, "tags": [], "n": 1}`)
+
+	got := jsonpatch.MergePatch(target, patch)
+	assert.Equal(t, decodeJSON(t, `{"a": "z", "c": {"d": "e"}, "tags": [], "n": 1}`), got)
+	assert.Equal(t, decodeJSON(t, `{"a": "b", "c": {"d": "e", "f": "g"}, "tags": ["x"]}`), target, "target is not modified")
+	assert.Equal(t, "x", jsonpatch.MergePatch(target, "x"), "a non-object patch replaces the target")
+}
+
+// TestApply tests the RFC 6902 operations
+func TestApply(t *testing.T) {
+	ops := func(s string) []jsonpatch.Operation {
+		var out []jsonpatch.Operation
+		require.NoError(t, json.Unmarshal([]byte(s), &out))
+		return out
+	}
+	doc := decodeJSON(t, `{"title": "a", "tags": ["x", "y"], "a~b": {"c/d": 1}}`)
+
+	got, err := jsonpatch.Apply(doc, ops(`[
+		{"op": "test", "path": "/title", "value": "a"},
+		{"op": "replace", "path": "/title", "value": "b"},
+		{"op": "add", "path": "/tags/1", "value": "w"},
+		{"op": "add", "path": "/tags/-", "value": "z"},
+		{"op": "remove", "path": "/tags/0"},
+		{"op": "copy", "from": "/title", "path": "/copy"},
+		{"op": "move", "from": "/a~0b/c~1d", "path": "/moved"}
+	]`))
+	require.NoError(t, err)
+	assert.Equal(t, decodeJSON(t, `{"title": "b", "tags": ["w", "y", "z"], "a~b": {}, "copy": "b", "moved": 1}`), got)
+	assert.Equal(t, decodeJSON(t, `{"title": "a", "tags": ["x", "y"], "a~b": {"c/d": 1}}`), doc, "doc is not modified")
+
+	_, err = jsonpatch.Apply(doc, ops(`[{"op": "test", "path": "/title", "value": "nope"}]`))
+	assert.ErrorIs(t, err, jsonpatch.ErrTestFailed)
+
+	for _, bad := range []string{
+		`[{"op": "replace", "path": "/missing", "value": 1}]`,
+		`[{"op": "remove", "path": "/tags/5"}]`,
+		`[{"op": "add", "path": "/tags/01", "value": 1}]`,
+		`[{"op": "add", "path": "title", "value": 1}]`,
+		`[{"op": "add", "path": "/title"}]`,
+		`[{"op": "move", "from": "/a~0b", "path": "/a~0b/inner"}]`,
+		`[{"op": "frobnicate", "path": "/title"}]`,
+	} {
+		_, err := jsonpatch.Apply(doc, ops(bad))
+		assert.Error(t, err, bad)
+		assert.NotErrorIs(t, err, jsonpatch.ErrTestFailed, bad)
+	}
+}
+
+// TestHandler_PatchTask tests PATCH /tasks/:id with both patch formats
+func TestHandler_PatchTask(t *testing.T) {
+	app := newFiberTestApp()
+	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	created := postTask(t, app, map[string]any{
+		"title": "Task", "description": "details", "priority": "HIGH", "tags": []string{"home"}, "due_date": due,
+	})
+	id := created["id"].(string)
+
+	patch := func(contentType, body string, headers ...string) (int, map[string]any) {
+		req, _ := http.NewRequest(http.MethodPatch, "/v1/tasks/"+id, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", contentType)
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		resp, err := app.Test(req, 5000)
+		require.NoError(t, err)
+		respBody, _ := io.ReadAll(resp.Body)
+		var out map[string]any
+		json.Unmarshal(respBody, &out)
+		return resp.StatusCode, out
+	}
+
+	// Merge patch: null clears a field, omitted fields are kept
+	status, task := patch("application/merge-patch+json", `{"title": "Renamed", "description": null}`)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "Renamed", task["title"])
+	assert.Nil(t, task["description"])
+	assert.Equal(t, "HIGH", task["priority"])
+	assert.Equal(t, []any{"home"}, task["tags"])
+	assert.Equal(t, float64(2), task["version"])
+
+	// JSON Patch, guarded by a test operation
+	status, task = patch("application/json-patch+json", `[
+		{"op": "test", "path": "/version", "value": 2},
+		{"op": "add", "path": "/tags/-", "value": "errand"},
+		{"op": "replace", "path": "/status", "value": "IN_PROGRESS"}
+	]`)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []any{"errand", "home"}, task["tags"])
+	assert.Equal(t, "IN_PROGRESS", task["status"])
+
+	status, _ = patch("application/json-patch+json", `[{"op": "test", "path": "/version", "value": 2}]`)
+	assert.Equal(t, http.StatusConflict, status)
+	status, _ = patch("application/json-patch+json", `[{"op": "replace", "path": "/missing", "value": 1}]`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	// Read-only and unknown fields
+	status, task = patch("application/merge-patch+json", `{"version": 99}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "version is read-only", task["detail"])
+	status, _ = patch("application/json-patch+json", `[{"op": "remove", "path": "/id"}]`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = patch("application/merge-patch+json", `{"blocked_by": ["x"]}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = patch("application/merge-patch+json", `{"colour": "red"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	// Invalid values are validated like any other write
+	status, _ = patch("application/merge-patch+json", `{"due_date": ""}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = patch("application/merge-patch+json", `{"due_date": null}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = patch("application/merge-patch+json", `{"title": 5}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = patch("application/merge-patch+json", `[]`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	// Content types and preconditions
+	status, _ = patch("application/json", `{"title": "x"}`)
+	assert.Equal(t, http.StatusUnsupportedMediaType, status)
+	status, _ = patch("application/merge-patch+json", `{"title": "x"}`, "If-Match", `"1"`)
+	assert.Equal(t, http.StatusPreconditionFailed, status)
+	status, task = patch("application/merge-patch+json; charset=utf-8", `{"title": "x"}`, "If-Match", `"3"`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "x", task["title"])
+
+	req, _ := http.NewRequest(http.MethodPatch, "/v1/tasks/missing", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	resp, _ := app.Test(req, 5000)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+// TestHandler_ReplaceTask tests that PUT resets omitted fields and rejects
+// an empty due date
+func TestHandler_ReplaceTask(t *testing.T) {
+	app := newFiberTestApp()
+	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	created := postTask(t, app, map[string]any{
+		"title": "Task", "description": "details", "priority": "HIGH", "status": "IN_PROGRESS", "tags": []string{"home"}, "due_date": due,
+	})
+	id := created["id"].(string)
+
+	put := func(body map[string]any) (int, map[string]any) {
+		b, _ := json.Marshal(body)
+		req, _ := http.NewRequest(http.MethodPut, "/v1/tasks/"+id, bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, 5000)
+		require.NoError(t, err)
+		respBody, _ := io.ReadAll(resp.Body)
+		var out map[string]any
+		json.Unmarshal(respBody, &out)
+		return resp.StatusCode, out
+	}
+
+	status, task := put(map[string]any{"title": "Replaced", "due_date": due})
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "Replaced", task["title"])
+	assert.Nil(t, task["description"])
+	assert.Nil(t, task["tags"])
+	assert.Equal(t, "MEDIUM", task["priority"])
+	assert.Equal(t, "PENDING", task["status"])
+
+	status, _ = put(map[string]any{"title": "Replaced", "due_date": ""})
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = put(map[string]any{"title": "Replaced"})
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = put(map[string]any{"due_date": due})
+	assert.Equal(t, http.StatusBadRequest, status)
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func priorityPtr(p domain.Priority) *domain.Priority {
+	return &p
+}
+
+// TestCreateTask_Priority tests default and validated priorities
+func TestCreateTask_Priority(t *testing.T) {
+	svc := newTestService()
+	ctx := context.Background()
+	due := time.Now().Add(24 * time.Hour)
+
+	task, err := svc.CreateTask(ctx, domain.CreateTaskInput{Title: "Default", DueDate: due})
+	require.NoError(t, err)
+	assert.Equal(t, domain.PriorityMedium, task.Priority)
+
+	task, err = svc.CreateTask(ctx, domain.CreateTaskInput{Title: "Urgent", Priority: priorityPtr(domain.PriorityUrgent), DueDate: due})
+	require.NoError(t, err)
+	assert.Equal(t, domain.PriorityUrgent, task.Priority)
+
+	_, err = svc.CreateTask(ctx, domain.CreateTaskInput{Title: "Bad", Priority: priorityPtr("CRITICAL"), DueDate: due})
+	assert.True(t, pkgerrors.IsValidation(err))
+	assert.Equal(t, domain.ErrPriorityInvalid, err.Error())
+
+	_, err = svc.UpdateTask(ctx, task.ID, domain.UpdateTaskInput{Priority: priorityPtr("")})
+	assert.True(t, pkgerrors.IsValidation(err))
+
+	updated, err := svc.UpdateTask(ctx, task.ID, domain.UpdateTaskInput{Priority: priorityPtr(domain.PriorityLow)})
+	require.NoError(t, err)
+	assert.Equal(t, domain.PriorityLow, updated.Priority)
+}
+
+// TestListTasks_PriorityOrderingAndFilter tests default ordering and filtering on every backend
+func TestListTasks_PriorityOrderingAndFilter(t *testing.T) {
+	for name, repo := range queryBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			svc := domain.NewTaskService(repo)
+			ctx := context.Background()
+			due := time.Now().Add(24 * time.Hour)
+
+			create := func(title string, p domain.Priority, offset time.Duration) {
+				_, err := svc.CreateTask(ctx, domain.CreateTaskInput{Title: title, Priority: &p, DueDate: due.Add(offset)})
+				require.NoError(t, err)
+			}
+			create("low", domain.PriorityLow, 0)
+			create("high-late", domain.PriorityHigh, 2*time.Hour)
+			create("urgent", domain.PriorityUrgent, 5*time.Hour)
+			create("high-early", domain.PriorityHigh, time.Hour)
+			create("medium", domain.PriorityMedium, 0)
+
+			var titles []string
+			cursor := ""
+			for {
+				page, err := repo.Query(ctx, domain.TaskFilter{PageSize: 2, Cursor: cursor})
+				require.NoError(t, err)
+				assert.Equal(t, 5, page.Total)
+				for _, task := range page.Tasks {
+					titles = append(titles, task.Title)
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				cursor = page.NextCursor
+			}
+			assert.Equal(t, []string{"urgent", "high-early", "high-late", "medium", "low"}, titles)
+
+			tasks, err := svc.ListTasks(ctx, domain.TaskFilter{Priorities: []domain.Priority{domain.PriorityLow, domain.PriorityHigh}})
+			require.NoError(t, err)
+			require.Len(t, tasks, 3)
+			assert.Equal(t, "high-early", tasks[0].Title)
+			assert.Equal(t, "low", tasks[2].Title)
+
+			_, err = svc.ListTasks(ctx, domain.TaskFilter{Priorities: []domain.Priority{"NOPE"}})
+			assert.True(t, pkgerrors.IsValidation(err))
+		})
+	}
+}
+
+// TestHandler_Priority tests priority in DTOs and the priority query parameter
+func TestHandler_Priority(t *testing.T) {
+	app := newFiberTestApp()
+	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	for _, p := range []string{"LOW", "HIGH", "URGENT"} {
+		b, _ := json.Marshal(map[string]any{"title": p, "priority": p, "due_date": due})
+		req, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req, 5000)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+
+	b, _ := json.Marshal(map[string]any{"title": "bad", "priority": "CRITICAL", "due_date": due})
+	badReq, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader(b))
+	badReq.Header.Set("Content-Type", "application/json")
+	badResp, _ := app.Test(badReq, 5000)
+	assert.Equal(t, http.StatusBadRequest, badResp.StatusCode)
+
+	for _, query := range []string{"priority=LOW&priority=URGENT", "priority=LOW,URGENT"} {
+		req, _ := http.NewRequest(http.MethodGet, "/v1/tasks?"+query, nil)
+		resp, _ := app.Test(req, 5000)
+		body, _ := io.ReadAll(resp.Body)
+		tasks := listItems(t, body)
+		require.Len(t, tasks, 2, query)
+		assert.Equal(t, "URGENT", tasks[0]["priority"])
+		assert.Equal(t, "LOW", tasks[1]["priority"])
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/v1/tasks?priority=NOPE", nil)
+	resp, _ := app.Test(req, 5000)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	httphandler "github.com/gauravpandey771/task-api/internal/transport/http"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type problemResponse struct {
+	Type     string                 `json:"type"`
+	Title    string                 `json:"title"`
+	Status   int                    `json:"status"`
+	Detail   string                 `json:"detail"`
+	Instance string                 `json:"instance"`
+	Code     string                 `json:"code"`
+	Errors   []pkgerrors.FieldError `json:"errors"`
+}
+
+// TestNewFieldErrors tests the code and message of field errors
+func TestNewFieldErrors(t *testing.T) {
+	err := pkgerrors.NewFieldErrors(pkgerrors.FieldError{Field: "title", Code: domain.CodeTitleRequired, Message: domain.ErrTitleRequired})
+	assert.True(t, pkgerrors.IsValidation(err))
+	assert.Equal(t, domain.ErrTitleRequired, err.Error())
+	assert.Equal(t, domain.CodeTitleRequired, err.(*pkgerrors.AppError).Code)
+
+	err = pkgerrors.NewFieldErrors(
+		pkgerrors.FieldError{Field: "title", Code: domain.CodeTitleRequired, Message: domain.ErrTitleRequired},
+		pkgerrors.FieldError{Field: "priority", Code: domain.CodePriorityInvalid, Message: domain.ErrPriorityInvalid},
+	)
+	assert.Equal(t, domain.ErrTitleRequired+"; "+domain.ErrPriorityInvalid, err.Error())
+	assert.Equal(t, pkgerrors.CodeValidationFailed, err.(*pkgerrors.AppError).Code)
+	assert.Equal(t, http.StatusBadRequest, err.(*pkgerrors.AppError).Status())
+}
+
+// TestHandler_ProblemDetails tests that errors are rendered as problem+json
+func TestHandler_ProblemDetails(t *testing.T) {
+	app := newFiberTestApp()
+	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+
+	send := func(method, path string, body any, headers ...string) problemResponse {
+		var r io.Reader
+		if body != nil {
+			b, _ := json.Marshal(body)
+			r = bytes.NewReader(b)
+		}
+		req, _ := http.NewRequest(method, path, r)
+		req.Header.Set("Content-Type", "application/json")
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		resp, err := app.Test(req, 5000)
+		require.NoError(t, err)
+		assert.Equal(t, httphandler.MIMEProblem, resp.Header.Get("Content-Type"))
+		var p problemResponse
+		respBody, _ := io.ReadAll(resp.Body)
+		require.NoError(t, json.Unmarshal(respBody, &p))
+		assert.Equal(t, resp.StatusCode, p.Status)
+		assert.Equal(t, httphandler.ProblemTypePrefix+p.Code, p.Type)
+		assert.Equal(t, http.StatusText(p.Status), p.Title)
+		return p
+	}
+
+	// Every invalid field is reported at once
+	p := send(http.MethodPost, "/v1/tasks", map[string]any{"title": "", "priority": "CRITICAL"})
+	assert.Equal(t, http.StatusBadRequest, p.Status)
+	assert.Equal(t, pkgerrors.CodeValidationFailed, p.Code)
+	assert.Equal(t, "/v1/tasks", p.Instance)
+	assert.Equal(t, []pkgerrors.FieldError{
+		{Field: "title", Code: domain.CodeTitleRequired, Message: domain.ErrTitleRequired},
+		{Field: "due_date", Code: domain.CodeDueDateRequired, Message: domain.ErrDueDateRequired},
+		{Field: "priority", Code: domain.CodePriorityInvalid, Message: domain.ErrPriorityInvalid},
+	}, p.Errors)
+
+	// A single invalid field lends the problem its code
+	p = send(http.MethodPost, "/v1/tasks", map[string]any{"title": "Task", "due_date": "tomorrow"})
+	assert.Equal(t, domain.CodeDueDateInvalid, p.Code)
+	require.Len(t, p.Errors, 1)
+	assert.Equal(t, "due_date", p.Errors[0].Field)
+
+	p = send(http.MethodGet, "/v1/tasks/missing?x=1", nil)
+	assert.Equal(t, http.StatusNotFound, p.Status)
+	assert.Equal(t, domain.CodeTaskNotFound, p.Code)
+	assert.Equal(t, domain.ErrTaskNotFound, p.Detail)
+	assert.Equal(t, "/v1/tasks/missing?x=1", p.Instance)
+	assert.NotNil(t, p.Errors)
+	assert.Empty(t, p.Errors)
+
+	// Errors raised by the transport are named after their status
+	p = send(http.MethodGet, "/nowhere", nil)
+	assert.Equal(t, http.StatusNotFound, p.Status)
+	assert.Equal(t, "not_found", p.Code)
+
+	task := postTask(t, app, map[string]any{"title": "Task", "due_date": due})
+	p = send(http.MethodPut, "/v1/tasks/"+task["id"].(string), map[string]any{"title": "New", "due_date": due}, "If-Match", `"7"`)
+	assert.Equal(t, http.StatusPreconditionFailed, p.Status)
+	assert.Equal(t, domain.CodeVersionConflict, p.Code)
+}
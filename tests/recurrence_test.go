@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	"github.com/gauravpandey771/task-api/internal/repository"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 9, 0, 0, 0, time.UTC)
+}
+
+// TestParseRecurrence tests parsing, canonical formatting and rejection
+func TestParseRecurrence(t *testing.T) {
+	r, err := domain.ParseRecurrence("rrule:freq=weekly;byday=th,mo;interval=2;count=3")
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;COUNT=3", r.String())
+
+	r, err = domain.ParseRecurrence("FREQ=DAILY;UNTIL=20300101")
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=DAILY;UNTIL=20300101T235959Z", r.String())
+
+	for _, bad := range []string{
+		"", "FREQ=HOURLY", "INTERVAL=2", "FREQ=DAILY;INTERVAL=0", "FREQ=DAILY;COUNT=x",
+		"FREQ=DAILY;COUNT=2;UNTIL=20300101", "FREQ=MONTHLY;BYDAY=MO", "FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=DAILY;FREQ=WEEKLY", "FREQ=DAILY;BYSETPOS=1", "FREQ=DAILY;UNTIL=tomorrow",
+		"FREQ=DAILY;INTERVAL=7;BYDAY=MO", "FREQ=DAILY;INTERVAL=14;BYDAY=TU,TH",
+	} {
+		_, err := domain.ParseRecurrence(bad)
+		assert.True(t, pkgerrors.IsValidation(err), bad)
+	}
+}
+
+// TestRecurrence_Occurrences tests next-date computation for each frequency
+func TestRecurrence_Occurrences(t *testing.T) {
+	cases := []struct {
+		rule  string
+		start time.Time
+		want  []time.Time
+	}{
+		{"FREQ=DAILY;INTERVAL=3", date(2030, 1, 30), []time.Time{date(2030, 2, 2), date(2030, 2, 5)}},
+		// 2030-01-02 is a Wednesday
+		{"FREQ=DAILY;BYDAY=MO,WE,FR", date(2030, 1, 2), []time.Time{date(2030, 1, 4), date(2030, 1, 7), date(2030, 1, 9)}},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", date(2030, 1, 2), []time.Time{date(2030, 1, 3), date(2030, 1, 14), date(2030, 1, 17)}},
+		{"FREQ=WEEKLY", date(2030, 1, 2), []time.Time{date(2030, 1, 9), date(2030, 1, 16)}},
+		{"FREQ=MONTHLY", date(2030, 1, 31), []time.Time{date(2030, 3, 31), date(2030, 5, 31)}},
+		{"FREQ=YEARLY", date(2028, 2, 29), []time.Time{date(2032, 2, 29)}},
+		{"FREQ=DAILY;COUNT=3", date(2030, 1, 1), []time.Time{date(2030, 1, 2), date(2030, 1, 3)}},
+		{"FREQ=DAILY;UNTIL=20300103", date(2030, 1, 1), []time.Time{date(2030, 1, 2), date(2030, 1, 3)}},
+	}
+	for _, tc := range cases {
+		r, err := domain.ParseRecurrence(tc.rule)
+		require.NoError(t, err, tc.rule)
+		assert.Equal(t, tc.want, r.Occurrences(tc.start, len(tc.want)), tc.rule)
+	}
+
+	r, _ := domain.ParseRecurrence("FREQ=DAILY;COUNT=3")
+	assert.Len(t, r.Occurrences(date(2030, 1, 1), 10), 2)
+}
+
+// TestRecurringTask_CompletionCreatesNext tests generating the next occurrence on DONE
+func TestRecurringTask_CompletionCreatesNext(t *testing.T) {
+	for name, repo := range queryBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			svc := domain.NewTaskService(repo)
+			ctx := context.Background()
+			done, inProgress := domain.StatusDone, domain.StatusInProgress
+			due := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+			task, err := svc.CreateTask(ctx, domain.CreateTaskInput{
+				Title: "Rotate keys", Tags: []string{"ops"}, DueDate: due, Recurrence: "freq=weekly;count=2",
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "FREQ=WEEKLY;COUNT=2", task.Recurrence)
+
+			completed, err := svc.UpdateTask(ctx, task.ID, domain.UpdateTaskInput{Status: &done})
+			require.NoError(t, err)
+			assert.Empty(t, completed.Recurrence)
+
+			pending := domain.StatusPending
+			open, err := svc.ListTasks(ctx, domain.TaskFilter{Status: &pending})
+			require.NoError(t, err)
+			require.Len(t, open, 1)
+			next := open[0]
+			assert.Equal(t, "Rotate keys", next.Title)
+			assert.Equal(t, []string{"ops"}, next.Tags)
+			assert.True(t, due.AddDate(0, 0, 7).Equal(next.DueDate))
+			assert.Equal(t, "FREQ=WEEKLY;COUNT=1", next.Recurrence)
+
+			// Reopening and completing again does not repeat the series
+			_, err = svc.UpdateTask(ctx, task.ID, domain.UpdateTaskInput{Status: &inProgress})
+			require.NoError(t, err)
+			_, err = svc.UpdateTask(ctx, task.ID, domain.UpdateTaskInput{Status: &done})
+			require.NoError(t, err)
+
+			// The last occurrence ends the series
+			_, err = svc.UpdateTask(ctx, next.ID, domain.UpdateTaskInput{Status: &done})
+			require.NoError(t, err)
+			all, err := svc.ListTasks(ctx, domain.TaskFilter{})
+			require.NoError(t, err)
+			assert.Len(t, all, 2)
+		})
+	}
+}
+
+// TestRecurringTask_SkipsPastOccurrences tests that late completion schedules in the future
+func TestRecurringTask_SkipsPastOccurrences(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	svc := domain.NewTaskService(repo)
+	ctx := context.Background()
+	done := domain.StatusDone
+
+	// Overdue by three and a half days, with two occurrences left after it
+	overdue := &domain.Task{
+		Title:      "Daily",
+		Status:     domain.StatusPending,
+		Priority:   domain.PriorityMedium,
+		DueDate:    time.Now().Add(-84 * time.Hour),
+		Recurrence: "FREQ=DAILY;COUNT=6",
+	}
+	require.NoError(t, repo.Create(ctx, overdue))
+
+	_, err := svc.UpdateTask(ctx, overdue.ID, domain.UpdateTaskInput{Status: &done})
+	require.NoError(t, err)
+	pending := domain.StatusPending
+	open, err := svc.ListTasks(ctx, domain.TaskFilter{Status: &pending})
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+	assert.True(t, overdue.DueDate.AddDate(0, 0, 4).Equal(open[0].DueDate))
+	assert.Equal(t, "FREQ=DAILY;COUNT=2", open[0].Recurrence)
+}
+
+// TestHandler_PreviewOccurrences tests the occurrence preview endpoint
+func TestHandler_PreviewOccurrences(t *testing.T) {
+	app := newFiberTestApp()
+	due := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	created := postTask(t, app, map[string]any{"title": "Standup", "due_date": due.Format(time.RFC3339), "recurrence": "FREQ=DAILY;COUNT=4"})
+	plain := postTask(t, app, map[string]any{"title": "Once", "due_date": due.Format(time.RFC3339)})
+
+	req, _ := http.NewRequest(http.MethodGet, "/v1/tasks/"+created["id"].(string)+"/occurrences?count=10", nil)
+	resp, _ := app.Test(req, 5000)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	var preview struct {
+		Occurrences []time.Time `json:"occurrences"`
+	}
+	require.NoError(t, json.Unmarshal(body, &preview))
+	require.Len(t, preview.Occurrences, 3)
+	assert.True(t, due.AddDate(0, 0, 1).Equal(preview.Occurrences[0]))
+
+	for path, want := range map[string]int{
+		"/v1/tasks/" + plain["id"].(string) + "/occurrences":           http.StatusBadRequest,
+		"/v1/tasks/" + created["id"].(string) + "/occurrences?count=0": http.StatusBadRequest,
+		"/v1/tasks/missing/occurrences":                                http.StatusNotFound,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		resp, _ := app.Test(req, 5000)
+		assert.Equal(t, want, resp.StatusCode, path)
+	}
+
+	bad := postTaskStatus(t, app, map[string]any{"title": "Bad", "due_date": due.Format(time.RFC3339), "recurrence": "FREQ=SOMETIMES"})
+	assert.Equal(t, http.StatusBadRequest, bad)
+}
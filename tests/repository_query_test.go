@@ -0,0 +1,200 @@
+package tests
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	"github.com/gauravpandey771/task-api/internal/repository"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queryBackends returns a fresh instance of every repository implementation
+func queryBackends(t *testing.T) map[string]domain.TaskRepository {
+	fileRepo, err := repository.NewFileTaskRepository(t.TempDir(), 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { fileRepo.Close() })
+
+	sqliteRepo, err := repository.NewSQLiteTaskRepository(filepath.Join(t.TempDir(), "tasks.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { sqliteRepo.Close() })
+
+	return map[string]domain.TaskRepository{
+		"memory": repository.NewInMemoryTaskRepository(),
+		"file":   fileRepo,
+		"sqlite": sqliteRepo,
+	}
+}
+
+// seedTasks creates n tasks with increasing due dates, alternating status
+func seedTasks(t *testing.T, repo domain.TaskRepository, n int) []*domain.Task {
+	base := time.Now().Add(time.Hour).Truncate(time.Second)
+	out := make([]*domain.Task, 0, n)
+	for i := 0; i < n; i++ {
+		status := domain.StatusPending
+		if i%2 == 1 {
+			status = domain.StatusDone
+		}
+		task := &domain.Task{
+			Title:   string(rune('a' + n - 1 - i)),
+			Status:  status,
+			DueDate: base.Add(time.Duration(i) * time.Hour),
+		}
+		require.NoError(t, repo.Create(context.Background(), task))
+		out = append(out, task)
+	}
+	return out
+}
+
+func ids(tasks []*domain.Task) []string {
+	out := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		out = append(out, t.ID)
+	}
+	return out
+}
+
+// TestRepositoryQuery_DueDatePaging tests ordering, totals and offset paging
+func TestRepositoryQuery_DueDatePaging(t *testing.T) {
+	for name, repo := range queryBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			seeded := seedTasks(t, repo, 5)
+
+			page, err := repo.Query(context.Background(), domain.TaskFilter{Page: 2, PageSize: 2})
+			require.NoError(t, err)
+			assert.Equal(t, 5, page.Total)
+			assert.Equal(t, ids(seeded[2:4]), ids(page.Tasks))
+			require.NotEmpty(t, page.NextCursor)
+
+			next, err := repo.Query(context.Background(), domain.TaskFilter{PageSize: 2, Cursor: page.NextCursor})
+			require.NoError(t, err)
+			assert.Equal(t, ids(seeded[4:]), ids(next.Tasks))
+
+			last, err := repo.Query(context.Background(), domain.TaskFilter{Page: 3, PageSize: 2})
+			require.NoError(t, err)
+			assert.Equal(t, ids(seeded[4:]), ids(last.Tasks))
+			assert.Empty(t, last.NextCursor)
+		})
+	}
+}
+
+// TestRepositoryQuery_StatusAndDesc tests status filtering with descending order
+func TestRepositoryQuery_StatusAndDesc(t *testing.T) {
+	for name, repo := range queryBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			seeded := seedTasks(t, repo, 5)
+			status := domain.StatusPending
+
+			page, err := repo.Query(context.Background(), domain.TaskFilter{
+				Status: &status,
+				Sort:   []domain.SortKey{{Field: domain.SortByDueDate, Desc: true}},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, 3, page.Total)
+			assert.Equal(t, []string{seeded[4].ID, seeded[2].ID, seeded[0].ID}, ids(page.Tasks))
+		})
+	}
+}
+
+// TestRepositoryQuery_Cursor tests walking all pages with cursors
+func TestRepositoryQuery_Cursor(t *testing.T) {
+	for name, repo := range queryBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			seeded := seedTasks(t, repo, 5)
+
+			for _, sort := range [][]domain.SortKey{nil, {{Field: domain.SortByTitle}}} {
+				var got []string
+				cursor := ""
+				for {
+					page, err := repo.Query(context.Background(), domain.TaskFilter{Sort: sort, PageSize: 2, Cursor: cursor})
+					require.NoError(t, err)
+					got = append(got, ids(page.Tasks)...)
+					if page.NextCursor == "" {
+						break
+					}
+					cursor = page.NextCursor
+				}
+				assert.Len(t, got, len(seeded))
+				assert.ElementsMatch(t, ids(seeded), got)
+			}
+
+			_, err := repo.Query(context.Background(), domain.TaskFilter{Cursor: "missing"})
+			assert.True(t, pkgerrors.IsValidation(err))
+		})
+	}
+}
+
+// TestRepositoryQuery_IndexFollowsUpdates tests that indexes track changes
+func TestRepositoryQuery_IndexFollowsUpdates(t *testing.T) {
+	for name, repo := range queryBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			seeded := seedTasks(t, repo, 3)
+			done := domain.StatusDone
+
+			moved, err := repo.GetByID(context.Background(), seeded[0].ID)
+			require.NoError(t, err)
+			moved.Status = domain.StatusDone
+			moved.DueDate = moved.DueDate.Add(10 * time.Hour)
+			require.NoError(t, repo.Update(context.Background(), moved))
+			require.NoError(t, repo.Delete(context.Background(), seeded[1].ID))
+
+			page, err := repo.Query(context.Background(), domain.TaskFilter{Status: &done})
+			require.NoError(t, err)
+			assert.Equal(t, []string{seeded[0].ID}, ids(page.Tasks))
+
+			all, err := repo.Query(context.Background(), domain.TaskFilter{})
+			require.NoError(t, err)
+			assert.Equal(t, []string{seeded[2].ID, seeded[0].ID}, ids(all.Tasks))
+		})
+	}
+}
+
+// TestRepository_CancelledContext tests that every backend honours cancellation
+func TestRepository_CancelledContext(t *testing.T) {
+	for name, repo := range queryBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			task := &domain.Task{Title: "Task", Status: domain.StatusPending, DueDate: time.Now().Add(time.Hour)}
+			assert.ErrorIs(t, repo.Create(ctx, task), context.Canceled)
+			_, err := repo.Query(ctx, domain.TaskFilter{})
+			assert.ErrorIs(t, err, context.Canceled)
+
+			tasks, err := repo.ListAll(context.Background())
+			require.NoError(t, err)
+			assert.Empty(t, tasks)
+		})
+	}
+}
+
+// TestRepositoryQuery_Trashed tests separating live and trashed tasks
+func TestRepositoryQuery_Trashed(t *testing.T) {
+	for name, repo := range queryBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			seeded := seedTasks(t, repo, 4)
+			ctx := context.Background()
+
+			trashed, err := repo.GetByID(ctx, seeded[1].ID)
+			require.NoError(t, err)
+			now := time.Now().UTC()
+			trashed.DeletedAt = &now
+			require.NoError(t, repo.Update(ctx, trashed))
+
+			live, err := repo.Query(ctx, domain.TaskFilter{})
+			require.NoError(t, err)
+			assert.Equal(t, 3, live.Total)
+			assert.NotContains(t, ids(live.Tasks), seeded[1].ID)
+
+			done := domain.StatusDone
+			trash, err := repo.Query(ctx, domain.TaskFilter{Trashed: true, Status: &done})
+			require.NoError(t, err)
+			assert.Equal(t, []string{seeded[1].ID}, ids(trash.Tasks))
+			assert.NotNil(t, trash.Tasks[0].DeletedAt)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -21,7 +22,7 @@ func TestRepository_CreateTask(t *testing.T) {
 		DueDate:     time.Now().Add(24 * time.Hour),
 	}
 
-	err := repo.Create(task)
+	err := repo.Create(context.Background(), task)
 	require.NoError(t, err)
 	assert.NotEmpty(t, task.ID)
 }
@@ -35,8 +36,8 @@ func TestRepository_GetByID(t *testing.T) {
 		DueDate: time.Now().Add(24 * time.Hour),
 	}
 
-	repo.Create(task)
-	retrieved, err := repo.GetByID(task.ID)
+	repo.Create(context.Background(), task)
+	retrieved, err := repo.GetByID(context.Background(), task.ID)
 
 	require.NoError(t, err)
 	assert.Equal(t, task.Title, retrieved.Title)
@@ -47,7 +48,7 @@ func TestRepository_GetByID(t *testing.T) {
 func TestRepository_GetByID_NotFound(t *testing.T) {
 	repo := repository.NewInMemoryTaskRepository()
 
-	_, err := repo.GetByID("non-existent-id")
+	_, err := repo.GetByID(context.Background(), "non-existent-id")
 	require.Error(t, err)
 	assert.True(t, pkgerrors.IsNotFound(err))
 }
@@ -61,13 +62,13 @@ func TestRepository_Update(t *testing.T) {
 		DueDate: time.Now().Add(24 * time.Hour),
 	}
 
-	repo.Create(task)
+	repo.Create(context.Background(), task)
 	task.Title = "Updated Title"
-	err := repo.Update(task)
+	err := repo.Update(context.Background(), task)
 
 	require.NoError(t, err)
 
-	retrieved, _ := repo.GetByID(task.ID)
+	retrieved, _ := repo.GetByID(context.Background(), task.ID)
 	assert.Equal(t, "Updated Title", retrieved.Title)
 }
 
@@ -81,7 +82,7 @@ func TestRepository_Update_NotFound(t *testing.T) {
 		DueDate: time.Now().Add(24 * time.Hour),
 	}
 
-	err := repo.Update(task)
+	err := repo.Update(context.Background(), task)
 	require.Error(t, err)
 	assert.True(t, pkgerrors.IsNotFound(err))
 }
@@ -95,11 +96,11 @@ func TestRepository_Delete(t *testing.T) {
 		DueDate: time.Now().Add(24 * time.Hour),
 	}
 
-	repo.Create(task)
-	err := repo.Delete(task.ID)
+	repo.Create(context.Background(), task)
+	err := repo.Delete(context.Background(), task.ID)
 	require.NoError(t, err)
 
-	_, err = repo.GetByID(task.ID)
+	_, err = repo.GetByID(context.Background(), task.ID)
 	assert.True(t, pkgerrors.IsNotFound(err))
 }
 
@@ -107,7 +108,7 @@ func TestRepository_Delete(t *testing.T) {
 func TestRepository_Delete_NotFound(t *testing.T) {
 	repo := repository.NewInMemoryTaskRepository()
 
-	err := repo.Delete("non-existent-id")
+	err := repo.Delete(context.Background(), "non-existent-id")
 	require.Error(t, err)
 	assert.True(t, pkgerrors.IsNotFound(err))
 }
@@ -127,10 +128,10 @@ func TestRepository_ListAll(t *testing.T) {
 		DueDate: time.Now().Add(48 * time.Hour),
 	}
 
-	repo.Create(task1)
-	repo.Create(task2)
+	repo.Create(context.Background(), task1)
+	repo.Create(context.Background(), task2)
 
-	tasks, err := repo.ListAll()
+	tasks, err := repo.ListAll(context.Background())
 	require.NoError(t, err)
 	assert.Equal(t, 2, len(tasks))
 }
@@ -139,7 +140,7 @@ func TestRepository_ListAll(t *testing.T) {
 func TestRepository_ListAll_Empty(t *testing.T) {
 	repo := repository.NewInMemoryTaskRepository()
 
-	tasks, err := repo.ListAll()
+	tasks, err := repo.ListAll(context.Background())
 	require.NoError(t, err)
 	assert.Equal(t, 0, len(tasks))
 }
@@ -153,11 +154,11 @@ func TestRepository_Isolation(t *testing.T) {
 		DueDate: time.Now().Add(24 * time.Hour),
 	}
 
-	repo.Create(task)
+	repo.Create(context.Background(), task)
 	taskID := task.ID
 
 	// Verify the task was created with correct values
-	retrieved, _ := repo.GetByID(taskID)
+	retrieved, _ := repo.GetByID(context.Background(), taskID)
 	assert.Equal(t, "Original", retrieved.Title)
 
 	// Update the original task struct and verify GetByID returns a copy
@@ -166,12 +167,12 @@ func TestRepository_Isolation(t *testing.T) {
 	// GetByID returns a shallow copy of the stored value,
 	// so it should show the modified title since we modified the original pointer
 	// This is expected behavior as we store pointers
-	retrieved2, _ := repo.GetByID(taskID)
+	retrieved2, _ := repo.GetByID(context.Background(), taskID)
 	assert.Equal(t, "Modified", retrieved2.Title)
 
 	// But if we modify the retrieved copy, it shouldn't affect the next retrieval
 	retrieved2.Title = "AnotherModification"
-	retrieved3, _ := repo.GetByID(taskID)
+	retrieved3, _ := repo.GetByID(context.Background(), taskID)
 	// Since GetByID makes a shallow copy, this should still be "Modified"
 	assert.Equal(t, "Modified", retrieved3.Title)
 }
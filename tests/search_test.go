@@ -0,0 +1,285 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	"github.com/gauravpandey771/task-api/internal/repository"
+	"github.com/gauravpandey771/task-api/internal/search"
+	httphandler "github.com/gauravpandey771/task-api/internal/transport/http"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSearchService returns a service whose repository keeps a search index
+func newSearchService(t *testing.T) domain.TaskService {
+	indexed, err := repository.NewIndexedTaskRepository(context.Background(), repository.NewInMemoryTaskRepository())
+	require.NoError(t, err)
+	return domain.NewTaskService(indexed, domain.WithSearch(indexed))
+}
+
+func searchTitles(t *testing.T, svc domain.TaskService, query string) []string {
+	page, err := svc.SearchTasks(context.Background(), query, 0)
+	require.NoError(t, err)
+	titles := []string{}
+	for _, r := range page.Items {
+		titles = append(titles, r.Task.Title)
+	}
+	return titles
+}
+
+// TestStem tests the Porter stemmer against reference outputs
+func TestStem(t *testing.T) {
+	cases := map[string]string{
+		"caresses":        "caress",
+		"ponies":          "poni",
+		"motoring":        "motor",
+		"hopping":         "hop",
+		"filing":          "file",
+		"relational":      "relat",
+		"generalizations": "gener",
+		"hopefulness":     "hope",
+		"adjustment":      "adjust",
+		"deployment":      "deploy",
+		"running":         "run",
+		"controll":        "control",
+		"go":              "go",
+		"café":            "café",
+	}
+	for word, want := range cases {
+		assert.Equal(t, want, search.Stem(word), word)
+	}
+}
+
+// TestIndex_Search tests stemming, prefixes, ranking and highlighting
+func TestIndex_Search(t *testing.T) {
+	idx := search.NewIndex(map[string]float64{"title": 2})
+	idx.Put("1", map[string]string{"title": "Deploy API", "description": "Roll out the gateway & verify"})
+	idx.Put("2", map[string]string{"title": "Write docs", "description": "Document the deploy steps"})
+	idx.Put("3", map[string]string{"title": "Plan sprint", "description": "Nothing to see"})
+
+	hits, total := idx.Search("deploy", 10)
+	require.Equal(t, 2, total)
+	assert.Equal(t, "1", hits[0].ID, "title matches rank above description matches")
+	assert.Equal(t, "<mark>Deploy</mark> API", hits[0].Highlights["title"])
+	assert.Equal(t, "Document the <mark>deploy</mark> steps", hits[1].Highlights["description"])
+
+	// Stemmed and prefix matches
+	hits, _ = idx.Search("documenting", 10)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "2", hits[0].ID)
+	hits, _ = idx.Search("gatew", 10)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "Roll out the <mark>gateway</mark> &amp; verify", hits[0].Highlights["description"])
+
+	// Every word must match; stop words are ignored
+	hits, _ = idx.Search("deploy the docs", 10)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "2", hits[0].ID)
+	hits, total = idx.Search("the", 10)
+	assert.Empty(t, hits)
+	assert.Zero(t, total)
+
+	// Limits and removal
+	hits, total = idx.Search("deploy", 1)
+	assert.Len(t, hits, 1)
+	assert.Equal(t, 2, total)
+	idx.Remove("1")
+	hits, _ = idx.Search("deploy", 10)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "2", hits[0].ID)
+	assert.Equal(t, 2, idx.Len())
+}
+
+// TestIndex_SearchWordPrefix tests prefixes that run past a word's stem
+func TestIndex_SearchWordPrefix(t *testing.T) {
+	idx := search.NewIndex(nil)
+	idx.Put("1", map[string]string{"title": "Deployment checklist"})
+	idx.Put("2", map[string]string{"title": "Organization chart"})
+	idx.Put("3", map[string]string{"title": "Generalizations memo"})
+
+	for query, want := range map[string]string{
+		"deploym":   "1",
+		"deployme":  "1",
+		"organiza":  "2",
+		"generaliz": "3",
+	} {
+		hits, _ := idx.Search(query, 10)
+		require.Len(t, hits, 1, query)
+		assert.Equal(t, want, hits[0].ID, query)
+	}
+	hits, _ := idx.Search("deploym", 10)
+	assert.Equal(t, "<mark>Deployment</mark> checklist", hits[0].Highlights["title"])
+
+	idx.Remove("1")
+	hits, total := idx.Search("deploym", 10)
+	assert.Empty(t, hits)
+	assert.Zero(t, total)
+}
+
+// TestIndex_Snippet tests that long texts are cut around the first match
+func TestIndex_Snippet(t *testing.T) {
+	idx := search.NewIndex(nil)
+	text := strings.Repeat("filler words here ", 20) + "the rollback plan " + strings.Repeat("more trailing text ", 20)
+	idx.Put("1", map[string]string{"description": text})
+
+	hits, _ := idx.Search("rollback", 10)
+	require.Len(t, hits, 1)
+	snippet := hits[0].Highlights["description"]
+	assert.True(t, strings.HasPrefix(snippet, "…"))
+	assert.True(t, strings.HasSuffix(snippet, "…"))
+	assert.Contains(t, snippet, "the <mark>rollback</mark> plan")
+	assert.Less(t, len(snippet), 220)
+}
+
+// TestIndex_SnippetRuneBoundary tests that snippets are not cut inside a
+// multi-byte character
+func TestIndex_SnippetRuneBoundary(t *testing.T) {
+	idx := search.NewIndex(nil)
+	// The snippet ends 160 bytes in, inside one of the 3-byte dashes
+	idx.Put("1", map[string]string{"description": "rollback " + strings.Repeat("—", 60)})
+
+	hits, _ := idx.Search("rollback", 10)
+	require.Len(t, hits, 1)
+	snippet := hits[0].Highlights["description"]
+	assert.True(t, utf8.ValidString(snippet), snippet)
+	assert.True(t, strings.HasSuffix(snippet, "—…"))
+}
+
+// TestSearchTasks_StaleHits tests that hits for tasks the index has not yet
+// dropped are left out of the results and the total
+func TestSearchTasks_StaleHits(t *testing.T) {
+	mem := repository.NewInMemoryTaskRepository()
+	indexed, err := repository.NewIndexedTaskRepository(context.Background(), mem)
+	require.NoError(t, err)
+	svc := domain.NewTaskService(indexed, domain.WithSearch(indexed))
+	ctx := context.Background()
+
+	stale, err := svc.CreateTask(ctx, domain.CreateTaskInput{Title: "Deploy API", DueDate: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	_, err = svc.CreateTask(ctx, domain.CreateTaskInput{Title: "Deploy docs", DueDate: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	// Deleting behind the index's back leaves it trailing
+	require.NoError(t, mem.Delete(ctx, stale.ID))
+
+	page, err := svc.SearchTasks(ctx, "deploy", 0)
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "Deploy docs", page.Items[0].Task.Title)
+	assert.Equal(t, 1, page.Total)
+}
+
+// TestSearchTasks_FollowsWrites tests that the index tracks creates,
+// updates, trash, restore and purge
+func TestSearchTasks_FollowsWrites(t *testing.T) {
+	svc := newSearchService(t)
+	ctx := context.Background()
+
+	task, err := svc.CreateTask(ctx, domain.CreateTaskInput{Title: "Deploy API", DueDate: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Deploy API"}, searchTitles(t, svc, "deploy"))
+
+	title := "Migrate database"
+	_, err = svc.UpdateTask(ctx, task.ID, domain.UpdateTaskInput{Title: &title})
+	require.NoError(t, err)
+	assert.Empty(t, searchTitles(t, svc, "deploy"))
+	assert.Equal(t, []string{"Migrate database"}, searchTitles(t, svc, "migration"))
+
+	require.NoError(t, svc.DeleteTask(ctx, task.ID))
+	assert.Empty(t, searchTitles(t, svc, "migrate"))
+	_, err = svc.RestoreTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Migrate database"}, searchTitles(t, svc, "migrate"))
+
+	require.NoError(t, svc.DeleteTask(ctx, task.ID))
+	require.NoError(t, svc.PurgeTask(ctx, task.ID))
+	assert.Empty(t, searchTitles(t, svc, "migrate"))
+
+	_, err = svc.SearchTasks(ctx, "  ", 0)
+	assert.True(t, pkgerrors.IsValidation(err))
+	_, err = svc.SearchTasks(ctx, "migrate", domain.MaxSearchLimit+1)
+	assert.True(t, pkgerrors.IsValidation(err))
+}
+
+// TestIndexedRepository_Rebuild tests indexing tasks already in the store
+func TestIndexedRepository_Rebuild(t *testing.T) {
+	repo := repository.NewInMemoryTaskRepository()
+	ctx := context.Background()
+	require.NoError(t, repo.Create(ctx, &domain.Task{Title: "Existing deploy", Status: domain.StatusPending, DueDate: time.Now().Add(time.Hour)}))
+	now := time.Now()
+	require.NoError(t, repo.Create(ctx, &domain.Task{Title: "Trashed deploy", Status: domain.StatusPending, DueDate: time.Now().Add(time.Hour), DeletedAt: &now}))
+
+	indexed, err := repository.NewIndexedTaskRepository(ctx, repo)
+	require.NoError(t, err)
+	matches, total, err := indexed.Search(ctx, "deploy", 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "Existing <mark>deploy</mark>", matches[0].Highlights["title"])
+}
+
+// TestIndexedRepository_Batcher tests that the index batches only when the
+// store it wraps does
+func TestIndexedRepository_Batcher(t *testing.T) {
+	ctx := context.Background()
+	indexed, err := repository.NewIndexedTaskRepository(ctx, repository.NewInMemoryTaskRepository())
+	require.NoError(t, err)
+	assert.Implements(t, (*domain.TaskBatcher)(nil), indexed)
+
+	// Embedding only the interface hides the store's Batch method
+	indexed, err = repository.NewIndexedTaskRepository(ctx, struct{ domain.TaskRepository }{repository.NewInMemoryTaskRepository()})
+	require.NoError(t, err)
+	_, ok := indexed.(domain.TaskBatcher)
+	assert.False(t, ok)
+
+	svc := domain.NewTaskService(indexed, domain.WithSearch(indexed))
+	_, err = svc.CreateTask(ctx, domain.CreateTaskInput{Title: "Deploy API", Tags: []string{"bug"}, DueDate: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	n, err := svc.RenameTag(ctx, "bug", "defect")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = svc.ApplyBatchAtomic(ctx, []domain.BatchOperation{{Op: domain.BatchCreate, Create: domain.CreateTaskInput{Title: "New", DueDate: time.Now().Add(time.Hour)}}})
+	assert.True(t, pkgerrors.HasCode(err, domain.CodeBatchUnsupported))
+}
+
+// TestHandler_SearchTasks tests GET /tasks/search
+func TestHandler_SearchTasks(t *testing.T) {
+	app := httphandler.NewApp(httphandler.NewTaskHandler(newSearchService(t)))
+	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	postTask(t, app, map[string]any{"title": "Deploy API", "description": "Roll out v2", "due_date": due})
+	postTask(t, app, map[string]any{"title": "Write docs", "due_date": due})
+
+	req, _ := http.NewRequest(http.MethodGet, "/v1/tasks/search?q=deploying", nil)
+	resp, err := app.Test(req, 5000)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, _ := io.ReadAll(resp.Body)
+	var page struct {
+		Items []struct {
+			Task       map[string]any    `json:"task"`
+			Score      float64           `json:"score"`
+			Highlights map[string]string `json:"highlights"`
+		} `json:"items"`
+		Total int `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(body, &page))
+	assert.Equal(t, 1, page.Total)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "Deploy API", page.Items[0].Task["title"])
+	assert.Positive(t, page.Items[0].Score)
+	assert.Equal(t, "<mark>Deploy</mark> API", page.Items[0].Highlights["title"])
+
+	req, _ = http.NewRequest(http.MethodGet, "/v1/tasks/search", nil)
+	resp, _ = app.Test(req, 5000)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
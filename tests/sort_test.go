@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseSort tests parsing of multi-key sort expressions
+func TestParseSort(t *testing.T) {
+	keys, err := domain.ParseSort("-updated_at, title")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.SortKey{
+		{Field: domain.SortByUpdatedAt, Desc: true},
+		{Field: domain.SortByTitle},
+	}, keys)
+
+	keys, err = domain.ParseSort("")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	for _, bad := range []string{"owner", "title,title", "title,", "-"} {
+		_, err := domain.ParseSort(bad)
+		assert.True(t, pkgerrors.IsValidation(err), bad)
+	}
+}
+
+// TestTimestamps_SetByService tests server-managed created/updated times
+func TestTimestamps_SetByService(t *testing.T) {
+	svc := newTestService()
+	ctx := context.Background()
+	before := time.Now().UTC()
+
+	created, err := svc.CreateTask(ctx, domain.CreateTaskInput{Title: "Task", DueDate: time.Now().Add(24 * time.Hour)})
+	require.NoError(t, err)
+	assert.False(t, created.CreatedAt.Before(before))
+	assert.Equal(t, created.CreatedAt, created.UpdatedAt)
+
+	title := "Updated"
+	updated, err := svc.UpdateTask(ctx, created.ID, domain.UpdateTaskInput{Title: &title})
+	require.NoError(t, err)
+	assert.Equal(t, created.CreatedAt, updated.CreatedAt)
+	assert.True(t, updated.UpdatedAt.After(created.UpdatedAt) || updated.UpdatedAt.Equal(created.UpdatedAt))
+}
+
+// TestListTasks_MultiKeySort tests ordering by several keys in both directions
+func TestListTasks_MultiKeySort(t *testing.T) {
+	for name, repo := range queryBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			svc := domain.NewTaskService(repo)
+			ctx := context.Background()
+			due := time.Now().Add(24 * time.Hour)
+			svc.CreateTask(ctx, domain.CreateTaskInput{Title: "b", DueDate: due})
+			svc.CreateTask(ctx, domain.CreateTaskInput{Title: "a", DueDate: due.Add(time.Hour)})
+			svc.CreateTask(ctx, domain.CreateTaskInput{Title: "a", DueDate: due})
+
+			tasks, err := svc.ListTasks(ctx, domain.TaskFilter{Sort: []domain.SortKey{
+				{Field: domain.SortByTitle},
+				{Field: domain.SortByDueDate, Desc: true},
+			}})
+			require.NoError(t, err)
+			require.Len(t, tasks, 3)
+			assert.Equal(t, "a", tasks[0].Title)
+			assert.True(t, tasks[0].DueDate.After(tasks[1].DueDate))
+			assert.Equal(t, "b", tasks[2].Title)
+
+			_, err = svc.ListTasks(ctx, domain.TaskFilter{Sort: []domain.SortKey{{Field: "bogus"}}})
+			assert.True(t, pkgerrors.IsValidation(err))
+		})
+	}
+}
+
+// TestHandler_ListTasks_Sort tests the sort query parameter
+func TestHandler_ListTasks_Sort(t *testing.T) {
+	app := newFiberTestApp()
+	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	var firstID string
+	for _, title := range []string{"First", "Second"} {
+		b, _ := json.Marshal(map[string]any{"title": title, "due_date": due})
+		req, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req, 5000)
+		if firstID == "" {
+			body, _ := io.ReadAll(resp.Body)
+			var created map[string]any
+			json.Unmarshal(body, &created)
+			firstID = created["id"].(string)
+			assert.NotEmpty(t, created["created_at"])
+		}
+	}
+
+	// Touch the first task so it becomes the most recently updated
+	b, _ := json.Marshal(map[string]any{"title": "First", "description": "touched", "due_date": due})
+	putReq, _ := http.NewRequest(http.MethodPut, "/v1/tasks/"+firstID, bytes.NewReader(b))
+	putReq.Header.Set("Content-Type", "application/json")
+	app.Test(putReq, 5000)
+
+	req, _ := http.NewRequest(http.MethodGet, "/v1/tasks?sort=-updated_at,title", nil)
+	resp, _ := app.Test(req, 5000)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	tasks := listItems(t, body)
+	require.Len(t, tasks, 2)
+	assert.Equal(t, "First", tasks[0]["title"])
+
+	badReq, _ := http.NewRequest(http.MethodGet, "/v1/tasks?sort=bogus", nil)
+	badResp, _ := app.Test(badReq, 5000)
+	assert.Equal(t, http.StatusBadRequest, badResp.StatusCode)
+}
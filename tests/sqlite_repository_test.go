@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	"github.com/gauravpandey771/task-api/internal/repository"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Helper to create a SQLite repository in a temp directory
+func newSQLiteRepo(t *testing.T) *repository.SQLiteTaskRepository {
+	t.Helper()
+	repo, err := repository.NewSQLiteTaskRepository(filepath.Join(t.TempDir(), "tasks.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+// TestSQLiteRepository_CreateAndGet tests creating and retrieving a task
+func TestSQLiteRepository_CreateAndGet(t *testing.T) {
+	repo := newSQLiteRepo(t)
+	due := time.Now().Add(24 * time.Hour)
+	task := &domain.Task{
+		Title:       "Test Task",
+		Description: "Test Description",
+		Status:      domain.StatusPending,
+		DueDate:     due,
+	}
+
+	require.NoError(t, repo.Create(context.Background(), task))
+	assert.NotEmpty(t, task.ID)
+
+	retrieved, err := repo.GetByID(context.Background(), task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.ID, retrieved.ID)
+	assert.Equal(t, "Test Task", retrieved.Title)
+	assert.Equal(t, "Test Description", retrieved.Description)
+	assert.Equal(t, domain.StatusPending, retrieved.Status)
+	assert.True(t, due.Equal(retrieved.DueDate))
+}
+
+// TestSQLiteRepository_GetByID_NotFound tests retrieval of non-existent task
+func TestSQLiteRepository_GetByID_NotFound(t *testing.T) {
+	repo := newSQLiteRepo(t)
+
+	_, err := repo.GetByID(context.Background(), "non-existent-id")
+	require.Error(t, err)
+	assert.True(t, pkgerrors.IsNotFound(err))
+}
+
+// TestSQLiteRepository_Update tests task update
+func TestSQLiteRepository_Update(t *testing.T) {
+	repo := newSQLiteRepo(t)
+	task := &domain.Task{Title: "Original Title", Status: domain.StatusPending, DueDate: time.Now().Add(24 * time.Hour)}
+	require.NoError(t, repo.Create(context.Background(), task))
+
+	task.Title = "Updated Title"
+	task.Status = domain.StatusDone
+	require.NoError(t, repo.Update(context.Background(), task))
+
+	retrieved, err := repo.GetByID(context.Background(), task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Updated Title", retrieved.Title)
+	assert.Equal(t, domain.StatusDone, retrieved.Status)
+}
+
+// TestSQLiteRepository_Update_NotFound tests update of non-existent task
+func TestSQLiteRepository_Update_NotFound(t *testing.T) {
+	repo := newSQLiteRepo(t)
+	task := &domain.Task{ID: "non-existent", Title: "Test", Status: domain.StatusPending, DueDate: time.Now().Add(24 * time.Hour)}
+
+	err := repo.Update(context.Background(), task)
+	require.Error(t, err)
+	assert.True(t, pkgerrors.IsNotFound(err))
+}
+
+// TestSQLiteRepository_Delete tests task deletion
+func TestSQLiteRepository_Delete(t *testing.T) {
+	repo := newSQLiteRepo(t)
+	task := &domain.Task{Title: "Task to Delete", Status: domain.StatusPending, DueDate: time.Now().Add(24 * time.Hour)}
+	require.NoError(t, repo.Create(context.Background(), task))
+
+	require.NoError(t, repo.Delete(context.Background(), task.ID))
+
+	_, err := repo.GetByID(context.Background(), task.ID)
+	assert.True(t, pkgerrors.IsNotFound(err))
+
+	err = repo.Delete(context.Background(), task.ID)
+	assert.True(t, pkgerrors.IsNotFound(err))
+}
+
+// TestSQLiteRepository_ListAll tests listing all tasks
+func TestSQLiteRepository_ListAll(t *testing.T) {
+	repo := newSQLiteRepo(t)
+
+	tasks, err := repo.ListAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(tasks))
+
+	repo.Create(context.Background(), &domain.Task{Title: "Task 1", Status: domain.StatusPending, DueDate: time.Now().Add(24 * time.Hour)})
+	repo.Create(context.Background(), &domain.Task{Title: "Task 2", Status: domain.StatusInProgress, DueDate: time.Now().Add(48 * time.Hour)})
+
+	tasks, err = repo.ListAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(tasks))
+}
+
+// TestSQLiteRepository_PersistsAcrossReopen tests durability across restarts
+func TestSQLiteRepository_PersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.db")
+	repo, err := repository.NewSQLiteTaskRepository(path)
+	require.NoError(t, err)
+	task := &domain.Task{Title: "Durable", Status: domain.StatusPending, DueDate: time.Now().Add(24 * time.Hour)}
+	require.NoError(t, repo.Create(context.Background(), task))
+	require.NoError(t, repo.Close())
+
+	reopened, err := repository.NewSQLiteTaskRepository(path)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	retrieved, err := reopened.GetByID(context.Background(), task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Durable", retrieved.Title)
+}
+
+// TestSQLiteRepository_WithService tests the service on top of SQLite
+func TestSQLiteRepository_WithService(t *testing.T) {
+	svc := domain.NewTaskService(newSQLiteRepo(t))
+	status := domain.StatusDone
+
+	svc.CreateTask(context.Background(), domain.CreateTaskInput{Title: "Later", DueDate: time.Now().Add(48 * time.Hour)})
+	svc.CreateTask(context.Background(), domain.CreateTaskInput{Title: "Earlier", DueDate: time.Now().Add(24 * time.Hour)})
+	svc.CreateTask(context.Background(), domain.CreateTaskInput{Title: "Done", Status: &status, DueDate: time.Now().Add(72 * time.Hour)})
+
+	tasks, err := svc.ListTasks(context.Background(), domain.TaskFilter{})
+	require.NoError(t, err)
+	require.Equal(t, 3, len(tasks))
+	assert.Equal(t, "Earlier", tasks[0].Title)
+
+	done, err := svc.ListTasks(context.Background(), domain.TaskFilter{Status: &status})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(done))
+	assert.Equal(t, "Done", done[0].Title)
+}
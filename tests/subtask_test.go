@@ -0,0 +1,203 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createChild creates a task under parentID
+func createChild(t *testing.T, svc domain.TaskService, parentID, title string) *domain.Task {
+	task, err := svc.CreateTask(context.Background(), domain.CreateTaskInput{ParentID: parentID, Title: title, DueDate: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	return task
+}
+
+// TestSubtasks_ParentValidation tests missing parents and cycles
+func TestSubtasks_ParentValidation(t *testing.T) {
+	svc := newTestService()
+	ctx := context.Background()
+
+	_, err := svc.CreateTask(ctx, domain.CreateTaskInput{ParentID: "missing", Title: "Task", DueDate: time.Now().Add(time.Hour)})
+	assert.True(t, pkgerrors.IsValidation(err))
+	assert.Equal(t, domain.ErrParentNotFound, err.Error())
+
+	root := createChild(t, svc, "", "root")
+	child := createChild(t, svc, root.ID, "child")
+	grandchild := createChild(t, svc, child.ID, "grandchild")
+
+	for _, parent := range []string{root.ID, grandchild.ID} {
+		_, err = svc.UpdateTask(ctx, root.ID, domain.UpdateTaskInput{ParentID: &parent})
+		assert.True(t, pkgerrors.IsValidation(err))
+		assert.Equal(t, domain.ErrParentCycle, err.Error())
+	}
+
+	none := ""
+	moved, err := svc.UpdateTask(ctx, grandchild.ID, domain.UpdateTaskInput{ParentID: &none})
+	require.NoError(t, err)
+	assert.Empty(t, moved.ParentID)
+}
+
+// TestSubtasks_ListChildren tests listing children on every backend
+func TestSubtasks_ListChildren(t *testing.T) {
+	for name, repo := range queryBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			svc := domain.NewTaskService(repo)
+			ctx := context.Background()
+
+			root := createChild(t, svc, "", "root")
+			a := createChild(t, svc, root.ID, "a")
+			b := createChild(t, svc, root.ID, "b")
+			createChild(t, svc, a.ID, "nested")
+
+			children, err := svc.ListChildren(ctx, root.ID, domain.TaskFilter{})
+			require.NoError(t, err)
+			assert.ElementsMatch(t, []string{a.ID, b.ID}, ids(children.Tasks))
+			assert.Equal(t, 2, children.Total)
+
+			first, err := svc.ListChildren(ctx, root.ID, domain.TaskFilter{PageSize: 1})
+			require.NoError(t, err)
+			require.NotEmpty(t, first.NextCursor)
+			next, err := svc.ListChildren(ctx, root.ID, domain.TaskFilter{PageSize: 1, Cursor: first.NextCursor})
+			require.NoError(t, err)
+			assert.ElementsMatch(t, []string{a.ID, b.ID}, ids(append(first.Tasks, next.Tasks...)))
+			assert.Empty(t, next.NextCursor)
+
+			_, err = svc.ListChildren(ctx, "missing", domain.TaskFilter{})
+			assert.True(t, pkgerrors.IsNotFound(err))
+		})
+	}
+}
+
+// TestSubtasks_CompletionBlock tests that parents with open children cannot be completed
+func TestSubtasks_CompletionBlock(t *testing.T) {
+	svc := newTestService()
+	ctx := context.Background()
+	done := domain.StatusDone
+
+	root := createChild(t, svc, "", "root")
+	child := createChild(t, svc, root.ID, "child")
+
+	_, err := svc.UpdateTask(ctx, root.ID, domain.UpdateTaskInput{Status: &done})
+	assert.True(t, pkgerrors.IsConflict(err))
+	assert.Equal(t, domain.ErrOpenChildren, err.Error())
+
+	_, err = svc.UpdateTask(ctx, child.ID, domain.UpdateTaskInput{Status: &done})
+	require.NoError(t, err)
+	parent, _ := svc.GetTask(ctx, root.ID)
+	assert.Equal(t, domain.StatusPending, parent.Status)
+
+	_, err = svc.UpdateTask(ctx, root.ID, domain.UpdateTaskInput{Status: &done})
+	assert.NoError(t, err)
+}
+
+// TestSubtasks_CompletionAuto tests that parents complete with their last child
+func TestSubtasks_CompletionAuto(t *testing.T) {
+	for name, repo := range queryBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			svc := domain.NewTaskService(repo, domain.WithHierarchyPolicy(domain.HierarchyPolicy{
+				Completion: domain.CompletionAuto,
+				Delete:     domain.DeleteOrphan,
+			}))
+			ctx := context.Background()
+			done := domain.StatusDone
+
+			root := createChild(t, svc, "", "root")
+			mid := createChild(t, svc, root.ID, "mid")
+			a := createChild(t, svc, mid.ID, "a")
+			b := createChild(t, svc, mid.ID, "b")
+
+			_, err := svc.UpdateTask(ctx, a.ID, domain.UpdateTaskInput{Status: &done})
+			require.NoError(t, err)
+			got, _ := svc.GetTask(ctx, mid.ID)
+			assert.Equal(t, domain.StatusPending, got.Status)
+
+			_, err = svc.UpdateTask(ctx, b.ID, domain.UpdateTaskInput{Status: &done})
+			require.NoError(t, err)
+			for _, id := range []string{mid.ID, root.ID} {
+				got, _ := svc.GetTask(ctx, id)
+				assert.Equal(t, domain.StatusDone, got.Status)
+			}
+		})
+	}
+}
+
+// TestSubtasks_DeletePolicies tests orphaning and cascading deletes
+func TestSubtasks_DeletePolicies(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("orphan", func(t *testing.T) {
+		svc := newTestService()
+		root := createChild(t, svc, "", "root")
+		child := createChild(t, svc, root.ID, "child")
+
+		require.NoError(t, svc.DeleteTask(ctx, root.ID))
+		got, err := svc.GetTask(ctx, child.ID)
+		require.NoError(t, err)
+		assert.Empty(t, got.ParentID)
+	})
+
+	t.Run("cascade", func(t *testing.T) {
+		for name, repo := range queryBackends(t) {
+			t.Run(name, func(t *testing.T) {
+				svc := domain.NewTaskService(repo, domain.WithHierarchyPolicy(domain.HierarchyPolicy{
+					Completion: domain.CompletionBlock,
+					Delete:     domain.DeleteCascade,
+				}))
+				root := createChild(t, svc, "", "root")
+				child := createChild(t, svc, root.ID, "child")
+				grandchild := createChild(t, svc, child.ID, "grandchild")
+
+				require.NoError(t, svc.DeleteTask(ctx, root.ID))
+				for _, id := range []string{child.ID, grandchild.ID} {
+					_, err := svc.GetTask(ctx, id)
+					assert.True(t, pkgerrors.IsNotFound(err))
+				}
+
+				// A child restored on its own comes back top-level
+				restored, err := svc.RestoreTask(ctx, child.ID)
+				require.NoError(t, err)
+				assert.Empty(t, restored.ParentID)
+
+				_, err = svc.RestoreTask(ctx, root.ID)
+				require.NoError(t, err)
+				got, err := svc.GetTask(ctx, grandchild.ID)
+				require.NoError(t, err)
+				assert.Equal(t, child.ID, got.ParentID)
+			})
+		}
+	})
+}
+
+// TestHandler_Subtasks tests parent_id in DTOs and the children endpoint
+func TestHandler_Subtasks(t *testing.T) {
+	app := newFiberTestApp()
+	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+
+	var root, child map[string]any
+	decodeBody(t, sendJSON(t, app, http.MethodPost, "/v1/tasks", map[string]any{"title": "root", "due_date": due}), &root)
+	rootID := root["id"].(string)
+	resp := sendJSON(t, app, http.MethodPost, "/v1/tasks", map[string]any{"title": "child", "parent_id": rootID, "due_date": due})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	decodeBody(t, resp, &child)
+	assert.Equal(t, rootID, child["parent_id"])
+
+	var children taskList
+	resp = sendJSON(t, app, http.MethodGet, "/v1/tasks/"+rootID+"/children?page_size=1", nil)
+	assert.Equal(t, `</v1/tasks/`+rootID+`/children?page_size=1>; rel="first"`, resp.Header.Get("Link"))
+	decodeBody(t, resp, &children)
+	require.Len(t, children.Items, 1)
+	assert.Equal(t, child["id"], children.Items[0]["id"])
+	assert.Equal(t, 1, children.Total)
+
+	assert.Equal(t, http.StatusNotFound, sendJSON(t, app, http.MethodGet, "/v1/tasks/missing/children", nil).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, sendJSON(t, app, http.MethodPost, "/v1/tasks", map[string]any{"title": "x", "parent_id": "missing", "due_date": due}).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, sendJSON(t, app, http.MethodPut, "/v1/tasks/"+rootID, map[string]any{"title": "root", "parent_id": child["id"], "due_date": due}).StatusCode)
+	assert.Equal(t, http.StatusConflict, sendJSON(t, app, http.MethodPut, "/v1/tasks/"+rootID, map[string]any{"title": "root", "status": "DONE", "due_date": due}).StatusCode)
+}
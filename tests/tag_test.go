@@ -0,0 +1,218 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	"github.com/gauravpandey771/task-api/internal/repository"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNormalizeTags tests trimming, case-folding, dedup and limits
+func TestNormalizeTags(t *testing.T) {
+	tags, err := domain.NormalizeTags([]string{" Backend", "bug", "BUG ", "api"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api", "backend", "bug"}, tags)
+
+	for _, bad := range []string{"", "   ", "a,b", strings.Repeat("x", domain.MaxTagLength+1)} {
+		_, err := domain.NormalizeTags([]string{bad})
+		assert.True(t, pkgerrors.IsValidation(err), bad)
+	}
+
+	svc := newTestService()
+	many := make([]string, domain.MaxTagsPerTask+1)
+	for i := range many {
+		many[i] = string(rune('a' + i))
+	}
+	_, err = svc.CreateTask(context.Background(), domain.CreateTaskInput{Title: "Task", Tags: many, DueDate: time.Now().Add(time.Hour)})
+	assert.True(t, pkgerrors.IsValidation(err))
+}
+
+// TestListTasks_TagFilter tests any/all tag matching on every backend
+func TestListTasks_TagFilter(t *testing.T) {
+	for name, repo := range queryBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			svc := domain.NewTaskService(repo)
+			ctx := context.Background()
+			due := time.Now().Add(time.Hour)
+
+			create := func(title string, tags ...string) {
+				_, err := svc.CreateTask(ctx, domain.CreateTaskInput{Title: title, Tags: tags, DueDate: due})
+				require.NoError(t, err)
+			}
+			create("both", "API", "bug")
+			create("api", "api")
+			create("bug", "bug", "ui")
+			create("none")
+
+			titles := func(filter domain.TaskFilter) []string {
+				tasks, err := svc.ListTasks(ctx, filter)
+				require.NoError(t, err)
+				var out []string
+				for _, task := range tasks {
+					out = append(out, task.Title)
+				}
+				return out
+			}
+
+			assert.ElementsMatch(t, []string{"both", "api", "bug"}, titles(domain.TaskFilter{Tags: []string{"api", "Bug"}}))
+			assert.ElementsMatch(t, []string{"both"}, titles(domain.TaskFilter{Tags: []string{"api", "bug"}, TagMatch: domain.TagMatchAll}))
+			assert.Empty(t, titles(domain.TaskFilter{Tags: []string{"missing"}}))
+
+			_, err := svc.ListTasks(ctx, domain.TaskFilter{Tags: []string{"api"}, TagMatch: "some"})
+			assert.True(t, pkgerrors.IsValidation(err))
+		})
+	}
+}
+
+// TestTags_CountsAndRename tests tag counts and rename/merge on every backend
+func TestTags_CountsAndRename(t *testing.T) {
+	for name, repo := range queryBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			svc := domain.NewTaskService(repo)
+			ctx := context.Background()
+			due := time.Now().Add(time.Hour)
+
+			a, _ := svc.CreateTask(ctx, domain.CreateTaskInput{Title: "a", Tags: []string{"bug", "defect"}, DueDate: due})
+			svc.CreateTask(ctx, domain.CreateTaskInput{Title: "b", Tags: []string{"bug"}, DueDate: due})
+			trashed, _ := svc.CreateTask(ctx, domain.CreateTaskInput{Title: "c", Tags: []string{"bug", "ui"}, DueDate: due})
+			require.NoError(t, svc.DeleteTask(ctx, trashed.ID))
+
+			counts, err := svc.ListTags(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, []domain.TagCount{{Tag: "bug", Count: 2}, {Tag: "defect", Count: 1}}, counts)
+
+			n, err := svc.RenameTag(ctx, "Bug", "defect")
+			require.NoError(t, err)
+			assert.Equal(t, 3, n)
+
+			counts, err = svc.ListTags(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, []domain.TagCount{{Tag: "defect", Count: 2}}, counts)
+
+			merged, err := svc.GetTask(ctx, a.ID)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"defect"}, merged.Tags)
+
+			restored, err := svc.RestoreTask(ctx, trashed.ID)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"defect", "ui"}, restored.Tags)
+
+			_, err = svc.RenameTag(ctx, "bug", "defect")
+			assert.True(t, pkgerrors.IsNotFound(err))
+		})
+	}
+}
+
+// failingBatchRepo fails the nth update made inside a batch
+type failingBatchRepo struct {
+	*repository.InMemoryTaskRepository
+	failAt int
+}
+
+func (r *failingBatchRepo) Batch(ctx context.Context, fn func(tx domain.TaskRepository) error) error {
+	return r.InMemoryTaskRepository.Batch(ctx, func(tx domain.TaskRepository) error {
+		return fn(&failingUpdates{TaskRepository: tx, failAt: r.failAt})
+	})
+}
+
+type failingUpdates struct {
+	domain.TaskRepository
+	failAt, updates int
+}
+
+func (r *failingUpdates) Update(ctx context.Context, task *domain.Task) error {
+	if r.updates++; r.updates == r.failAt {
+		return errors.New("disk full")
+	}
+	return r.TaskRepository.Update(ctx, task)
+}
+
+// TestTags_RenameAtomic tests that a failed rename leaves every task as it was
+func TestTags_RenameAtomic(t *testing.T) {
+	svc := domain.NewTaskService(&failingBatchRepo{InMemoryTaskRepository: repository.NewInMemoryTaskRepository(), failAt: 2})
+	ctx := context.Background()
+	due := time.Now().Add(time.Hour)
+	for _, title := range []string{"a", "b", "c"} {
+		_, err := svc.CreateTask(ctx, domain.CreateTaskInput{Title: title, Tags: []string{"bug"}, DueDate: due})
+		require.NoError(t, err)
+	}
+
+	_, err := svc.RenameTag(ctx, "bug", "defect")
+	require.Error(t, err)
+	counts, err := svc.ListTags(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.TagCount{{Tag: "bug", Count: 3}}, counts)
+}
+
+// TestTags_RenameRetriesConflicts tests that without batches a task changed
+// during a rename is re-read rather than failing the rename
+func TestTags_RenameRetriesConflicts(t *testing.T) {
+	hook := &listHookRepo{InMemoryTaskRepository: repository.NewInMemoryTaskRepository()}
+	// Embedding only the interface hides the store's Batch method
+	svc := domain.NewTaskService(struct{ domain.TaskRepository }{hook})
+	ctx := context.Background()
+	due := time.Now().Add(time.Hour)
+	a, _ := svc.CreateTask(ctx, domain.CreateTaskInput{Title: "a", Tags: []string{"bug"}, DueDate: due})
+	svc.CreateTask(ctx, domain.CreateTaskInput{Title: "b", Tags: []string{"bug"}, DueDate: due})
+
+	hook.afterList = func() {
+		hook.afterList = nil
+		tags := []string{"bug", "ui"}
+		_, err := svc.UpdateTask(ctx, a.ID, domain.UpdateTaskInput{Tags: &tags})
+		require.NoError(t, err)
+	}
+	n, err := svc.RenameTag(ctx, "bug", "defect")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	got, err := svc.GetTask(ctx, a.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"defect", "ui"}, got.Tags)
+}
+
+// TestHandler_Tags tests the tag query parameters and tag endpoints
+func TestHandler_Tags(t *testing.T) {
+	app := newFiberTestApp()
+	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+
+	resp := sendJSON(t, app, http.MethodPost, "/v1/tasks", map[string]any{"title": "one", "tags": []string{"Home", "errand"}, "due_date": due})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var created map[string]any
+	decodeBody(t, resp, &created)
+	assert.Equal(t, []any{"errand", "home"}, created["tags"])
+	sendJSON(t, app, http.MethodPost, "/v1/tasks", map[string]any{"title": "two", "tags": []string{"home"}, "due_date": due})
+
+	var list taskList
+	decodeBody(t, sendJSON(t, app, http.MethodGet, "/v1/tasks?tag=home&tag=errand&tag_match=all", nil), &list)
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, "one", list.Items[0]["title"])
+	decodeBody(t, sendJSON(t, app, http.MethodGet, "/v1/tasks?tag=home,errand", nil), &list)
+	assert.Len(t, list.Items, 2)
+	assert.Equal(t, http.StatusBadRequest, sendJSON(t, app, http.MethodGet, "/v1/tasks?tag=home&tag_match=most", nil).StatusCode)
+
+	resp = sendJSON(t, app, http.MethodPut, "/v1/tasks/"+created["id"].(string), map[string]any{"title": "one", "tags": []string{}, "due_date": due})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var cleared map[string]any
+	decodeBody(t, resp, &cleared)
+	assert.Nil(t, cleared["tags"])
+
+	resp = sendJSON(t, app, http.MethodPost, "/v1/tags/rename", map[string]any{"from": "home", "to": "house"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var renamed map[string]any
+	decodeBody(t, resp, &renamed)
+	assert.Equal(t, float64(1), renamed["updated"])
+	assert.Equal(t, http.StatusNotFound, sendJSON(t, app, http.MethodPost, "/v1/tags/rename", map[string]any{"from": "home", "to": "x"}).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, sendJSON(t, app, http.MethodPost, "/v1/tags/rename", map[string]any{"from": "house", "to": ""}).StatusCode)
+
+	var counts []domain.TagCount
+	decodeBody(t, sendJSON(t, app, http.MethodGet, "/v1/tags", nil), &counts)
+	assert.Equal(t, []domain.TagCount{{Tag: "house", Count: 1}}, counts)
+}
@@ -18,7 +18,7 @@ func TestIntegration_CreateAndGetTask(t *testing.T) {
 	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
 	body := map[string]any{"title": "Integration Task", "description": "Test description", "due_date": due}
 	b, _ := json.Marshal(body)
-	req, _ := http.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(b))
+	req, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader(b))
 	req.Header.Set("Content-Type", "application/json")
 	resp, _ := app.Test(req, 5000)
 	assert.Equal(t, http.StatusCreated, resp.StatusCode)
@@ -30,7 +30,7 @@ func TestIntegration_CreateAndGetTask(t *testing.T) {
 	require.True(t, ok)
 	require.NotEmpty(t, id)
 
-	getReq, _ := http.NewRequest(http.MethodGet, "/tasks/"+id, nil)
+	getReq, _ := http.NewRequest(http.MethodGet, "/v1/tasks/"+id, nil)
 	getResp, _ := app.Test(getReq, 5000)
 	assert.Equal(t, http.StatusOK, getResp.StatusCode)
 	getRespBody, _ := io.ReadAll(getResp.Body)
@@ -46,22 +46,21 @@ func TestIntegration_ListTasks(t *testing.T) {
 
 	body := map[string]any{"title": "Task 1", "due_date": due}
 	b, _ := json.Marshal(body)
-	req, _ := http.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(b))
+	req, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader(b))
 	req.Header.Set("Content-Type", "application/json")
 	app.Test(req, 5000)
 
 	body["title"] = "Task 2"
 	b, _ = json.Marshal(body)
-	req2, _ := http.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(b))
+	req2, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader(b))
 	req2.Header.Set("Content-Type", "application/json")
 	app.Test(req2, 5000)
 
-	listReq, _ := http.NewRequest(http.MethodGet, "/tasks", nil)
+	listReq, _ := http.NewRequest(http.MethodGet, "/v1/tasks", nil)
 	resp, _ := app.Test(listReq, 5000)
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 	respBody, _ := io.ReadAll(resp.Body)
-	var tasks []map[string]any
-	require.NoError(t, json.Unmarshal(respBody, &tasks))
+	tasks := listItems(t, respBody)
 	assert.Equal(t, 2, len(tasks))
 }
 
@@ -71,7 +70,7 @@ func TestIntegration_UpdateTask(t *testing.T) {
 	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
 	body := map[string]any{"title": "Original", "due_date": due}
 	b, _ := json.Marshal(body)
-	req, _ := http.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(b))
+	req, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader(b))
 	req.Header.Set("Content-Type", "application/json")
 	resp, _ := app.Test(req, 5000)
 	respBody, _ := io.ReadAll(resp.Body)
@@ -79,9 +78,9 @@ func TestIntegration_UpdateTask(t *testing.T) {
 	json.Unmarshal(respBody, &created)
 	id := created["id"].(string)
 
-	updateBody := map[string]any{"title": "Updated", "status": "IN_PROGRESS"}
+	updateBody := map[string]any{"title": "Updated", "status": "IN_PROGRESS", "due_date": due}
 	updateB, _ := json.Marshal(updateBody)
-	updateReq, _ := http.NewRequest(http.MethodPut, "/tasks/"+id, bytes.NewReader(updateB))
+	updateReq, _ := http.NewRequest(http.MethodPut, "/v1/tasks/"+id, bytes.NewReader(updateB))
 	updateReq.Header.Set("Content-Type", "application/json")
 	updateResp, _ := app.Test(updateReq, 5000)
 	assert.Equal(t, http.StatusOK, updateResp.StatusCode)
@@ -99,7 +98,7 @@ func TestIntegration_DeleteTask(t *testing.T) {
 	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
 	body := map[string]any{"title": "To Delete", "due_date": due}
 	b, _ := json.Marshal(body)
-	req, _ := http.NewRequest(http.MethodPost, "/tasks", bytes.NewReader(b))
+	req, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader(b))
 	req.Header.Set("Content-Type", "application/json")
 	resp, _ := app.Test(req, 5000)
 	respBody, _ := io.ReadAll(resp.Body)
@@ -107,11 +106,11 @@ func TestIntegration_DeleteTask(t *testing.T) {
 	json.Unmarshal(respBody, &created)
 	id := created["id"].(string)
 
-	delReq, _ := http.NewRequest(http.MethodDelete, "/tasks/"+id, nil)
+	delReq, _ := http.NewRequest(http.MethodDelete, "/v1/tasks/"+id, nil)
 	delResp, _ := app.Test(delReq, 5000)
 	assert.Equal(t, http.StatusNoContent, delResp.StatusCode)
 
-	getReq, _ := http.NewRequest(http.MethodGet, "/tasks/"+id, nil)
+	getReq, _ := http.NewRequest(http.MethodGet, "/v1/tasks/"+id, nil)
 	getResp, _ := app.Test(getReq, 5000)
 	assert.Equal(t, http.StatusNotFound, getResp.StatusCode)
 }
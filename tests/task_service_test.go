@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -22,7 +23,7 @@ func TestCreateTask_Success(t *testing.T) {
 	svc := newTestService()
 	due := time.Now().Add(24 * time.Hour)
 
-	task, err := svc.CreateTask(domain.CreateTaskInput{
+	task, err := svc.CreateTask(context.Background(), domain.CreateTaskInput{
 		Title:   "Test Task",
 		DueDate: due,
 	})
@@ -38,7 +39,7 @@ func TestCreateTask_WithDescription(t *testing.T) {
 	svc := newTestService()
 	due := time.Now().Add(24 * time.Hour)
 
-	task, err := svc.CreateTask(domain.CreateTaskInput{
+	task, err := svc.CreateTask(context.Background(), domain.CreateTaskInput{
 		Title:       "Task with desc",
 		Description: "This is a description",
 		DueDate:     due,
@@ -54,7 +55,7 @@ func TestCreateTask_WithCustomStatus(t *testing.T) {
 	due := time.Now().Add(24 * time.Hour)
 	status := domain.StatusInProgress
 
-	task, err := svc.CreateTask(domain.CreateTaskInput{
+	task, err := svc.CreateTask(context.Background(), domain.CreateTaskInput{
 		Title:   "In Progress Task",
 		Status:  &status,
 		DueDate: due,
@@ -69,7 +70,7 @@ func TestCreateTask_MissingTitle(t *testing.T) {
 	svc := newTestService()
 	due := time.Now().Add(24 * time.Hour)
 
-	_, err := svc.CreateTask(domain.CreateTaskInput{
+	_, err := svc.CreateTask(context.Background(), domain.CreateTaskInput{
 		Title:   "",
 		DueDate: due,
 	})
@@ -83,7 +84,7 @@ func TestCreateTask_MissingTitle(t *testing.T) {
 func TestCreateTask_MissingDueDate(t *testing.T) {
 	svc := newTestService()
 
-	_, err := svc.CreateTask(domain.CreateTaskInput{
+	_, err := svc.CreateTask(context.Background(), domain.CreateTaskInput{
 		Title:   "Task without date",
 		DueDate: time.Time{},
 	})
@@ -98,7 +99,7 @@ func TestCreateTask_PastDueDate(t *testing.T) {
 	svc := newTestService()
 	due := time.Now().Add(-24 * time.Hour)
 
-	_, err := svc.CreateTask(domain.CreateTaskInput{
+	_, err := svc.CreateTask(context.Background(), domain.CreateTaskInput{
 		Title:   "Task",
 		DueDate: due,
 	})
@@ -114,7 +115,7 @@ func TestCreateTask_InvalidStatus(t *testing.T) {
 	due := time.Now().Add(24 * time.Hour)
 	invalidStatus := domain.TaskStatus("INVALID")
 
-	_, err := svc.CreateTask(domain.CreateTaskInput{
+	_, err := svc.CreateTask(context.Background(), domain.CreateTaskInput{
 		Title:   "Task",
 		Status:  &invalidStatus,
 		DueDate: due,
@@ -130,12 +131,12 @@ func TestGetTask_Success(t *testing.T) {
 	svc := newTestService()
 	due := time.Now().Add(24 * time.Hour)
 
-	created, _ := svc.CreateTask(domain.CreateTaskInput{
+	created, _ := svc.CreateTask(context.Background(), domain.CreateTaskInput{
 		Title:   "Task to Get",
 		DueDate: due,
 	})
 
-	got, err := svc.GetTask(created.ID)
+	got, err := svc.GetTask(context.Background(), created.ID)
 	require.NoError(t, err)
 	assert.Equal(t, created.ID, got.ID)
 	assert.Equal(t, "Task to Get", got.Title)
@@ -145,7 +146,7 @@ func TestGetTask_Success(t *testing.T) {
 func TestGetTask_NotFound(t *testing.T) {
 	svc := newTestService()
 
-	_, err := svc.GetTask("non-existent")
+	_, err := svc.GetTask(context.Background(), "non-existent")
 	require.Error(t, err)
 	assert.True(t, pkgerrors.IsNotFound(err))
 }
@@ -155,13 +156,13 @@ func TestUpdateTask_Success(t *testing.T) {
 	svc := newTestService()
 	due := time.Now().Add(24 * time.Hour)
 
-	created, _ := svc.CreateTask(domain.CreateTaskInput{
+	created, _ := svc.CreateTask(context.Background(), domain.CreateTaskInput{
 		Title:   "Original Title",
 		DueDate: due,
 	})
 
 	newTitle := "Updated Title"
-	updated, err := svc.UpdateTask(created.ID, domain.UpdateTaskInput{
+	updated, err := svc.UpdateTask(context.Background(), created.ID, domain.UpdateTaskInput{
 		Title: &newTitle,
 	})
 
@@ -174,7 +175,7 @@ func TestUpdateTask_NotFound(t *testing.T) {
 	svc := newTestService()
 	title := "Updated"
 
-	_, err := svc.UpdateTask("non-existent", domain.UpdateTaskInput{
+	_, err := svc.UpdateTask(context.Background(), "non-existent", domain.UpdateTaskInput{
 		Title: &title,
 	})
 
@@ -187,16 +188,16 @@ func TestDeleteTask_Success(t *testing.T) {
 	svc := newTestService()
 	due := time.Now().Add(24 * time.Hour)
 
-	created, _ := svc.CreateTask(domain.CreateTaskInput{
+	created, _ := svc.CreateTask(context.Background(), domain.CreateTaskInput{
 		Title:   "Task to Delete",
 		DueDate: due,
 	})
 
-	err := svc.DeleteTask(created.ID)
+	err := svc.DeleteTask(context.Background(), created.ID)
 	require.NoError(t, err)
 
 	// Verify it's deleted
-	_, err = svc.GetTask(created.ID)
+	_, err = svc.GetTask(context.Background(), created.ID)
 	assert.True(t, pkgerrors.IsNotFound(err))
 }
 
@@ -204,7 +205,7 @@ func TestDeleteTask_Success(t *testing.T) {
 func TestDeleteTask_NotFound(t *testing.T) {
 	svc := newTestService()
 
-	err := svc.DeleteTask("non-existent")
+	err := svc.DeleteTask(context.Background(), "non-existent")
 	require.Error(t, err)
 	assert.True(t, pkgerrors.IsNotFound(err))
 }
@@ -213,7 +214,7 @@ func TestDeleteTask_NotFound(t *testing.T) {
 func TestListTasks_Empty(t *testing.T) {
 	svc := newTestService()
 
-	tasks, err := svc.ListTasks(domain.TaskFilter{})
+	tasks, err := svc.ListTasks(context.Background(), domain.TaskFilter{})
 	require.NoError(t, err)
 	assert.Equal(t, 0, len(tasks))
 }
@@ -224,10 +225,10 @@ func TestListTasks_Multiple(t *testing.T) {
 	due1 := time.Now().Add(24 * time.Hour)
 	due2 := time.Now().Add(48 * time.Hour)
 
-	svc.CreateTask(domain.CreateTaskInput{Title: "Task 1", DueDate: due1})
-	svc.CreateTask(domain.CreateTaskInput{Title: "Task 2", DueDate: due2})
+	svc.CreateTask(context.Background(), domain.CreateTaskInput{Title: "Task 1", DueDate: due1})
+	svc.CreateTask(context.Background(), domain.CreateTaskInput{Title: "Task 2", DueDate: due2})
 
-	tasks, err := svc.ListTasks(domain.TaskFilter{})
+	tasks, err := svc.ListTasks(context.Background(), domain.TaskFilter{})
 	require.NoError(t, err)
 	assert.Equal(t, 2, len(tasks))
 }
@@ -238,10 +239,10 @@ func TestListTasks_SortedByDueDate(t *testing.T) {
 	due2 := time.Now().Add(48 * time.Hour)
 	due1 := time.Now().Add(24 * time.Hour)
 
-	svc.CreateTask(domain.CreateTaskInput{Title: "Task Later", DueDate: due2})
-	svc.CreateTask(domain.CreateTaskInput{Title: "Task Earlier", DueDate: due1})
+	svc.CreateTask(context.Background(), domain.CreateTaskInput{Title: "Task Later", DueDate: due2})
+	svc.CreateTask(context.Background(), domain.CreateTaskInput{Title: "Task Earlier", DueDate: due1})
 
-	tasks, err := svc.ListTasks(domain.TaskFilter{})
+	tasks, err := svc.ListTasks(context.Background(), domain.TaskFilter{})
 	require.NoError(t, err)
 	assert.Equal(t, "Task Earlier", tasks[0].Title)
 	assert.Equal(t, "Task Later", tasks[1].Title)
@@ -253,10 +254,10 @@ func TestListTasks_FilterByStatus(t *testing.T) {
 	due := time.Now().Add(24 * time.Hour)
 	status := domain.StatusDone
 
-	svc.CreateTask(domain.CreateTaskInput{Title: "Pending Task", DueDate: due})
-	svc.CreateTask(domain.CreateTaskInput{Title: "Done Task", Status: &status, DueDate: due})
+	svc.CreateTask(context.Background(), domain.CreateTaskInput{Title: "Pending Task", DueDate: due})
+	svc.CreateTask(context.Background(), domain.CreateTaskInput{Title: "Done Task", Status: &status, DueDate: due})
 
-	tasks, err := svc.ListTasks(domain.TaskFilter{Status: &status})
+	tasks, err := svc.ListTasks(context.Background(), domain.TaskFilter{Status: &status})
 	require.NoError(t, err)
 	assert.Equal(t, 1, len(tasks))
 	assert.Equal(t, domain.StatusDone, tasks[0].Status)
@@ -268,21 +269,21 @@ func TestListTasks_Pagination(t *testing.T) {
 	due := time.Now().Add(24 * time.Hour)
 
 	for i := 0; i < 25; i++ {
-		svc.CreateTask(domain.CreateTaskInput{Title: "Task", DueDate: due})
+		svc.CreateTask(context.Background(), domain.CreateTaskInput{Title: "Task", DueDate: due})
 	}
 
 	// First page
-	tasks1, err := svc.ListTasks(domain.TaskFilter{Page: 1, PageSize: 10})
+	tasks1, err := svc.ListTasks(context.Background(), domain.TaskFilter{Page: 1, PageSize: 10})
 	require.NoError(t, err)
 	assert.Equal(t, 10, len(tasks1))
 
 	// Second page
-	tasks2, err := svc.ListTasks(domain.TaskFilter{Page: 2, PageSize: 10})
+	tasks2, err := svc.ListTasks(context.Background(), domain.TaskFilter{Page: 2, PageSize: 10})
 	require.NoError(t, err)
 	assert.Equal(t, 10, len(tasks2))
 
 	// Third page (partial)
-	tasks3, err := svc.ListTasks(domain.TaskFilter{Page: 3, PageSize: 10})
+	tasks3, err := svc.ListTasks(context.Background(), domain.TaskFilter{Page: 3, PageSize: 10})
 	require.NoError(t, err)
 	assert.Equal(t, 5, len(tasks3))
 }
@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	"github.com/gauravpandey771/task-api/internal/repository"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTrash_DeleteAndRestore tests soft delete followed by restore
+func TestTrash_DeleteAndRestore(t *testing.T) {
+	svc := newTestService()
+	ctx := context.Background()
+	created, _ := svc.CreateTask(ctx, domain.CreateTaskInput{Title: "Task", DueDate: time.Now().Add(24 * time.Hour)})
+
+	require.NoError(t, svc.DeleteTask(ctx, created.ID))
+
+	_, err := svc.GetTask(ctx, created.ID)
+	assert.True(t, pkgerrors.IsNotFound(err))
+	live, _ := svc.ListTasks(ctx, domain.TaskFilter{})
+	assert.Empty(t, live)
+
+	trash, err := svc.ListTrash(ctx, domain.TaskFilter{})
+	require.NoError(t, err)
+	require.Len(t, trash.Tasks, 1)
+	assert.Equal(t, 1, trash.Total)
+	assert.NotNil(t, trash.Tasks[0].DeletedAt)
+
+	restored, err := svc.RestoreTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+
+	got, err := svc.GetTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Task", got.Title)
+
+	_, err = svc.RestoreTask(ctx, created.ID)
+	assert.True(t, pkgerrors.IsNotFound(err))
+}
+
+// TestTrash_Purge tests purging single tasks and expired trash
+func TestTrash_Purge(t *testing.T) {
+	svc := newTestService()
+	ctx := context.Background()
+	live, _ := svc.CreateTask(ctx, domain.CreateTaskInput{Title: "Live", DueDate: time.Now().Add(24 * time.Hour)})
+	first, _ := svc.CreateTask(ctx, domain.CreateTaskInput{Title: "First", DueDate: time.Now().Add(24 * time.Hour)})
+	second, _ := svc.CreateTask(ctx, domain.CreateTaskInput{Title: "Second", DueDate: time.Now().Add(24 * time.Hour)})
+
+	// Live tasks cannot be purged directly
+	assert.True(t, pkgerrors.IsNotFound(svc.PurgeTask(ctx, live.ID)))
+
+	require.NoError(t, svc.DeleteTask(ctx, first.ID))
+	require.NoError(t, svc.PurgeTask(ctx, first.ID))
+	trash, _ := svc.ListTrash(ctx, domain.TaskFilter{})
+	assert.Empty(t, trash.Tasks)
+
+	require.NoError(t, svc.DeleteTask(ctx, second.ID))
+	n, err := svc.PurgeTrash(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	n, err = svc.PurgeTrash(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = svc.RestoreTask(ctx, second.ID)
+	assert.True(t, pkgerrors.IsNotFound(err))
+	_, err = svc.GetTask(ctx, live.ID)
+	assert.NoError(t, err)
+}
+
+// TestHandler_TrashEndpoints tests the trash, restore and purge routes
+func TestHandler_TrashEndpoints(t *testing.T) {
+	app := newFiberTestApp()
+	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	b, _ := json.Marshal(map[string]any{"title": "Task", "due_date": due})
+	req, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req, 5000)
+	respBody, _ := io.ReadAll(resp.Body)
+	var created map[string]any
+	json.Unmarshal(respBody, &created)
+	id := created["id"].(string)
+
+	delReq, _ := http.NewRequest(http.MethodDelete, "/v1/tasks/"+id, nil)
+	delResp, _ := app.Test(delReq, 5000)
+	assert.Equal(t, http.StatusNoContent, delResp.StatusCode)
+
+	trashReq, _ := http.NewRequest(http.MethodGet, "/v1/tasks/trash", nil)
+	trashResp, _ := app.Test(trashReq, 5000)
+	assert.Equal(t, http.StatusOK, trashResp.StatusCode)
+	trashBody, _ := io.ReadAll(trashResp.Body)
+	var trash taskList
+	json.Unmarshal(trashBody, &trash)
+	require.Len(t, trash.Items, 1)
+	assert.NotEmpty(t, trash.Items[0]["deleted_at"])
+
+	restoreReq, _ := http.NewRequest(http.MethodPost, "/v1/tasks/"+id+"/restore", nil)
+	restoreResp, _ := app.Test(restoreReq, 5000)
+	assert.Equal(t, http.StatusOK, restoreResp.StatusCode)
+
+	getReq, _ := http.NewRequest(http.MethodGet, "/v1/tasks/"+id, nil)
+	getResp, _ := app.Test(getReq, 5000)
+	assert.Equal(t, http.StatusOK, getResp.StatusCode)
+
+	delReq2, _ := http.NewRequest(http.MethodDelete, "/v1/tasks/"+id, nil)
+	app.Test(delReq2, 5000)
+	purgeReq, _ := http.NewRequest(http.MethodDelete, "/v1/tasks/"+id+"/purge", nil)
+	purgeResp, _ := app.Test(purgeReq, 5000)
+	assert.Equal(t, http.StatusNoContent, purgeResp.StatusCode)
+
+	restoreReq2, _ := http.NewRequest(http.MethodPost, "/v1/tasks/"+id+"/restore", nil)
+	restoreResp2, _ := app.Test(restoreReq2, 5000)
+	assert.Equal(t, http.StatusNotFound, restoreResp2.StatusCode)
+}
+
+// listHookRepo runs a hook after listing tasks, to change them between a
+// caller reading them and acting on them
+type listHookRepo struct {
+	*repository.InMemoryTaskRepository
+	afterList func()
+}
+
+func (r *listHookRepo) ListAll(ctx context.Context) ([]*domain.Task, error) {
+	tasks, err := r.InMemoryTaskRepository.ListAll(ctx)
+	if r.afterList != nil {
+		r.afterList()
+	}
+	return tasks, err
+}
+
+// TestTrash_PurgeKeepsRestored tests that a task restored while the trash is
+// being purged is kept
+func TestTrash_PurgeKeepsRestored(t *testing.T) {
+	repo := &listHookRepo{InMemoryTaskRepository: repository.NewInMemoryTaskRepository()}
+	svc := domain.NewTaskService(repo)
+	ctx := context.Background()
+	task, _ := svc.CreateTask(ctx, domain.CreateTaskInput{Title: "Task", DueDate: time.Now().Add(24 * time.Hour)})
+	require.NoError(t, svc.DeleteTask(ctx, task.ID))
+
+	repo.afterList = func() {
+		repo.afterList = nil
+		_, err := svc.RestoreTask(ctx, task.ID)
+		require.NoError(t, err)
+	}
+	n, err := svc.PurgeTrash(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	_, err = svc.GetTask(ctx, task.ID)
+	assert.NoError(t, err)
+}
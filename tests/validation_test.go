@@ -0,0 +1,195 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	httphandler "github.com/gauravpandey771/task-api/internal/transport/http"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/gauravpandey771/task-api/pkg/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestValidate tests JSON Schema validation with JSON pointer paths
+func TestValidate(t *testing.T) {
+	v := &jsonschema.Validator{
+		RefPrefix: "#/defs/",
+		Defs: map[string]any{
+			"Color": map[string]any{"type": "string", "enum": []any{"red", "green"}},
+			"Item": map[string]any{
+				"type":                 "object",
+				"required":             []string{"name"},
+				"additionalProperties": false,
+				"properties": map[string]any{
+					"name":  map[string]any{"type": "string", "maxLength": 5},
+					"color": map[string]any{"$ref": "#/defs/Color"},
+					"at":    map[string]any{"type": "string", "format": "date-time"},
+					"count": map[string]any{"type": "integer", "minimum": 1},
+				},
+			},
+		},
+	}
+	schema := map[string]any{"type": "array", "items": map[string]any{"$ref": "#/defs/Item"}}
+
+	var doc any
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"name": "ok", "color": "red", "at": "2026-01-02T15:04:05Z", "count": 2},
+		{"color": "blue", "at": "tomorrow", "count": 1.5, "a/b": true},
+		{"name": "toolong", "count": 0},
+		"nope"
+	]`), &doc))
+
+	var got []string
+	for _, err := range v.Validate(schema, doc) {
+		got = append(got, err.Pointer+" "+err.Keyword)
+	}
+	assert.Equal(t, []string{
+		"/1/name required",
+		"/1/a~1b additionalProperties",
+		"/1/at format",
+		"/1/color enum",
+		"/1/count type",
+		"/2/count minimum",
+		"/2/name maxLength",
+		"/3 type",
+	}, got)
+
+	assert.Empty(t, v.Validate(schema, []any{}))
+}
+
+// renameSpy counts the renames that reach the service
+type renameSpy struct {
+	domain.TaskService
+	renames int
+}
+
+func (s *renameSpy) RenameTag(ctx context.Context, from, to string) (int, error) {
+	s.renames++
+	return s.TaskService.RenameTag(ctx, from, to)
+}
+
+// TestHandler_ValidateBody tests that request bodies are checked against the
+// OpenAPI document before reaching the handler
+func TestHandler_ValidateBody(t *testing.T) {
+	svc := &renameSpy{TaskService: newTestService()}
+	app := httphandler.NewApp(httphandler.NewTaskHandler(svc))
+	due := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+
+	send := func(method, path, contentType, body string) (int, problemResponse) {
+		req, _ := http.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", contentType)
+		resp, err := app.Test(req, 5000)
+		require.NoError(t, err)
+		respBody, _ := io.ReadAll(resp.Body)
+		var p problemResponse
+		json.Unmarshal(respBody, &p)
+		return resp.StatusCode, p
+	}
+
+	status, p := send(http.MethodPost, "/v1/tasks", "application/json",
+		`{"title": 5, "due_date": "tomorrow", "status": "BOGUS", "colour": "red"}`)
+	require.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, pkgerrors.CodeValidationFailed, p.Code)
+	assert.Equal(t, []pkgerrors.FieldError{
+		{Field: "colour", Code: "field_unknown", Message: "colour is not a known field"},
+		{Field: "due_date", Code: "format_invalid", Message: "due_date must be an RFC3339 date-time"},
+		{Field: "status", Code: "enum_invalid", Message: "status must be one of PENDING, IN_PROGRESS, DONE"},
+		{Field: "title", Code: "type_invalid", Message: "title must be a string"},
+	}, p.Errors)
+
+	// The domain's own checks are reported along with the schema's, and
+	// win for fields both report
+	status, p = send(http.MethodPost, "/v1/tasks", "application/json", `{"title": "", "colour": "red"}`)
+	require.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, []pkgerrors.FieldError{
+		{Field: "title", Code: domain.CodeTitleRequired, Message: domain.ErrTitleRequired},
+		{Field: "due_date", Code: domain.CodeDueDateRequired, Message: domain.ErrDueDateRequired},
+		{Field: "colour", Code: "field_unknown", Message: "colour is not a known field"},
+	}, p.Errors)
+	assert.Equal(t, 0, countTasks(t, svc))
+
+	status, p = send(http.MethodPost, "/v1/tasks", "application/json", `{"title": "Task", "due_date": "`+due+`", "colour": "red"}`)
+	require.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "field_unknown", p.Code)
+	assert.Equal(t, 0, countTasks(t, svc), "invalid bodies store nothing")
+
+	// Nor do they use up an Idempotency-Key
+	req, _ := http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader([]byte(`{"title": "Task", "due_date": "`+due+`", "colour": "red"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", "dry")
+	resp, err := app.Test(req, 5000)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("ETag"))
+	req, _ = http.NewRequest(http.MethodPost, "/v1/tasks", bytes.NewReader([]byte(`{"title": "Task", "due_date": "`+due+`"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", "dry")
+	resp, err = app.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	// Nested values are located by their full path
+	status, p = send(http.MethodPost, "/v1/tasks:batch", "application/json", `{"operations": [{"op": "upsert", "extra": 1}]}`)
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Len(t, p.Errors, 2)
+	assert.Equal(t, "operations.0.extra", p.Errors[0].Field)
+	assert.Equal(t, "operations.0.op", p.Errors[1].Field)
+
+	// Batch tasks are checked against the request of their op
+	status, p = send(http.MethodPost, "/v1/tasks:batch", "application/json", `{"operations": [
+		{"op": "create", "task": {"title": "Task", "due_date": "`+due+`", "colour": "red"}},
+		{"op": "update", "id": "x", "task": {"priority": "CRITICAL", "due_date": "soon"}},
+		{"op": "delete", "id": "x"}
+	]}`)
+	require.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, []string{"operations.0.task.colour", "operations.1.task.due_date", "operations.1.task.priority"},
+		[]string{p.Errors[0].Field, p.Errors[1].Field, p.Errors[2].Field})
+	assert.Len(t, p.Errors, 3)
+
+	task := postTask(t, app, map[string]any{"title": "Task", "due_date": due})
+	id := task["id"].(string)
+
+	status, p = send(http.MethodPut, "/v1/tasks/"+id, "application/json", `{"title": "Task", "due_date": "`+due+`", "tags": "a,b"}`)
+	require.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "tags", p.Errors[0].Field)
+	assert.Equal(t, "type_invalid", p.Code)
+
+	status, p = send(http.MethodPut, "/v1/tasks/"+id, "application/json", `{"title": "", "due_date": "`+due+`", "colour": "red"}`)
+	require.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, []pkgerrors.FieldError{
+		{Field: "title", Code: domain.CodeTitleRequired, Message: domain.ErrTitleRequired},
+		{Field: "colour", Code: "field_unknown", Message: "colour is not a known field"},
+	}, p.Errors)
+
+	status, p = send(http.MethodPatch, "/v1/tasks/"+id, "application/json-patch+json", `[{"op": "replace", "path": "/title", "value": "x", "extra": 1}]`)
+	require.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "0.extra", p.Errors[0].Field)
+
+	status, p = send(http.MethodPatch, "/v1/tasks/"+id, "application/merge-patch+json", `["not", "an", "object"]`)
+	require.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "", p.Errors[0].Field)
+	assert.Equal(t, "body must be an object", p.Errors[0].Message)
+
+	status, _ = send(http.MethodPost, "/v1/tasks", "application/json", `{"title": `)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	// Media types the document does not list are refused
+	status, _ = send(http.MethodPatch, "/v1/tasks/"+id, "text/plain", `title=x`)
+	assert.Equal(t, http.StatusUnsupportedMediaType, status)
+	tasks := countTasks(t, svc)
+	status, p = send(http.MethodPost, "/v1/tasks", "application/x-www-form-urlencoded", `title=Task&due_date=`+due)
+	assert.Equal(t, http.StatusUnsupportedMediaType, status)
+	assert.Contains(t, p.Detail, "expected application/json")
+	assert.Equal(t, tasks, countTasks(t, svc))
+
+	status, _ = send(http.MethodPost, "/v1/tags/rename", "application/json", `{"from": "a", "to": ["b"]}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Zero(t, svc.renames, "invalid bodies never reach the handler")
+}
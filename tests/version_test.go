@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	"github.com/gauravpandey771/task-api/internal/repository"
+	httphandler "github.com/gauravpandey771/task-api/internal/transport/http"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// v2Routes is a minimal second API version sharing the task service with v1
+type v2Routes struct {
+	svc domain.TaskService
+}
+
+func (v v2Routes) RegisterRoutes(r fiber.Router) {
+	r.Get("/tasks/:id", func(c *fiber.Ctx) error {
+		task, err := v.svc.GetTask(c.UserContext(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(fiber.Map{"data": task})
+	})
+}
+
+// TestVersion_V1 tests that the API is served under /v1 without deprecation
+func TestVersion_V1(t *testing.T) {
+	app := newFiberTestApp()
+	task := postTask(t, app, map[string]any{"title": "Task", "due_date": time.Now().Add(24 * time.Hour).Format(time.RFC3339)})
+
+	req, _ := http.NewRequest(http.MethodGet, "/v1/tasks/"+task["id"].(string), nil)
+	resp, err := app.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get(httphandler.HeaderDeprecation))
+	assert.Empty(t, resp.Header.Get(httphandler.HeaderSunset))
+
+	req, _ = http.NewRequest(http.MethodGet, "/v1/tasks?page_size=1", nil)
+	resp, err = app.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Contains(t, resp.Header.Get("Link"), `</v1/tasks?page_size=1>; rel="first"`)
+	assert.NotContains(t, resp.Header.Get("Link"), "successor-version")
+}
+
+// TestVersion_LegacyDeprecated tests the headers of the unprefixed routes
+func TestVersion_LegacyDeprecated(t *testing.T) {
+	app := newFiberTestApp()
+
+	req, _ := http.NewRequest(http.MethodGet, "/tasks?page_size=1", nil)
+	resp, err := app.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "@1792108800", resp.Header.Get(httphandler.HeaderDeprecation))
+	assert.Equal(t, "Fri, 16 Apr 2027 00:00:00 GMT", resp.Header.Get(httphandler.HeaderSunset))
+	assert.Equal(t, `</tasks?page_size=1>; rel="first", </v1/tasks>; rel="successor-version"`, resp.Header.Get("Link"))
+
+	// Errors are marked too
+	req, _ = http.NewRequest(http.MethodGet, "/tasks/missing", nil)
+	resp, err = app.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get(httphandler.HeaderDeprecation))
+	assert.Contains(t, resp.Header.Get("Link"), `</v1/tasks/missing>; rel="successor-version"`)
+
+	// The legacy document describes the same operations, all deprecated
+	req, _ = http.NewRequest(http.MethodGet, "/openapi.json", nil)
+	resp, err = app.Test(req, 5000)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(body, &doc))
+	assert.Equal(t, []any{map[string]any{"url": "/"}}, doc["servers"])
+	op := doc["paths"].(map[string]any)["/tasks"].(map[string]any)["get"].(map[string]any)
+	assert.Equal(t, true, op["deprecated"])
+
+	v1 := getOpenAPI(t, app)
+	assert.Equal(t, []any{map[string]any{"url": "/v1"}}, v1["servers"])
+	assert.NotContains(t, v1["paths"].(map[string]any)["/tasks"].(map[string]any)["get"], "deprecated")
+}
+
+// TestVersion_LegacySunset tests configuring when the legacy routes go away
+func TestVersion_LegacySunset(t *testing.T) {
+	sunset := time.Date(2027, time.January, 1, 12, 0, 0, 0, time.UTC)
+	app := httphandler.NewApp(httphandler.NewTaskHandler(newTestService()), httphandler.WithLegacySunset(sunset))
+
+	req, _ := http.NewRequest(http.MethodGet, "/tasks", nil)
+	resp, err := app.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, "Fri, 01 Jan 2027 12:00:00 GMT", resp.Header.Get(httphandler.HeaderSunset))
+}
+
+// TestVersion_LegacyGone tests that the legacy routes are gone after sunset
+func TestVersion_LegacyGone(t *testing.T) {
+	sunset := time.Now().Add(-time.Hour)
+	app := httphandler.NewApp(httphandler.NewTaskHandler(newTestService()), httphandler.WithLegacySunset(sunset))
+
+	req, _ := http.NewRequest(http.MethodGet, "/tasks", nil)
+	resp, err := app.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusGone, resp.StatusCode)
+	assert.Equal(t, httphandler.MIMEProblem, resp.Header.Get("Content-Type"))
+	assert.Equal(t, `</v1/tasks>; rel="successor-version"`, resp.Header.Get("Link"))
+
+	req, _ = http.NewRequest(http.MethodGet, "/v1/tasks", nil)
+	resp, err = app.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+// TestVersion_Coexist tests serving a second version over the same service
+func TestVersion_Coexist(t *testing.T) {
+	svc := domain.NewTaskService(repository.NewInMemoryTaskRepository())
+	app := httphandler.NewApp(httphandler.NewTaskHandler(svc), httphandler.WithVersion("v2", v2Routes{svc: svc}))
+	task := postTask(t, app, map[string]any{"title": "Task", "due_date": time.Now().Add(24 * time.Hour).Format(time.RFC3339)})
+	id := task["id"].(string)
+
+	req, _ := http.NewRequest(http.MethodGet, "/v2/tasks/"+id, nil)
+	resp, err := app.Test(req, 5000)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	var got struct {
+		Data map[string]any `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(body, &got))
+	assert.Equal(t, id, got.Data["id"])
+
+	req, _ = http.NewRequest(http.MethodGet, "/v1/tasks/"+id, nil)
+	resp, err = app.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	req, _ = http.NewRequest(http.MethodGet, "/v2/tasks/missing", nil)
+	resp, err = app.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
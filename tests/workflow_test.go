@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gauravpandey771/task-api/internal/domain"
+	"github.com/gauravpandey771/task-api/internal/repository"
+	pkgerrors "github.com/gauravpandey771/task-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWorkflow_DefaultTransitions tests the built-in transition table
+func TestWorkflow_DefaultTransitions(t *testing.T) {
+	svc := newTestService()
+	ctx := context.Background()
+	pending, done, inProgress := domain.StatusPending, domain.StatusDone, domain.StatusInProgress
+
+	task, err := svc.CreateTask(ctx, domain.CreateTaskInput{Title: "Task", Status: &done, DueDate: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+
+	_, err = svc.UpdateTask(ctx, task.ID, domain.UpdateTaskInput{Status: &pending})
+	require.Error(t, err)
+	assert.True(t, pkgerrors.IsValidation(err))
+	assert.Equal(t, "invalid status transition from DONE to PENDING", err.Error())
+
+	// Staying put and reopening are allowed
+	_, err = svc.UpdateTask(ctx, task.ID, domain.UpdateTaskInput{Status: &done})
+	require.NoError(t, err)
+	reopened, err := svc.UpdateTask(ctx, task.ID, domain.UpdateTaskInput{Status: &inProgress})
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusInProgress, reopened.Status)
+
+	blocked := domain.TaskStatus("BLOCKED")
+	_, err = svc.UpdateTask(ctx, task.ID, domain.UpdateTaskInput{Status: &blocked})
+	assert.Equal(t, domain.ErrStatusInvalid, err.Error())
+}
+
+// TestWorkflow_CustomConfig tests loading custom states and guards
+func TestWorkflow_CustomConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "workflow.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"initial": "PENDING",
+		"states": ["PENDING", "IN_PROGRESS", "BLOCKED", "IN_REVIEW", "DONE"],
+		"transitions": [
+			{"from": "PENDING", "to": "IN_PROGRESS"},
+			{"from": "IN_PROGRESS", "to": "BLOCKED"},
+			{"from": "BLOCKED", "to": "IN_PROGRESS"},
+			{"from": "IN_PROGRESS", "to": "IN_REVIEW"},
+			{"from": "IN_REVIEW", "to": "DONE", "guards": ["description_required"]}
+		]
+	}`), 0o644))
+
+	workflow, err := domain.LoadWorkflow(path)
+	require.NoError(t, err)
+	svc := domain.NewTaskService(repository.NewInMemoryTaskRepository(), domain.WithWorkflow(workflow))
+	ctx := context.Background()
+
+	task, err := svc.CreateTask(ctx, domain.CreateTaskInput{Title: "Task", DueDate: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	move := func(status string, description *string) error {
+		s := domain.TaskStatus(status)
+		_, err := svc.UpdateTask(ctx, task.ID, domain.UpdateTaskInput{Status: &s, Description: description})
+		return err
+	}
+
+	assert.Error(t, move("DONE", nil))
+	require.NoError(t, move("IN_PROGRESS", nil))
+	require.NoError(t, move("BLOCKED", nil))
+	require.NoError(t, move("IN_PROGRESS", nil))
+	require.NoError(t, move("IN_REVIEW", nil))
+
+	err = move("DONE", nil)
+	assert.True(t, pkgerrors.IsValidation(err))
+	assert.Equal(t, "invalid status transition from IN_REVIEW to DONE: description is required", err.Error())
+
+	// The guard sees the description set in the same update
+	description := "Reviewed"
+	require.NoError(t, move("DONE", &description))
+
+	// Tasks may be created in any state reachable from the initial one,
+	// provided they pass the guards on the way
+	review, done := domain.TaskStatus("IN_REVIEW"), domain.TaskStatus("DONE")
+	created, err := svc.CreateTask(ctx, domain.CreateTaskInput{Title: "Skip", Status: &review, DueDate: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, review, created.Status)
+
+	_, err = svc.CreateTask(ctx, domain.CreateTaskInput{Title: "Skip", Status: &done, DueDate: time.Now().Add(time.Hour)})
+	assert.True(t, pkgerrors.IsValidation(err))
+	assert.Equal(t, "invalid status transition from PENDING to DONE: description is required", err.Error())
+
+	created, err = svc.CreateTask(ctx, domain.CreateTaskInput{Title: "Skip", Description: "Reviewed", Status: &done, DueDate: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, done, created.Status)
+
+	// States with no way in cannot be created directly
+	isolated, err := domain.NewWorkflow(domain.WorkflowConfig{Initial: "OPEN", States: []domain.TaskStatus{"OPEN", "ARCHIVED"}})
+	require.NoError(t, err)
+	archived := domain.TaskStatus("ARCHIVED")
+	_, err = domain.NewTaskService(repository.NewInMemoryTaskRepository(), domain.WithWorkflow(isolated)).
+		CreateTask(ctx, domain.CreateTaskInput{Title: "Task", Status: &archived, DueDate: time.Now().Add(time.Hour)})
+	assert.Equal(t, "invalid status transition from OPEN to ARCHIVED", err.Error())
+}
+
+// TestWorkflow_InvalidConfig tests that broken configs are rejected
+func TestWorkflow_InvalidConfig(t *testing.T) {
+	states := []domain.TaskStatus{"OPEN", "CLOSED"}
+	for name, cfg := range map[string]domain.WorkflowConfig{
+		"unknown initial": {Initial: "NEW", States: states},
+		"duplicate state": {Initial: "OPEN", States: []domain.TaskStatus{"OPEN", "OPEN"}},
+		"unknown state":   {Initial: "OPEN", States: states, Transitions: []domain.TransitionConfig{{From: "OPEN", To: "GONE"}}},
+		"self transition": {Initial: "OPEN", States: states, Transitions: []domain.TransitionConfig{{From: "OPEN", To: "OPEN"}}},
+		"unknown guard":   {Initial: "OPEN", States: states, Transitions: []domain.TransitionConfig{{From: "OPEN", To: "CLOSED", Guards: []string{"nope"}}}},
+		"duplicate transition": {Initial: "OPEN", States: states, Transitions: []domain.TransitionConfig{
+			{From: "OPEN", To: "CLOSED"}, {From: "OPEN", To: "CLOSED"},
+		}},
+	} {
+		_, err := domain.NewWorkflow(cfg)
+		assert.Error(t, err, name)
+	}
+}